@@ -0,0 +1,160 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+	"github.com/spf13/cobra"
+)
+
+var abLoad bool
+
+// abSide is one of the two differently configured databases of the ab command.
+type abSide struct {
+	name     string
+	p        *properties.Properties
+	db       ycsb.DB
+	oracle   *client.OracleDB
+	workload ycsb.Workload
+	// elapsed is the time spent running the side, its throughput is computed
+	// from it as the histograms of the interleaved sides overlap.
+	elapsed time.Duration
+}
+
+// run runs count operations of the side, its operations are measured under
+// the "<name>-" prefix.
+func (s *abSide) run(count int64) time.Duration {
+	p := properties.NewProperties()
+	p.Merge(s.p)
+	p.Set(prop.OperationCount, strconv.FormatInt(count, 10))
+
+	start := time.Now()
+	client.NewClient(p, s.workload, s.db).Run(measurement.WithLabel(globalContext, s.name))
+	return time.Since(start)
+}
+
+// results returns the results of the side with its throughput over the time
+// it ran.
+func (s *abSide) results(all map[string]measurement.Result) map[string]measurement.Result {
+	res := make(map[string]measurement.Result)
+	for op, r := range all {
+		if !strings.HasPrefix(op, s.name+"-") {
+			continue
+		}
+		r.OPS = float64(r.Count) / s.elapsed.Seconds()
+		res[strings.TrimPrefix(op, s.name+"-")] = r
+	}
+	return res
+}
+
+func runABCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	initialProps(func() {
+		globalProps.Set(prop.DoTransactions, "true")
+		globalProps.Set(prop.Command, "run")
+		globalProps = util.PhaseProperties(globalProps, "run")
+	})
+
+	mode := globalProps.GetString(prop.ABMode, prop.ABModeDefault)
+	rounds := int64(1)
+	switch mode {
+	case "sequential":
+	case "interleaved":
+		rounds = int64(globalProps.GetInt(prop.ABRounds, prop.ABRoundsDefault))
+	default:
+		util.Fatalf("unknown %s %s", prop.ABMode, mode)
+	}
+	operationCount := globalProps.GetInt64(prop.OperationCount, 0)
+	if rounds < 1 || operationCount < rounds {
+		util.Fatalf("%s must be at least %d, the number of rounds", prop.OperationCount, rounds)
+	}
+
+	sides := []*abSide{{name: "A"}, {name: "B"}}
+	for _, s := range sides {
+		s.p = util.OverrideProperties(globalProps, "ab."+strings.ToLower(s.name)+".")
+		s.db, s.oracle = openDB(dbName, s.p)
+		s.workload = createWorkload(s.p)
+	}
+	defer func() {
+		for _, s := range sides {
+			s.workload.Close()
+			s.db.Close()
+		}
+	}()
+
+	if abLoad {
+		for _, s := range sides {
+			fmt.Printf("***************** loading %s *****************\n", s.name)
+			lp := util.PhaseProperties(s.p, "load")
+			lp.Set(prop.DoTransactions, "false")
+			lp.Set(prop.Command, "load")
+			workload := createWorkload(lp)
+			client.NewClient(lp, workload, s.db).Run(globalContext)
+			workload.Close()
+		}
+		measurement.InitMeasure(globalProps)
+	}
+
+	for i := int64(0); i < rounds && globalContext.Err() == nil; i++ {
+		count := operationCount / rounds
+		if i < operationCount%rounds {
+			count++
+		}
+		// every other round starts with B, so both sides run as often right
+		// after the other one.
+		order := sides
+		if i%2 == 1 {
+			order = []*abSide{sides[1], sides[0]}
+		}
+		for _, s := range order {
+			fmt.Printf("***************** round %d of %d: %s *****************\n", i+1, rounds, s.name)
+			s.elapsed += s.run(count)
+		}
+	}
+
+	results := measurement.Results()
+	fmt.Println("**********************************************")
+	measurement.OutputComparison(os.Stdout, globalProps, "A", "B", sides[0].results(results), sides[1].results(results))
+	for _, s := range sides {
+		if s.oracle != nil {
+			fmt.Printf("Oracle of %s:\n", s.name)
+			s.oracle.Report()
+		}
+	}
+}
+
+func newABCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "ab db",
+		Short: "Run the same workload against two differently configured databases and compare them",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runABCommandFunc,
+	}
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	m.Flags().BoolVar(&abLoad, "load", false, "Load the data into both databases first")
+	return m
+}
@@ -0,0 +1,165 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+
+	"github.com/spf13/cobra"
+)
+
+// cliExitCode is the exit code of the one-shot commands, main exits with it
+// once the database is closed.
+var cliExitCode int
+
+var cliDBName string
+
+// cliFailed prints the failure on stderr, so that stdout only holds the
+// records, and makes the command exit with 1.
+func cliFailed(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, format+"\n", args...)
+	cliExitCode = 1
+}
+
+// cliContext opens the binding on the table and runs fn with the thread
+// context set up in shellContext.
+func cliContext(table string, fn func()) {
+	tableName = table
+	initialGlobal(cliDBName, nil)
+
+	ctx := globalWorkload.InitThread(globalContext, 0, 1)
+	ctx = globalDB.InitThread(ctx, 0, 1)
+	shellContext = ctx
+
+	fn()
+
+	globalDB.CleanupThread(ctx)
+	globalWorkload.CleanupThread(ctx)
+}
+
+func runCliGetCommandFunc(cmd *cobra.Command, args []string) {
+	table, key, fields := args[0], args[1], args[2:]
+	cliContext(table, func() {
+		row, err := globalDB.Read(shellContext, tableName, key, fields)
+		if err != nil {
+			cliFailed("get %s failed %v", key, err)
+			return
+		}
+		if row == nil {
+			cliFailed("%s not found", key)
+			return
+		}
+		printShellRow(row)
+	})
+}
+
+func runCliPutCommandFunc(cmd *cobra.Command, args []string) {
+	table, key := args[0], args[1]
+	values, err := parseShellValues(args[2:])
+	if err != nil {
+		cliFailed("%v", err)
+		return
+	}
+	cliContext(table, func() {
+		if err := globalDB.Insert(shellContext, tableName, key, values); err != nil {
+			cliFailed("put %s failed %v", key, err)
+		}
+	})
+}
+
+func runCliDeleteCommandFunc(cmd *cobra.Command, args []string) {
+	table, key := args[0], args[1]
+	cliContext(table, func() {
+		if err := globalDB.Delete(shellContext, tableName, key); err != nil {
+			cliFailed("delete %s failed %v", key, err)
+		}
+	})
+}
+
+func runCliScanCommandFunc(cmd *cobra.Command, args []string) {
+	table, key, fields := args[0], args[1], args[3:]
+	count, err := strconv.Atoi(args[2])
+	if err != nil || count <= 0 {
+		cliFailed("invalid record count %s for scan", args[2])
+		return
+	}
+	cliContext(table, func() {
+		var keys []string
+		var rows []map[string][]byte
+		if scanDB, ok := globalDB.(ycsb.ScanKeysDB); ok {
+			keys, rows, err = scanDB.ScanKeys(shellContext, tableName, key, count, fields)
+		}
+		if keys == nil {
+			rows, err = globalDB.Scan(shellContext, tableName, key, count, fields)
+		}
+		if err != nil {
+			cliFailed("scan from %s with %d failed %v", key, count, err)
+			return
+		}
+
+		// the records are separated by an empty line, led by their key
+		// on the bindings returning it.
+		for i, row := range rows {
+			if i > 0 {
+				fmt.Println()
+			}
+			if keys != nil {
+				fmt.Printf("key=%q\n", keys[i])
+			}
+			printShellRow(row)
+		}
+	})
+}
+
+func newCliCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "cli",
+		Short: "Run a single operation and exit, for scripts around the runs",
+	}
+	m.PersistentFlags().StringVar(&cliDBName, "db", "fredb", "The database binding")
+	m.PersistentFlags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.PersistentFlags().StringSliceVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+
+	m.AddCommand(
+		&cobra.Command{
+			Use:   "get table key [field0 field1 field2 ...]",
+			Short: "Print the fields of a record, exits with 1 if it doesn't exist",
+			Args:  cobra.MinimumNArgs(2),
+			Run:   runCliGetCommandFunc,
+		},
+		&cobra.Command{
+			Use:   "put table key field0=value0 [field1=value1 ...]",
+			Short: "Insert a record",
+			Args:  cobra.MinimumNArgs(3),
+			Run:   runCliPutCommandFunc,
+		},
+		&cobra.Command{
+			Use:   "delete table key",
+			Short: "Delete a record",
+			Args:  cobra.ExactArgs(2),
+			Run:   runCliDeleteCommandFunc,
+		},
+		&cobra.Command{
+			Use:   "scan table key recordcount [field0 field1 field2 ...]",
+			Short: "Print the records from key on",
+			Args:  cobra.MinimumNArgs(3),
+			Run:   runCliScanCommandFunc,
+		},
+	)
+	return m
+}
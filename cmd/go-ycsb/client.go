@@ -15,19 +15,65 @@ package main
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 	"strconv"
 	"time"
 
 	"github.com/pingcap/go-ycsb/pkg/client"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/spf13/cobra"
 )
 
 func runClientCommandFunc(cmd *cobra.Command, args []string, doTransactions bool, command string) {
+	if cronArg != "" {
+		runScheduled(cmd, args, doTransactions, command)
+		return
+	}
+	runClient(cmd, args, doTransactions, command)
+}
+
+// runScheduled runs the load or run every time the schedule of --cron is
+// due, until the program is stopped.
+func runScheduled(cmd *cobra.Command, args []string, doTransactions bool, command string) {
+	schedule, err := util.ParseCron(cronArg)
+	if err != nil {
+		util.Fatalf("%v", err)
+	}
+
+	for {
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			util.Fatalf("cron %q is never due", cronArg)
+		}
+		fmt.Printf("[CRON] the next %s starts at %s\n", command, next.Format(time.RFC3339))
+		select {
+		case <-globalContext.Done():
+			return
+		case <-time.After(time.Until(next)):
+		}
+
+		runClient(cmd, args, doTransactions, command)
+		// every run opens the database again, like a run of its own.
+		globalDB.Close()
+		globalWorkload.Close()
+		globalDB, globalWorkload = nil, nil
+		if globalContext.Err() != nil {
+			return
+		}
+	}
+}
+
+func runClient(cmd *cobra.Command, args []string, doTransactions bool, command string) {
 	dbName := args[0]
 
-	initialGlobal(dbName, func() {
+	if replayManifestArg != "" {
+		replayRun = readReplayManifest(replayManifestArg, dbName, command)
+	}
+
+	initialProps(func() {
 		doTransFlag := "true"
 		if !doTransactions {
 			doTransFlag = "false"
@@ -47,20 +93,124 @@ func runClientCommandFunc(cmd *cobra.Command, args []string, doTransactions bool
 		if cmd.Flags().Changed("interval") {
 			globalProps.Set(prop.LogInterval, strconv.Itoa(reportInterval))
 		}
+
+		if _, ok := globalProps.Get(prop.RandomSeed); !ok && globalProps.GetBool(prop.Manifest, prop.ManifestDefault) {
+			// the seed is recorded so the run can be replayed.
+			globalProps.Set(prop.RandomSeed, strconv.FormatInt(time.Now().UnixNano(), 10))
+		}
+		globalProps = util.PhaseProperties(globalProps, command)
 	})
+	if doTransactions {
+		restorePoolDataset(dbName)
+	}
+	globalWorkload = createWorkload(globalProps)
+	globalDB, globalOracle = openDB(dbName, globalProps)
 
 	fmt.Println("***************** properties *****************")
 	for key, value := range globalProps.Map() {
 		fmt.Printf("\"%s\"=\"%s\"\n", key, value)
 	}
 	fmt.Println("**********************************************")
+	if globalProps.GetString(prop.WriteOrdering, prop.WriteOrderingDefault) == "strict" {
+		fmt.Println("[ORDERING] the writes of a thread to a key are issued in order")
+	} else {
+		fmt.Println("[ORDERING] the writes of a thread to a key may be reordered within a batch")
+	}
 
-	c := client.NewClient(globalProps, globalWorkload, globalDB)
-	start := time.Now()
-	c.Run(globalContext)
-	fmt.Println("**********************************************")
-	fmt.Printf("Run finished, takes %s\n", time.Now().Sub(start))
-	measurement.Output()
+	if globalProps.GetBool(prop.Manifest, prop.ManifestDefault) {
+		if err := client.WriteRunManifest(globalProps, dbName); err != nil {
+			fmt.Printf("[MANIFEST] %v\n", err)
+		}
+	}
+
+	fingerprint := globalProps.GetBool(prop.Fingerprint, prop.FingerprintDefault)
+	if fingerprint && doTransactions {
+		if err := client.VerifyManifest(globalContext, globalProps, globalDB); err != nil {
+			util.Fatalf("verify the data set failed %v", err)
+		}
+	}
+
+	var results []map[string]measurement.Result
+	for i := 1; i <= repeatArg; i++ {
+		if i > 1 {
+			fmt.Printf("***************** repetition %d of %d *****************\n", i, repeatArg)
+			prepareRepeat(dbName, doTransactions)
+		}
+
+		c := client.NewClient(globalProps, globalWorkload, globalDB)
+		start := time.Now()
+		c.Run(globalContext)
+		fmt.Println("**********************************************")
+		fmt.Printf("Run finished, takes %s\n", time.Now().Sub(start))
+		measurement.Output()
+		client.OutputEngineStats(globalContext, globalDB)
+		results = append(results, measurement.Results())
+
+		if fingerprint && !doTransactions {
+			if err := client.WriteManifest(globalContext, globalProps, globalDB); err != nil {
+				fmt.Printf("[FINGERPRINT] %v\n", err)
+			}
+		}
+
+		if globalOracle != nil {
+			globalOracle.Report()
+		}
+
+		if globalContext.Err() != nil {
+			break
+		}
+	}
+
+	if len(results) > 1 {
+		fmt.Printf("***************** %d repetitions *****************\n", len(results))
+		measurement.OutputRepeats(os.Stdout, globalProps, results)
+	}
+
+	resultsFile := globalProps.GetString(prop.ResultsFile, "")
+	if resultsFile == "" && cronArg != "" {
+		resultsFile = filepath.Join(globalProps.GetString(prop.OutputDir, prop.OutputDirDefault), "results.jsonl")
+	}
+	if resultsFile != "" {
+		if err := client.AppendResults(resultsFile, globalProps, dbName, results); err != nil {
+			fmt.Printf("[RESULTS] append to %s failed %v\n", resultsFile, err)
+		} else {
+			fmt.Printf("[RESULTS] appended to %s\n", resultsFile)
+		}
+	}
+}
+
+// prepareRepeat prepares the next repetition. A load always starts over from
+// an empty database, a run does so only with --fresh, after loading the data
+// again.
+func prepareRepeat(dbName string, doTransactions bool) {
+	if doTransactions && !freshArg {
+		measurement.InitMeasure(globalProps)
+		return
+	}
+
+	globalDB.Close()
+	globalWorkload.Close()
+	loadProps := util.PhaseProperties(globalProps, "load")
+	loadProps.Set(prop.DropData, "true")
+	loadProps.Set(prop.DoTransactions, "false")
+	loadProps.Set(prop.Command, "load")
+	globalDB, globalOracle = openDB(dbName, loadProps)
+
+	if doTransactions {
+		fmt.Println("***************** loading fresh data *****************")
+		measurement.InitMeasure(loadProps)
+		workload := createWorkload(loadProps)
+		client.NewClient(loadProps, workload, globalDB).Run(globalContext)
+		workload.Close()
+		if globalProps.GetBool(prop.Fingerprint, prop.FingerprintDefault) {
+			if err := client.WriteManifest(globalContext, loadProps, globalDB); err != nil {
+				fmt.Printf("[FINGERPRINT] %v\n", err)
+			}
+		}
+	}
+
+	globalWorkload = createWorkload(globalProps)
+	measurement.InitMeasure(globalProps)
 }
 
 func runLoadCommandFunc(cmd *cobra.Command, args []string) {
@@ -75,8 +225,44 @@ var (
 	threadsArg     int
 	targetArg      int
 	reportInterval int
+	repeatArg      int
+	freshArg       bool
+	cronArg        string
+
+	replayManifestArg string
+	// replayRun is the run recorded in the manifest given to --replay-manifest.
+	replayRun *client.ManifestRun
 )
 
+// readReplayManifest reads the run to replay from the manifest, it must be
+// recorded by the same command.
+func readReplayManifest(path string, dbName string, command string) *client.ManifestRun {
+	m, err := client.ReadManifest(path)
+	if err != nil {
+		util.Fatalf("read manifest %s failed %v", path, err)
+	}
+	run := m.Run
+	if run == nil {
+		util.Fatalf("%s doesn't record a run, it's written with %s=true", path, prop.Manifest)
+	}
+	if run.Command != command {
+		util.Fatalf("%s records a %s, replay it with the %s command", path, run.Command, run.Command)
+	}
+	if run.DB != dbName {
+		fmt.Printf("[MANIFEST] the run is recorded on %s, but replayed on %s\n", run.DB, dbName)
+	}
+	build := client.CurrentBuild()
+	if build.GitSHA != run.GitSHA {
+		fmt.Printf("[MANIFEST] the run is recorded by the revision %q, but replayed by %q\n", run.GitSHA, build.GitSHA)
+	}
+	for _, change := range build.EngineChanges(run.BuildInfo) {
+		fmt.Printf("[MANIFEST] the engine version changed since the run: %s\n", change)
+	}
+	fmt.Printf("[MANIFEST] replaying the %s started at %s on %s with the seed %d\n",
+		run.Command, run.Started.Format(time.RFC3339), run.Environment.Hostname, run.Seed)
+	return run
+}
+
 func initClientCommand(m *cobra.Command) {
 	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
 	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
@@ -84,6 +270,10 @@ func initClientCommand(m *cobra.Command) {
 	m.Flags().IntVar(&threadsArg, "threads", 1, "Execute using n threads - can also be specified as the \"threadcount\" property")
 	m.Flags().IntVar(&targetArg, "target", 0, "Attempt to do n operations per second (default: unlimited) - can also be specified as the \"target\" property")
 	m.Flags().IntVar(&reportInterval, "interval", 10, "Interval of outputting measurements in seconds")
+	m.Flags().IntVar(&repeatArg, "repeat", 1, "Run n times and report the mean, standard deviation and 95% confidence interval of the metrics")
+	m.Flags().BoolVar(&freshArg, "fresh", false, "Load the data again into an empty database before every repetition of a run")
+	m.Flags().StringVar(&cronArg, "cron", "", "Run again every time the cron schedule, like \"0 2 * * *\", is due until stopped")
+	m.Flags().StringVar(&replayManifestArg, "replay-manifest", "", "Execute the load or run recorded in the manifest again, the given properties override the recorded ones")
 }
 
 func newLoadCommand() *cobra.Command {
@@ -0,0 +1,71 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// codecMaxReported is the number of mismatches printed.
+const codecMaxReported = 20
+
+var (
+	codecRows int
+	codecSeed int64
+)
+
+func runCodecVerifyCommandFunc(cmd *cobra.Command, args []string) {
+	p := loadProperties()
+	codec := util.NewRowCodec(p)
+
+	seed := codecSeed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	fmt.Printf("[CODEC] round-trip %d rows, seed %d\n", codecRows, seed)
+
+	start := time.Now()
+	reported := 0
+	mismatches := util.VerifyRowCodec(codec, rand.New(rand.NewSource(seed)), codecRows, func(m util.CodecMismatch) {
+		if reported < codecMaxReported {
+			reported++
+			fmt.Print(m)
+		}
+	})
+	if mismatches > 0 {
+		util.Fatalf("[CODEC] %d of %d rows didn't round-trip, run again with --seed %d to reproduce them", mismatches, codecRows, seed)
+	}
+	fmt.Printf("[CODEC] all the rows round-tripped in %s\n", time.Since(start).Round(time.Millisecond))
+}
+
+func newCodecVerifyCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "codec-verify",
+		Short: "Round-trip random rows through the row codec and report any mismatch",
+		Args:  cobra.NoArgs,
+		Run:   runCodecVerifyCommandFunc,
+	}
+
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	m.Flags().IntVar(&codecRows, "rows", 1000000, "The number of rows to round-trip")
+	m.Flags().Int64Var(&codecSeed, "seed", 0, "The seed of the rows, 0 for a random one")
+	return m
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+var propertyHeader = []string{"Property", "Default", "Description"}
+
+func runListCommandFunc(cmd *cobra.Command, args []string) {
+	fmt.Println("Workloads:")
+	for _, name := range ycsb.WorkloadNames() {
+		fmt.Printf("  %s%s\n", name, describedSuffix(ycsb.GetWorkloadProperties(name)))
+	}
+	fmt.Println("Databases:")
+	for _, name := range ycsb.DBNames() {
+		fmt.Printf("  %s%s\n", name, describedSuffix(ycsb.GetDBProperties(name)))
+	}
+}
+
+func describedSuffix(props []ycsb.Property) string {
+	if len(props) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" (%d properties)", len(props))
+}
+
+func runDescribeCommandFunc(cmd *cobra.Command, args []string) {
+	kind, name := args[0], args[1]
+
+	var props []ycsb.Property
+	switch kind {
+	case "workload":
+		if ycsb.GetWorkloadCreator(name) == nil {
+			util.Fatalf("workload %s is not registered", name)
+		}
+		props = ycsb.GetWorkloadProperties(name)
+	case "db":
+		if ycsb.GetDBCreator(name) == nil {
+			util.Fatalf("%s is not registered", name)
+		}
+		props = ycsb.GetDBProperties(name)
+	default:
+		util.Fatalf("unknown kind %s, expected workload or db", kind)
+	}
+	if len(props) == 0 {
+		fmt.Printf("%s %s doesn't describe its properties, see the README\n", kind, name)
+		return
+	}
+
+	lines := make([][]string, 0, len(props))
+	for _, p := range props {
+		lines = append(lines, []string{p.Name, p.Default, p.Description})
+	}
+	util.RenderTable(os.Stdout, propertyHeader, lines)
+}
+
+func newListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the registered workloads and databases",
+		Args:  cobra.NoArgs,
+		Run:   runListCommandFunc,
+	}
+}
+
+func newDescribeCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "describe workload|db name",
+		Short: "Print the properties of a workload or a database with their defaults",
+		Args:  cobra.ExactArgs(2),
+		Run:   runDescribeCommandFunc,
+	}
+}
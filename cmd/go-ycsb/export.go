@@ -0,0 +1,56 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+func runExportCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	initialGlobal(dbName, func() {
+		globalProps.Set(prop.Command, "export")
+		// the exported data set must be kept.
+		globalProps.Set(prop.DropData, "false")
+		globalProps.Set(prop.OpLogSize, "0")
+		globalProps.Set(prop.Oracle, "false")
+	})
+
+	start := time.Now()
+	rows, err := client.Export(globalContext, globalProps, globalDB)
+	if err != nil {
+		util.Fatalf("Export failed after %d rows %v", rows, err)
+	}
+	fmt.Printf("Export finished, %d rows written to %s in %s\n",
+		rows, globalProps.GetString(prop.SpillDir, prop.SpillDirDefault), time.Now().Sub(start))
+}
+
+func newExportCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "export db",
+		Short: "Export a table to a spill file the ingest command can load, optionally anonymized",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runExportCommandFunc,
+	}
+
+	initClientCommand(m)
+	return m
+}
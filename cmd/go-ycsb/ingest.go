@@ -0,0 +1,71 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+func runIngestCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	initialGlobal(dbName, func() {
+		globalProps.Set(prop.DoTransactions, "false")
+		globalProps.Set(prop.Command, "ingest")
+		// the ingested rows must reach the binding as they are.
+		globalProps.Set(prop.OpLogSize, "0")
+		globalProps.Set(prop.Oracle, "false")
+
+		if cmd.Flags().Changed("threads") {
+			globalProps.Set(prop.ThreadCount, strconv.Itoa(threadsArg))
+		}
+	})
+
+	measurement.EnableWarmUp(false)
+	start := time.Now()
+	rows, err := client.Ingest(globalContext, globalProps, globalDB.(client.DbWrapper))
+	takes := time.Now().Sub(start)
+	fmt.Println("**********************************************")
+	fmt.Printf("Ingest finished, %d rows takes %s, %.1f rows/s\n", rows, takes, float64(rows)/takes.Seconds())
+	measurement.Output()
+	if err != nil {
+		util.Fatalf("ingest failed %v", err)
+	}
+
+	if globalProps.GetBool(prop.Fingerprint, prop.FingerprintDefault) {
+		if err := client.VerifyManifest(globalContext, globalProps, globalDB); err != nil {
+			util.Fatalf("verify the ingested data set failed %v", err)
+		}
+	}
+}
+
+func newIngestCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "ingest db",
+		Short: "Ingest the spill files written by `load spill` into the database",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runIngestCommandFunc,
+	}
+
+	initClientCommand(m)
+	return m
+}
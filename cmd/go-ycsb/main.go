@@ -38,48 +38,17 @@ import (
 	_ "github.com/pingcap/go-ycsb/pkg/workload"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 
-	// Register basic database
+	// Register the lua operation scripts
+	_ "github.com/pingcap/go-ycsb/pkg/script/lua"
+
+	// Register basic database, the other bindings are registered in the
+	// bindings_*.go files. A build with the slim tag only has the bindings
+	// whose tags are given too, e.g. -tags "slim fredb boltdb".
 	_ "github.com/pingcap/go-ycsb/db/basic"
-	// Register MySQL database
-	_ "github.com/pingcap/go-ycsb/db/mysql"
-	// Register TiKV database
-	_ "github.com/pingcap/go-ycsb/db/tikv"
-	// Register PostgreSQL database
-	_ "github.com/pingcap/go-ycsb/db/pg"
-	// Register Aerospike database
-	_ "github.com/pingcap/go-ycsb/db/aerospike"
-	// Register Badger database
-	_ "github.com/pingcap/go-ycsb/db/badger"
-	// Register FoundationDB database
-	_ "github.com/pingcap/go-ycsb/db/foundationdb"
-	// Register RocksDB database
-	_ "github.com/pingcap/go-ycsb/db/rocksdb"
-	// Register Spanner database
-	_ "github.com/pingcap/go-ycsb/db/spanner"
-	// Register pegasus database
-	_ "github.com/pingcap/go-ycsb/db/pegasus"
-	// Register sqlite database
-	_ "github.com/pingcap/go-ycsb/db/sqlite"
-	// Register cassandra database
-	_ "github.com/pingcap/go-ycsb/db/cassandra"
-	// Register mongodb database
-	_ "github.com/pingcap/go-ycsb/db/mongodb"
-	// Register redis database
-	_ "github.com/pingcap/go-ycsb/db/redis"
-	// Register boltdb database
-	_ "github.com/pingcap/go-ycsb/db/boltdb"
-	// Register minio
-	_ "github.com/pingcap/go-ycsb/db/minio"
-	// Register elastic
-	_ "github.com/pingcap/go-ycsb/db/elasticsearch"
-	// Register etcd
-	_ "github.com/pingcap/go-ycsb/db/etcd"
-	// Register dynamodb
-	_ "github.com/pingcap/go-ycsb/db/dynamodb"
-	// Register s3 database
-	_ "github.com/pingcap/go-ycsb/db/s3"
-	// Register fredb database
-	_ "github.com/pingcap/go-ycsb/db/fredb"
+	// Register selftest database
+	_ "github.com/pingcap/go-ycsb/db/selftest"
+	// Register spill database
+	_ "github.com/pingcap/go-ycsb/db/spill"
 )
 
 var (
@@ -92,14 +61,26 @@ var (
 	globalCancel  context.CancelFunc
 
 	globalDB       ycsb.DB
+	globalOracle   *client.OracleDB
 	globalWorkload ycsb.Workload
 	globalProps    *properties.Properties
 )
 
 func initialGlobal(dbName string, onProperties func()) {
-	globalProps = properties.NewProperties()
+	initialProps(onProperties)
+	globalWorkload = createWorkload(globalProps)
+	globalDB, globalOracle = openDB(dbName, globalProps)
+}
+
+// loadProperties loads the property files and values of the command line.
+func loadProperties() *properties.Properties {
+	p := properties.NewProperties()
+	if replayRun != nil {
+		// the recorded properties come first, the given ones override them.
+		p = properties.LoadMap(replayRun.Properties)
+	}
 	if len(propertyFiles) > 0 {
-		globalProps = properties.MustLoadFiles(propertyFiles, properties.UTF8, false)
+		p.Merge(properties.MustLoadFiles(propertyFiles, properties.UTF8, false))
 	}
 
 	for _, prop := range propertyValues {
@@ -107,8 +88,14 @@ func initialGlobal(dbName string, onProperties func()) {
 		if len(seps) != 2 {
 			log.Fatalf("bad property: `%s`, expected format `name=value`", prop)
 		}
-		globalProps.Set(seps[0], seps[1])
+		p.Set(seps[0], seps[1])
 	}
+	return p
+}
+
+// initialProps loads the properties and initializes the measurement.
+func initialProps(onProperties func()) {
+	globalProps = loadProperties()
 
 	if onProperties != nil {
 		onProperties()
@@ -130,21 +117,51 @@ func initialGlobal(dbName string, onProperties func()) {
 		panic(err)
 	}
 
-	workloadName := globalProps.GetString(prop.Workload, "core")
+}
+
+func createWorkload(p *properties.Properties) ycsb.Workload {
+	workloadName := p.GetString(prop.Workload, "core")
 	workloadCreator := ycsb.GetWorkloadCreator(workloadName)
 
-	if globalWorkload, err = workloadCreator.Create(globalProps); err != nil {
+	workload, err := workloadCreator.Create(p)
+	if err != nil {
 		util.Fatalf("create workload %s failed %v", workloadName, err)
 	}
+	return workload
+}
 
+// openDB opens the database with its middlewares and wrappers, it also
+// returns the oracle if it's enabled.
+func openDB(dbName string, p *properties.Properties) (ycsb.DB, *client.OracleDB) {
 	dbCreator := ycsb.GetDBCreator(dbName)
 	if dbCreator == nil {
-		util.Fatalf("%s is not registered", dbName)
+		util.Fatalf("%s is not registered, the binary is built with %s", dbName, strings.Join(ycsb.DBNames(), ", "))
 	}
-	if globalDB, err = dbCreator.Create(globalProps); err != nil {
+	db, err := dbCreator.Create(p)
+	if err != nil {
 		util.Fatalf("create db %s failed %v", dbName, err)
 	}
-	globalDB = client.DbWrapper{globalDB}
+	if shadowName := p.GetString(prop.ShadowDB, ""); shadowName != "" {
+		shadowCreator := ycsb.GetDBCreator(shadowName)
+		if shadowCreator == nil {
+			util.Fatalf("%s is not registered", shadowName)
+		}
+		shadow, err := shadowCreator.Create(util.OverrideProperties(p, prop.ShadowPrefix))
+		if err != nil {
+			util.Fatalf("create shadow db %s failed %v", shadowName, err)
+		}
+		db = client.NewShadowDB(p, db, shadow)
+	}
+	if db, err = client.NewMiddlewareDB(p, db); err != nil {
+		util.Fatalf("create db %s failed %v", dbName, err)
+	}
+	db = client.NewOpLogDB(p, db)
+	var oracle *client.OracleDB
+	if p.GetBool(prop.Oracle, prop.OracleDefault) {
+		oracle = client.NewOracleDB(db)
+		db = oracle
+	}
+	return client.DbWrapper{db}, oracle
 }
 
 func main() {
@@ -160,6 +177,10 @@ func main() {
 	closeDone := make(chan struct{}, 1)
 	go func() {
 		sig := <-sc
+		for sig == syscall.SIGHUP && client.ReloadsOnHangup() {
+			// the client reloads the target file.
+			sig = <-sc
+		}
 		fmt.Printf("\nGot signal [%v] to exit.\n", sig)
 		globalCancel()
 
@@ -176,15 +197,40 @@ func main() {
 		}
 	}()
 
+	var listDBs bool
 	rootCmd := &cobra.Command{
 		Use:   "go-ycsb",
 		Short: "Go YCSB",
+		Run: func(cmd *cobra.Command, _ []string) {
+			if !listDBs {
+				cmd.Help()
+				return
+			}
+			for _, name := range ycsb.DBNames() {
+				fmt.Println(name)
+			}
+		},
 	}
+	rootCmd.Version = client.CurrentBuild().String()
+	rootCmd.SetVersionTemplate("{{.Version}}\n")
+	rootCmd.Flags().BoolVar(&listDBs, "list-dbs", false, "List the database bindings built into the binary")
 
 	rootCmd.AddCommand(
 		newShellCommand(),
+		newCliCommand(),
 		newLoadCommand(),
 		newRunCommand(),
+		newSelfTestCommand(),
+		newReplayCommand(),
+		newIngestCommand(),
+		newMigrateCommand(),
+		newExportCommand(),
+		newABCommand(),
+		newPoolCommand(),
+		newPlotCommand(),
+		newCodecVerifyCommand(),
+		newListCommand(),
+		newDescribeCommand(),
 	)
 
 	cobra.EnablePrefixMatching = true
@@ -203,4 +249,8 @@ func main() {
 	}
 
 	closeDone <- struct{}{}
+
+	if cliExitCode != 0 {
+		os.Exit(cliExitCode)
+	}
 }
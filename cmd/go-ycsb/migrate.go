@@ -0,0 +1,62 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+func runMigrateCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	initialGlobal(dbName, func() {
+		globalProps.Set(prop.DoTransactions, "false")
+		globalProps.Set(prop.Command, "migrate")
+		// the data set is rewritten in place.
+		globalProps.Set(prop.DropData, "false")
+		globalProps.Set(prop.OpLogSize, "0")
+		globalProps.Set(prop.Oracle, "false")
+		globalProps.Set(prop.ScanMissingStartKey, "seek")
+	})
+
+	measurement.EnableWarmUp(false)
+	start := time.Now()
+	rows, err := client.Migrate(globalContext, globalProps, globalDB)
+	takes := time.Now().Sub(start)
+	fmt.Println("**********************************************")
+	if err != nil {
+		util.Fatalf("Migrate failed after %d rows %v", rows, err)
+	}
+	fmt.Printf("Migrate finished, %d rows takes %s, %.1f rows/s\n", rows, takes, float64(rows)/takes.Seconds())
+	measurement.Output()
+}
+
+func newMigrateCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "migrate db",
+		Short: "Rewrite the rows of a loaded data set in the current row format",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runMigrateCommandFunc,
+	}
+
+	initClientCommand(m)
+	return m
+}
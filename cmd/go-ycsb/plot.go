@@ -0,0 +1,220 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+// plotPercentilesSuffix is the suffix of the files written by
+// histogram.percentiles.export.
+const plotPercentilesSuffix = "-percentiles.txt"
+
+// plotMaxPoints bounds the points of a latency CDF line.
+const plotMaxPoints = 500
+
+var (
+	plotOutputDir string
+	plotInterval  time.Duration
+)
+
+// readRawCSV reads the latencies in microseconds and their start times in
+// microseconds of every operation of a raw or csv measurement output.
+func readRawCSV(path string) (starts map[string][]int64, latencies map[string][]int64, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer f.Close()
+
+	starts = make(map[string][]int64)
+	latencies = make(map[string][]int64)
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if line == 1 && strings.HasPrefix(text, "operation,") || text == "" {
+			continue
+		}
+		cols := strings.Split(text, ",")
+		if len(cols) != 3 {
+			return nil, nil, fmt.Errorf("%s:%d: want operation,timestamp_us,latency_us", path, line)
+		}
+		start, err1 := strconv.ParseInt(cols[1], 10, 64)
+		lat, err2 := strconv.ParseInt(cols[2], 10, 64)
+		if err1 != nil || err2 != nil {
+			return nil, nil, fmt.Errorf("%s:%d: bad timestamp or latency", path, line)
+		}
+		starts[cols[0]] = append(starts[cols[0]], start)
+		latencies[cols[0]] = append(latencies[cols[0]], lat)
+	}
+	return starts, latencies, scanner.Err()
+}
+
+// readPercentiles reads the latency distribution exported by
+// histogram.percentiles.export, the percentiles are in 0-100.
+func readPercentiles(path string) (util.ChartSeries, error) {
+	s := util.ChartSeries{Name: strings.TrimSuffix(filepath.Base(path), plotPercentilesSuffix)}
+	f, err := os.Open(path)
+	if err != nil {
+		return s, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		cols := strings.Fields(scanner.Text())
+		if len(cols) < 2 || strings.HasPrefix(cols[0], "#") || cols[0] == "Value" {
+			continue
+		}
+		value, err1 := strconv.ParseFloat(cols[0], 64)
+		percentile, err2 := strconv.ParseFloat(cols[1], 64)
+		if err1 != nil || err2 != nil {
+			return s, fmt.Errorf("%s: bad line %q", path, scanner.Text())
+		}
+		s.X = append(s.X, value)
+		s.Y = append(s.Y, percentile*100)
+	}
+	return s, scanner.Err()
+}
+
+func sortedOps(m map[string][]int64) []string {
+	ops := make([]string, 0, len(m))
+	for op := range m {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// throughputChart plots the operations per second of every operation over
+// the time of the run.
+func throughputChart(title string, starts map[string][]int64, interval time.Duration) *util.Chart {
+	first := int64(-1)
+	for _, ts := range starts {
+		for _, t := range ts {
+			if first < 0 || t < first {
+				first = t
+			}
+		}
+	}
+
+	c := &util.Chart{Title: title, XLabel: "time (s)", YLabel: "ops/sec"}
+	width := interval.Microseconds()
+	for _, op := range sortedOps(starts) {
+		var counts []int64
+		for _, t := range starts[op] {
+			i := int((t - first) / width)
+			for len(counts) <= i {
+				counts = append(counts, 0)
+			}
+			counts[i]++
+		}
+		s := util.ChartSeries{Name: op}
+		for i, n := range counts {
+			s.X = append(s.X, float64(i)*interval.Seconds())
+			s.Y = append(s.Y, float64(n)/interval.Seconds())
+		}
+		c.Series = append(c.Series, s)
+	}
+	return c
+}
+
+// latencyChart plots the cumulative distribution of the latencies of every
+// operation.
+func latencyChart(title string, latencies map[string][]int64) *util.Chart {
+	c := &util.Chart{Title: title, XLabel: "latency (us)", YLabel: "percentile", LogX: true}
+	for _, op := range sortedOps(latencies) {
+		lats := latencies[op]
+		sort.Slice(lats, func(i, j int) bool { return lats[i] < lats[j] })
+
+		s := util.ChartSeries{Name: op}
+		step := (len(lats) + plotMaxPoints - 1) / plotMaxPoints
+		for i := step - 1; i < len(lats); i += step {
+			// the microsecond resolution measures fast operations as 0.
+			s.X = append(s.X, float64(max(lats[i], 1)))
+			s.Y = append(s.Y, float64(i+1)/float64(len(lats))*100)
+		}
+		c.Series = append(c.Series, s)
+	}
+	return c
+}
+
+func writeChart(c *util.Chart, name string) {
+	path := filepath.Join(plotOutputDir, name)
+	f, err := os.Create(path)
+	if err != nil {
+		util.Fatalf("create %s failed %v", path, err)
+	}
+	if err = c.WriteSVG(f); err == nil {
+		err = f.Close()
+	}
+	if err != nil {
+		util.Fatalf("write %s failed %v", path, err)
+	}
+	fmt.Printf("%s\n", path)
+}
+
+func runPlotCommandFunc(cmd *cobra.Command, args []string) {
+	if plotInterval <= 0 {
+		util.Fatalf("the interval must be positive")
+	}
+	if err := os.MkdirAll(plotOutputDir, 0755); err != nil {
+		util.Fatalf("create %s failed %v", plotOutputDir, err)
+	}
+
+	percentiles := &util.Chart{Title: "latency distribution", XLabel: "latency (us)", YLabel: "percentile", LogX: true}
+	for _, path := range args {
+		if strings.HasSuffix(path, plotPercentilesSuffix) {
+			s, err := readPercentiles(path)
+			if err != nil {
+				util.Fatalf("read %s failed %v", path, err)
+			}
+			percentiles.Series = append(percentiles.Series, s)
+			continue
+		}
+
+		starts, latencies, err := readRawCSV(path)
+		if err != nil {
+			util.Fatalf("read %s failed %v", path, err)
+		}
+		base := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		writeChart(throughputChart(base+" throughput", starts, plotInterval), base+"-throughput.svg")
+		writeChart(latencyChart(base+" latency distribution", latencies), base+"-latency.svg")
+	}
+	if len(percentiles.Series) > 0 {
+		writeChart(percentiles, "percentiles-latency.svg")
+	}
+}
+
+func newPlotCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "plot file...",
+		Short: "Render the raw measurements and the exported percentiles into SVG charts",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runPlotCommandFunc,
+	}
+	m.Flags().StringVarP(&plotOutputDir, "output", "o", ".", "The directory to write the charts to")
+	m.Flags().DurationVar(&plotInterval, "interval", time.Second, "The time step of the throughput charts")
+	return m
+}
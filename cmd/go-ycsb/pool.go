@@ -0,0 +1,195 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+	"github.com/spf13/cobra"
+)
+
+func openPool(p *properties.Properties) *client.Pool {
+	pool := client.OpenPool(p)
+	if pool == nil {
+		util.Fatalf("the pool needs %s", prop.PoolDir)
+	}
+	return pool
+}
+
+func poolDataPath(dbName string) ycsb.DataPath {
+	dataPath, ok := ycsb.GetDBDataPath(dbName)
+	if !ok {
+		util.Fatalf("%s doesn't keep its data in local files, it can't use a pool", dbName)
+	}
+	return dataPath
+}
+
+// buildPoolDataset loads the data set of the global properties into the pool.
+func buildPoolDataset(dbName string, pool *client.Pool, name string) {
+	path, err := pool.Create(name)
+	if err != nil {
+		util.Fatalf("create data set %s failed %v", name, err)
+	}
+
+	loadProps := util.PhaseProperties(globalProps, "load")
+	loadProps.Set(prop.DropData, "true")
+	loadProps.Set(prop.DoTransactions, "false")
+	loadProps.Set(prop.Command, "load")
+	loadProps.Set(poolDataPath(dbName).Property, path)
+
+	fmt.Printf("***************** building data set %s *****************\n", name)
+	measurement.InitMeasure(loadProps)
+	workload := createWorkload(loadProps)
+	db, _ := openDB(dbName, loadProps)
+	start := time.Now()
+	client.NewClient(loadProps, workload, db).Run(globalContext)
+	fmt.Printf("Load finished, takes %s\n", time.Now().Sub(start))
+	measurement.Output()
+	workload.Close()
+	db.Close()
+	if globalContext.Err() != nil {
+		util.Fatalf("the load of data set %s is interrupted", name)
+	}
+
+	ds := &client.PoolDataset{
+		Name:      name,
+		DB:        dbName,
+		Created:   time.Now(),
+		Signature: client.DatasetSignature(globalProps, dbName),
+	}
+	if err := pool.Add(ds); err != nil {
+		util.Fatalf("add data set %s failed %v", name, err)
+	}
+	fmt.Printf("[POOL] data set %s is added to %s\n", name, path)
+}
+
+// restorePoolDataset restores the data set matching the properties of the
+// run from the pool, so the run doesn't need a load phase. A missing data set
+// is built first.
+func restorePoolDataset(dbName string) {
+	pool := client.OpenPool(globalProps)
+	if pool == nil {
+		return
+	}
+
+	dataPath := poolDataPath(dbName)
+	name := client.DatasetName(dbName, client.DatasetSignature(globalProps, dbName))
+	if _, ok := pool.Find(name); !ok {
+		fmt.Printf("[POOL] no data set in %s matches the properties\n", globalProps.GetString(prop.PoolDir, ""))
+		buildPoolDataset(dbName, pool, name)
+		measurement.InitMeasure(globalProps)
+	}
+
+	path := globalProps.GetString(dataPath.Property, dataPath.Default)
+	start := time.Now()
+	if err := pool.Restore(name, path); err != nil {
+		util.Fatalf("restore data set %s failed %v", name, err)
+	}
+	// the restored data must not be dropped when the database is opened.
+	globalProps.Set(prop.DropData, "false")
+	fmt.Printf("[POOL] data set %s is restored to %s in %s, the load is skipped\n",
+		name, path, time.Now().Sub(start).Round(time.Millisecond))
+}
+
+func runPoolBuildCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+	initialProps(nil)
+	pool := openPool(globalProps)
+	buildPoolDataset(dbName, pool, client.DatasetName(dbName, client.DatasetSignature(globalProps, dbName)))
+}
+
+func runPoolListCommandFunc(cmd *cobra.Command, args []string) {
+	pool := openPool(loadProperties())
+	datasets, err := pool.List()
+	if err != nil {
+		util.Fatalf("list the pool failed %v", err)
+	}
+
+	for _, ds := range datasets {
+		keys := make([]string, 0, len(ds.Signature))
+		for key := range ds.Signature {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sig := make([]string, 0, len(keys))
+		for _, key := range keys {
+			sig = append(sig, key+"="+ds.Signature[key])
+		}
+
+		var size int64
+		filepath.Walk(pool.DataPath(ds.Name), func(_ string, info os.FileInfo, err error) error {
+			if err == nil && !info.IsDir() {
+				size += info.Size()
+			}
+			return nil
+		})
+		fmt.Printf("%s\t%s\t%d MB\t%s\n", ds.Name, ds.Created.Format(time.RFC3339), size>>20, strings.Join(sig, " "))
+	}
+}
+
+func runPoolRemoveCommandFunc(cmd *cobra.Command, args []string) {
+	pool := openPool(loadProperties())
+	for _, name := range args {
+		if err := pool.Remove(name); err != nil {
+			util.Fatalf("remove data set failed %v", err)
+		}
+		fmt.Printf("[POOL] data set %s is removed\n", name)
+	}
+}
+
+func initPoolCommand(m *cobra.Command) {
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+}
+
+func newPoolCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "pool",
+		Short: "Maintain a pool of pre-built data sets for the run phase",
+	}
+
+	build := &cobra.Command{
+		Use:   "build db",
+		Short: "Load the data set of the properties into the pool",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runPoolBuildCommandFunc,
+	}
+	list := &cobra.Command{
+		Use:   "list",
+		Short: "List the data sets of the pool",
+		Run:   runPoolListCommandFunc,
+	}
+	remove := &cobra.Command{
+		Use:   "remove name...",
+		Short: "Remove data sets from the pool",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runPoolRemoveCommandFunc,
+	}
+	for _, c := range []*cobra.Command{build, list, remove} {
+		initPoolCommand(c)
+		m.AddCommand(c)
+	}
+	return m
+}
@@ -0,0 +1,62 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+
+	"github.com/pingcap/go-ycsb/pkg/client"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/spf13/cobra"
+)
+
+var replayFile string
+
+func runReplayCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := args[0]
+
+	records, err := client.ReadOpLog(replayFile)
+	if err != nil {
+		util.Fatalf("read repro file %s failed %v", replayFile, err)
+	}
+
+	initialGlobal(dbName, func() {
+		// replay against a fresh database, recording it again is pointless.
+		globalProps.Set(prop.DropData, "true")
+		globalProps.Set(prop.OpLogSize, "0")
+	})
+
+	ctx := globalWorkload.InitThread(globalContext, 0, 1)
+	ctx = globalDB.InitThread(ctx, 0, 1)
+	diverged := client.Replay(ctx, globalDB, records)
+	globalDB.CleanupThread(ctx)
+	globalWorkload.CleanupThread(ctx)
+
+	fmt.Printf("Replayed %d operations, %d diverged from the recording\n", len(records), diverged)
+}
+
+func newReplayCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "replay db",
+		Short: "Replay a repro file written by the operation log against a fresh database",
+		Args:  cobra.MinimumNArgs(1),
+		Run:   runReplayCommandFunc,
+	}
+	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
+	m.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+	m.Flags().StringVarP(&replayFile, "file", "f", "", "The repro file to replay")
+	m.MarkFlagRequired("file")
+	return m
+}
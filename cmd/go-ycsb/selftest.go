@@ -0,0 +1,41 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"github.com/spf13/cobra"
+)
+
+var selfTestLoad bool
+
+func runSelfTestCommandFunc(cmd *cobra.Command, args []string) {
+	command := "run"
+	if selfTestLoad {
+		command = "load"
+	}
+	runClientCommandFunc(cmd, []string{"selftest"}, !selfTestLoad, command)
+}
+
+func newSelfTestCommand() *cobra.Command {
+	m := &cobra.Command{
+		Use:   "selftest",
+		Short: "Measure the workload generators and row codec without any database",
+		Args:  cobra.NoArgs,
+		Run:   runSelfTestCommandFunc,
+	}
+
+	initClientCommand(m)
+	m.Flags().BoolVar(&selfTestLoad, "load", false, "Generate the load phase inserts instead of the run phase operations")
+	return m
+}
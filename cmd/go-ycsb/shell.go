@@ -17,21 +17,23 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"sort"
 	"strconv"
 	"strings"
 
 	"github.com/chzyer/readline"
 	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
 
 	"github.com/spf13/cobra"
 )
 
 func newShellCommand() *cobra.Command {
 	m := &cobra.Command{
-		Use:   "shell db",
-		Short: "YCSB Command Line Client",
-		Args:  cobra.MinimumNArgs(1),
+		Use:   "shell [db]",
+		Short: "YCSB Command Line Client, on fredb by default",
+		Args:  cobra.MaximumNArgs(1),
 		Run:   runShellCommandFunc,
 	}
 	m.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Spefify a property file")
@@ -43,7 +45,10 @@ func newShellCommand() *cobra.Command {
 var shellContext context.Context
 
 func runShellCommandFunc(cmd *cobra.Command, args []string) {
-	dbName := args[0]
+	dbName := "fredb"
+	if len(args) > 0 {
+		dbName = args[0]
+	}
 	initialGlobal(dbName, nil)
 
 	shellContext = globalWorkload.InitThread(globalContext, 0, 1)
@@ -100,6 +105,13 @@ func runShellCommand(args []string) {
 			Run:                   runShellDeleteCommand,
 			DisableFlagsInUseLine: true,
 		},
+		&cobra.Command{
+			Use:                   "stats",
+			Short:                 "Print the engine statistics",
+			Args:                  cobra.NoArgs,
+			Run:                   runShellStatsCommand,
+			DisableFlagsInUseLine: true,
+		},
 		&cobra.Command{
 			Use:                   "table [tablename]",
 			Short:                 "Get or [set] the name of the table",
@@ -129,11 +141,34 @@ func runShellReadCommand(cmd *cobra.Command, args []string) {
 	}
 
 	fmt.Printf("Read %s ok\n", key)
-	for key, value := range row {
-		fmt.Printf("%s=%q\n", key, value)
+	printShellRow(row)
+}
+
+// printShellRow prints the fields of the row sorted by name.
+func printShellRow(row map[string][]byte) {
+	fields := make([]string, 0, len(row))
+	for field := range row {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		fmt.Printf("%s=%q\n", field, row[field])
 	}
 }
 
+// parseShellValues parses the field=value arguments.
+func parseShellValues(args []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(args))
+	for _, arg := range args {
+		sep := strings.SplitN(arg, "=", 2)
+		if len(sep) != 2 {
+			return nil, fmt.Errorf("bad value `%s`, expected format `field=value`", arg)
+		}
+		values[sep[0]] = []byte(sep[1])
+	}
+	return values, nil
+}
+
 func runShellScanCommand(cmd *cobra.Command, args []string) {
 	key := args[0]
 	recordCount, err := strconv.Atoi(args[1])
@@ -143,7 +178,15 @@ func runShellScanCommand(cmd *cobra.Command, args []string) {
 	}
 	fields := args[2:]
 
-	rows, err := globalDB.Scan(shellContext, tableName, key, recordCount, fields)
+	// the bindings returning the scanned keys show which record is which.
+	var keys []string
+	var rows []map[string][]byte
+	if scanDB, ok := globalDB.(ycsb.ScanKeysDB); ok {
+		keys, rows, err = scanDB.ScanKeys(shellContext, tableName, key, recordCount, fields)
+	}
+	if keys == nil {
+		rows, err = globalDB.Scan(shellContext, tableName, key, recordCount, fields)
+	}
 	if err != nil {
 		fmt.Printf("Scan from %s with %d failed %v\n", key, recordCount, err)
 		return
@@ -156,21 +199,22 @@ func runShellScanCommand(cmd *cobra.Command, args []string) {
 
 	fmt.Println("--------------------------------")
 	for i, row := range rows {
-		fmt.Printf("Record %d\n", i+1)
-		for key, value := range row {
-			fmt.Printf("%s=%q\n", key, value)
+		if keys != nil {
+			fmt.Printf("Record %d: %s\n", i+1, keys[i])
+		} else {
+			fmt.Printf("Record %d\n", i+1)
 		}
+		printShellRow(row)
 	}
 	fmt.Println("--------------------------------")
 }
 
 func runShellInsertCommand(cmd *cobra.Command, args []string) {
 	key := args[0]
-	values := make(map[string][]byte, len(args[1:]))
-
-	for _, arg := range args[1:] {
-		sep := strings.SplitN(arg, "=", 2)
-		values[sep[0]] = []byte(sep[1])
+	values, err := parseShellValues(args[1:])
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
 	if err := globalDB.Insert(shellContext, tableName, key, values); err != nil {
@@ -183,11 +227,10 @@ func runShellInsertCommand(cmd *cobra.Command, args []string) {
 
 func runShellUpdateCommand(cmd *cobra.Command, args []string) {
 	key := args[0]
-	values := make(map[string][]byte, len(args[1:]))
-
-	for _, arg := range args[1:] {
-		sep := strings.SplitN(arg, "=", 2)
-		values[sep[0]] = []byte(sep[1])
+	values, err := parseShellValues(args[1:])
+	if err != nil {
+		fmt.Println(err)
+		return
 	}
 
 	if err := globalDB.Update(shellContext, tableName, key, values); err != nil {
@@ -208,6 +251,32 @@ func runShellDeleteCommand(cmd *cobra.Command, args []string) {
 	fmt.Printf("Delete %s ok\n", key)
 }
 
+func runShellStatsCommand(cmd *cobra.Command, args []string) {
+	statsDB, ok := globalDB.(ycsb.StatsDB)
+	if !ok {
+		fmt.Println("the database doesn't report statistics")
+		return
+	}
+	stats, err := statsDB.Stats(shellContext)
+	if err != nil {
+		fmt.Printf("Stats failed %v\n", err)
+		return
+	}
+	if len(stats) == 0 {
+		fmt.Println("the database doesn't report statistics")
+		return
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("%s=%v\n", name, stats[name])
+	}
+}
+
 func runShellTableCommand(cmd *cobra.Command, args []string) {
 	if len(args) == 1 {
 		tableName = args[0]
@@ -0,0 +1,110 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// ycsb-agent hosts a database for the remote binding of go-ycsb, so the
+// client generating the load and the storage run on separate machines.
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/magiconair/properties"
+	"github.com/spf13/cobra"
+
+	"github.com/pingcap/go-ycsb/pkg/proxy"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+
+	// Register fredb database
+	_ "github.com/pingcap/go-ycsb/db/fredb"
+)
+
+// properties
+const (
+	agentAddr        = "agent.addr"
+	agentAddrDefault = ":7380"
+)
+
+var (
+	propertyFiles  []string
+	propertyValues []string
+)
+
+func loadProperties() *properties.Properties {
+	p := properties.NewProperties()
+	if len(propertyFiles) > 0 {
+		p = properties.MustLoadFiles(propertyFiles, properties.UTF8, false)
+	}
+	for _, prop := range propertyValues {
+		seps := strings.SplitN(prop, "=", 2)
+		if len(seps) != 2 {
+			log.Fatalf("bad property: `%s`, expected format `name=value`", prop)
+		}
+		p.Set(seps[0], seps[1])
+	}
+	return p
+}
+
+func runAgentCommandFunc(cmd *cobra.Command, args []string) {
+	dbName := "fredb"
+	if len(args) > 0 {
+		dbName = args[0]
+	}
+	p := loadProperties()
+
+	dbCreator := ycsb.GetDBCreator(dbName)
+	if dbCreator == nil {
+		util.Fatalf("%s is not registered", dbName)
+	}
+	db, err := dbCreator.Create(p)
+	if err != nil {
+		util.Fatalf("create db %s failed %v", dbName, err)
+	}
+	defer db.Close()
+
+	addr := p.GetString(agentAddr, agentAddrDefault)
+	l, err := net.Listen("tcp", addr)
+	if err != nil {
+		util.Fatalf("listen on %s failed %v", addr, err)
+	}
+	fmt.Printf("[AGENT] serving %s on %s\n", dbName, l.Addr())
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+	if err := proxy.NewServer(db).Serve(ctx, l); err != nil {
+		util.Fatalf("serve failed %v", err)
+	}
+	fmt.Println("[AGENT] stopped")
+}
+
+func main() {
+	rootCmd := &cobra.Command{
+		Use:   "ycsb-agent [db]",
+		Short: "Host a database for the remote binding of go-ycsb, fredb by default",
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runAgentCommandFunc,
+	}
+	rootCmd.Flags().StringSliceVarP(&propertyFiles, "property_file", "P", nil, "Specify a property file")
+	rootCmd.Flags().StringArrayVarP(&propertyValues, "prop", "p", nil, "Specify a property value with name=value")
+
+	if err := rootCmd.Execute(); err != nil {
+		os.Exit(1)
+	}
+}
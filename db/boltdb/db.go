@@ -50,6 +50,8 @@ const (
 	boltInitialMmapSize = "bolt.initial_mmap_size"
 )
 
+const boltPathDefault = "/tmp/boltdb"
+
 type boltCreator struct {
 }
 
@@ -89,7 +91,7 @@ func (c boltCreator) Create(p *properties.Properties) (ycsb.DB, error) {
 }
 
 func getOptions(p *properties.Properties) boltOptions {
-	path := p.GetString(boltPath, "/tmp/boltdb")
+	path := p.GetString(boltPath, boltPathDefault)
 
 	opts := bolt.DefaultOptions
 	opts.Timeout = p.GetDuration(boltTimeout, 0)
@@ -241,4 +243,5 @@ func (db *boltDB) Delete(ctx context.Context, table string, key string) error {
 
 func init() {
 	ycsb.RegisterDBCreator("boltdb", boltCreator{})
+	ycsb.RegisterDBDataPath("boltdb", ycsb.DataPath{Property: boltPath, Default: boltPathDefault})
 }
@@ -0,0 +1,305 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"bytes"
+	"sort"
+	"strconv"
+
+	"github.com/alexhholmes/fredb"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// singleBucket is the bucket of every table with fredb.single_bucket.
+const singleBucket = "ycsb"
+
+// tableBucket is the bucket of a table. With fredb.nested_buckets the rows
+// are spread over the shard buckets `<table>/<shard>` by the hash of their
+// key, so the writers of the load don't all go down the same B+tree. fredb
+// has no buckets in buckets, so the shards are top level buckets and the
+// first one marks that the table exists. With fredb.single_bucket the tables
+// share one bucket and their keys start with the table name.
+type tableBucket struct {
+	tx   *fredb.Tx
+	name []byte
+	// top is the bucket of the rows, nil if they are in the shards.
+	top *fredb.Bucket
+	// shards is the number of shard buckets, 0 if the rows are in top.
+	shards int
+	// prefix is the table name and a 0 byte the keys start with, nil if the
+	// table has a bucket of its own.
+	prefix []byte
+}
+
+// bucketName returns the name of the bucket of the table.
+func (db *freDB) bucketName(table string) []byte {
+	if db.singleBucket {
+		return []byte(singleBucket)
+	}
+	return []byte(table)
+}
+
+func (db *freDB) newTableBucket(tx *fredb.Tx, top *fredb.Bucket, table string) *tableBucket {
+	b := &tableBucket{tx: tx, name: db.bucketName(table), top: top, shards: db.bucketShards}
+	if db.singleBucket {
+		b.prefix = append([]byte(table), 0)
+	}
+	return b
+}
+
+// table returns the bucket of the table, nil if it doesn't exist.
+func (db *freDB) table(tx *fredb.Tx, table string) *tableBucket {
+	if db.bucketShards > 0 {
+		if tx.Bucket(shardBucketName(db.bucketName(table), 0)) == nil {
+			return nil
+		}
+		return db.newTableBucket(tx, nil, table)
+	}
+	top := tx.Bucket(db.bucketName(table))
+	if top == nil {
+		return nil
+	}
+	return db.newTableBucket(tx, top, table)
+}
+
+// createTable returns the bucket of the table, creating it if it doesn't
+// exist.
+func (db *freDB) createTable(tx *fredb.Tx, table string) (*tableBucket, error) {
+	if db.bucketShards > 0 {
+		if _, err := tx.CreateBucketIfNotExists(shardBucketName(db.bucketName(table), 0)); err != nil {
+			return nil, err
+		}
+		return db.newTableBucket(tx, nil, table), nil
+	}
+	top, err := tx.CreateBucketIfNotExists(db.bucketName(table))
+	if err != nil {
+		return nil, err
+	}
+	return db.newTableBucket(tx, top, table), nil
+}
+
+// key returns the key the row is stored under in top or its shard.
+func (b *tableBucket) key(key []byte) []byte {
+	if b.prefix == nil {
+		return key
+	}
+	return prefixed(b.prefix, key)
+}
+
+func prefixed(prefix []byte, key []byte) []byte {
+	k := make([]byte, 0, len(prefix)+len(key))
+	return append(append(k, prefix...), key...)
+}
+
+// shardBucketName returns the name of the shard bucket of the bucket.
+func shardBucketName(name []byte, shard int) []byte {
+	return append(append(append([]byte(nil), name...), '/'), strconv.Itoa(shard)...)
+}
+
+func (b *tableBucket) shardName(key []byte) []byte {
+	shard := uint64(util.BytesHash64(key)) % uint64(b.shards)
+	return shardBucketName(b.name, int(shard))
+}
+
+func (b *tableBucket) Get(key []byte) []byte {
+	key = b.key(key)
+	if b.shards == 0 {
+		return b.top.Get(key)
+	}
+	shard := b.tx.Bucket(b.shardName(key))
+	if shard == nil {
+		return nil
+	}
+	return shard.Get(key)
+}
+
+func (b *tableBucket) Put(key []byte, value []byte) error {
+	key = b.key(key)
+	if b.shards == 0 {
+		return b.top.Put(key, value)
+	}
+	shard, err := b.tx.CreateBucketIfNotExists(b.shardName(key))
+	if err != nil {
+		return err
+	}
+	return shard.Put(key, value)
+}
+
+func (b *tableBucket) Delete(key []byte) error {
+	key = b.key(key)
+	if b.shards == 0 {
+		return b.top.Delete(key)
+	}
+	shard := b.tx.Bucket(b.shardName(key))
+	if shard == nil {
+		return nil
+	}
+	return shard.Delete(key)
+}
+
+// cursorSteps is the most rows getSorted walks to the next key before it
+// seeks to it.
+const cursorSteps = 8
+
+// getSorted calls fn with the index and the value of every key, nil if it's
+// missing, in key order. The keys are looked up with the cursor, which walks
+// to the next key when it's close and seeks to it otherwise, so clustered
+// keys are read from the same pages.
+func getSorted(cursor rowCursor, keys [][]byte, fn func(i int, value []byte) error) error {
+	order := make([]int, len(keys))
+	for i := range order {
+		order[i] = i
+	}
+	sort.Slice(order, func(i, j int) bool {
+		return bytes.Compare(keys[order[i]], keys[order[j]]) < 0
+	})
+
+	var k, v []byte
+	for n, i := range order {
+		target := keys[i]
+		if n == 0 {
+			k, v = cursor.Seek(target)
+		}
+		for steps := 0; k != nil && bytes.Compare(k, target) < 0; steps++ {
+			if steps == cursorSteps {
+				k, v = cursor.Seek(target)
+				break
+			}
+			k, v = cursor.Next()
+		}
+
+		var value []byte
+		if k != nil && bytes.Equal(k, target) {
+			value = v
+		}
+		if err := fn(i, value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// rowCursor walks the rows of a table in key order.
+type rowCursor interface {
+	First() ([]byte, []byte)
+	Seek(key []byte) ([]byte, []byte)
+	Next() ([]byte, []byte)
+}
+
+// Cursor returns a cursor over the rows of the table, merging the shards in
+// key order.
+func (b *tableBucket) Cursor() rowCursor {
+	c := b.shardCursor()
+	if b.prefix == nil {
+		return c
+	}
+	return &prefixCursor{cursor: c, prefix: b.prefix}
+}
+
+func (b *tableBucket) shardCursor() rowCursor {
+	if b.shards == 0 {
+		return b.top.Cursor()
+	}
+	var cursors []rowCursor
+	for i := 0; i < b.shards; i++ {
+		if shard := b.tx.Bucket(shardBucketName(b.name, i)); shard != nil {
+			cursors = append(cursors, shard.Cursor())
+		}
+	}
+	return newMergeCursor(cursors)
+}
+
+// mergeCursor merges the cursors of the shards, keys and values are
+// the current rows of every cursor, a nil key once it's exhausted.
+type mergeCursor struct {
+	cursors []rowCursor
+	keys    [][]byte
+	values  [][]byte
+	// current is the cursor of the last returned row.
+	current int
+}
+
+func newMergeCursor(cursors []rowCursor) *mergeCursor {
+	return &mergeCursor{
+		cursors: cursors,
+		keys:    make([][]byte, len(cursors)),
+		values:  make([][]byte, len(cursors)),
+	}
+}
+
+func (m *mergeCursor) First() ([]byte, []byte) {
+	for i, c := range m.cursors {
+		m.keys[i], m.values[i] = c.First()
+	}
+	return m.next()
+}
+
+func (m *mergeCursor) Seek(key []byte) ([]byte, []byte) {
+	for i, c := range m.cursors {
+		m.keys[i], m.values[i] = c.Seek(key)
+	}
+	return m.next()
+}
+
+func (m *mergeCursor) Next() ([]byte, []byte) {
+	if m.current < 0 {
+		return nil, nil
+	}
+	m.keys[m.current], m.values[m.current] = m.cursors[m.current].Next()
+	return m.next()
+}
+
+// next returns the smallest of the current rows.
+func (m *mergeCursor) next() ([]byte, []byte) {
+	m.current = -1
+	for i, key := range m.keys {
+		if key != nil && (m.current < 0 || bytes.Compare(key, m.keys[m.current]) < 0) {
+			m.current = i
+		}
+	}
+	if m.current < 0 {
+		return nil, nil
+	}
+	return m.keys[m.current], m.values[m.current]
+}
+
+// prefixCursor walks the rows of a table in the single bucket, it strips the
+// prefix of the table from the keys and stops at the first row of another
+// table.
+type prefixCursor struct {
+	cursor rowCursor
+	prefix []byte
+}
+
+// First seeks to the prefix, the first row of the table is not the first
+// key of the bucket.
+func (p *prefixCursor) First() ([]byte, []byte) {
+	return p.Seek(nil)
+}
+
+func (p *prefixCursor) Seek(key []byte) ([]byte, []byte) {
+	return p.strip(p.cursor.Seek(prefixed(p.prefix, key)))
+}
+
+func (p *prefixCursor) Next() ([]byte, []byte) {
+	return p.strip(p.cursor.Next())
+}
+
+func (p *prefixCursor) strip(key []byte, value []byte) ([]byte, []byte) {
+	if !bytes.HasPrefix(key, p.prefix) {
+		return nil, nil
+	}
+	return key[len(p.prefix):], value
+}
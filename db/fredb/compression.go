@@ -0,0 +1,156 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/snappy"
+	"github.com/klauspost/compress/zstd"
+	"github.com/magiconair/properties"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+)
+
+// rowCompressor compresses the encoded rows before they are put and
+// decompresses them after they are read.
+type rowCompressor struct {
+	name string
+
+	compress   func(src []byte) []byte
+	decompress func(src []byte) ([]byte, error)
+	close      func()
+
+	// rawBytes and storedBytes are the sizes of the rows before and after
+	// the compression.
+	rawBytes    int64
+	storedBytes int64
+}
+
+func newRowCompressor(p *properties.Properties) (*rowCompressor, error) {
+	switch name := p.GetString(fredbCompression, "none"); name {
+	case "none":
+		return nil, nil
+	case "snappy":
+		return &rowCompressor{
+			name: name,
+			compress: func(src []byte) []byte {
+				return snappy.Encode(nil, src)
+			},
+			decompress: func(src []byte) ([]byte, error) {
+				return snappy.Decode(nil, src)
+			},
+			close: func() {},
+		}, nil
+	case "zstd":
+		// EncodeAll and DecodeAll can be called concurrently.
+		enc, err := zstd.NewWriter(nil)
+		if err != nil {
+			return nil, err
+		}
+		dec, err := zstd.NewReader(nil)
+		if err != nil {
+			return nil, err
+		}
+		return &rowCompressor{
+			name: name,
+			compress: func(src []byte) []byte {
+				return enc.EncodeAll(src, nil)
+			},
+			decompress: func(src []byte) ([]byte, error) {
+				return dec.DecodeAll(src, nil)
+			},
+			close: func() {
+				enc.Close()
+				dec.Close()
+			},
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %s, expected snappy, zstd or none", fredbCompression, name)
+	}
+}
+
+// encode encodes the row into buf and compresses it, the compression is
+// measured as COMPRESS. It returns buf, grown if the row didn't fit, to give
+// back to the pool, and the row to store, which is a new slice when it's
+// compressed.
+func (db *freDB) encode(buf []byte, values map[string][]byte) ([]byte, []byte, error) {
+	buf, err := db.r.Encode(buf, values)
+	if err != nil || db.compressor == nil {
+		return buf, buf, err
+	}
+	return buf, db.compressor.compressRow(buf), nil
+}
+
+// decode decompresses the row and decodes it, the decompression is measured
+// as DECOMPRESS.
+func (db *freDB) decode(row []byte, fields []string) (map[string][]byte, error) {
+	row, err := db.decompress(row)
+	if err != nil {
+		return nil, err
+	}
+	return db.r.Decode(row, fields)
+}
+
+// readRow decodes a row returned by a read, with read.staleness the time
+// since the row was written is measured as READ_STALENESS.
+func (db *freDB) readRow(row []byte, fields []string) (map[string][]byte, error) {
+	if !db.staleness {
+		return db.decode(row, fields)
+	}
+
+	row, err := db.decompress(row)
+	if err != nil {
+		return nil, err
+	}
+	m, meta, ok, err := db.r.DecodeWithMeta(row, fields)
+	if ok {
+		now := time.Now()
+		measurement.Measure("READ_STALENESS", now, now.Sub(meta.Timestamp))
+	}
+	return m, err
+}
+
+func (db *freDB) decompress(row []byte) ([]byte, error) {
+	if db.compressor == nil {
+		return row, nil
+	}
+	start := time.Now()
+	row, err := db.compressor.decompress(row)
+	if err != nil {
+		return nil, fmt.Errorf("decompress the row: %v", err)
+	}
+	measurement.Measure("DECOMPRESS", start, time.Now().Sub(start))
+	return row, nil
+}
+
+func (c *rowCompressor) compressRow(row []byte) []byte {
+	start := time.Now()
+	stored := c.compress(row)
+	measurement.Measure("COMPRESS", start, time.Now().Sub(start))
+	atomic.AddInt64(&c.rawBytes, int64(len(row)))
+	atomic.AddInt64(&c.storedBytes, int64(len(stored)))
+	return stored
+}
+
+func (c *rowCompressor) report() {
+	raw, stored := atomic.LoadInt64(&c.rawBytes), atomic.LoadInt64(&c.storedBytes)
+	if stored == 0 {
+		return
+	}
+	fmt.Printf("fredb: %s compressed %d bytes of rows to %d bytes, ratio %.2f\n",
+		c.name, raw, stored, float64(raw)/float64(stored))
+}
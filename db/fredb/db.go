@@ -30,12 +30,20 @@ package fredb
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
+	"path/filepath"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	"github.com/alexhholmes/fredb"
 	"github.com/magiconair/properties"
 
+	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
 	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
@@ -44,58 +52,510 @@ import (
 // properties
 const (
 	fredbPath = "fredb.path"
+	// fredbSpaceMargin is the factor applied to the estimated size of the
+	// loaded data set for the free disk space required before the load, 0
+	// disables the check.
+	fredbSpaceMargin = "fredb.space.margin"
+	// fredbLayout is how the rows are stored, `packed` encodes a row under
+	// its key, `field` puts every field under its own key so an update
+	// only writes the changed fields.
+	fredbLayout = "fredb.layout"
+	// fredbSync is the durability of the commits, `full` syncs every commit
+	// and `off` never syncs, empty keeps the library default.
+	fredbSync = "fredb.sync"
+	// fredbCacheSizeMB is the size of the in-memory page cache, 0 keeps the
+	// library default.
+	fredbCacheSizeMB = "fredb.cache_size_mb"
+	// fredbOpenTimeout is how long opening the database waits for the lock
+	// file held by another process, 0 waits forever.
+	fredbOpenTimeout = "fredb.open_timeout"
+	// fredbUniquePath appends the start time and a random suffix to the
+	// path, so the benchmarks running at once on a host get their own files.
+	fredbUniquePath = "fredb.unique_path"
+	// fredbCompression compresses the encoded rows with snappy or zstd,
+	// none stores them as they are.
+	fredbCompression = "fredb.compression"
+	// fredbMaxBatchSize is the most operations of a batch written in one
+	// transaction, larger batches are split, 0 writes a batch at once.
+	fredbMaxBatchSize = "fredb.max_batch_size"
+	// fredbBatchSingleTx writes the operations of a batch in one
+	// transaction, false writes every one in its own.
+	fredbBatchSingleTx = "fredb.batch_single_tx"
+	// fredbHandlePerThread spreads the rows over a database file per client
+	// thread by the hash of their key, the shared one and the shard files
+	// next to it.
+	fredbHandlePerThread = "fredb.handle_per_thread"
+	// fredbReopenAfterLoad closes and opens the database again once the
+	// load is done, so a run in the same process starts like a new one.
+	fredbReopenAfterLoad = "fredb.reopen_after_load"
+	// fredbDropCaches evicts the database from the page cache before the
+	// run phase opens it, `file` the database file only, `all` every clean
+	// page of the host, empty keeps the cache.
+	fredbDropCaches = "fredb.drop_caches"
+	// fredbNestedBuckets spreads the rows of a table over
+	// fredbBucketShards shard buckets by the hash of their key.
+	fredbNestedBuckets = "fredb.nested_buckets"
+	fredbBucketShards  = "fredb.bucket_shards"
+	// fredbSingleBucket stores the rows of every table in one bucket under
+	// the table name, a 0 byte and their key.
+	fredbSingleBucket = "fredb.single_bucket"
+	// fredbKeyEncoding rewrites the keys before they are stored, `hash`
+	// puts the hash of the key before it and `reverse` reverses its bytes,
+	// `raw` stores them as they are.
+	fredbKeyEncoding = "fredb.key_encoding"
+	// fredbGroupCommitInterval commits the insert, update and delete
+	// transactions started by the threads within the interval together, 0
+	// commits every one on its own.
+	fredbGroupCommitInterval = "fredb.group_commit_interval"
 )
 
+const (
+	fredbPathDefault         = "/tmp/fredb"
+	fredbLayoutDefault       = "packed"
+	fredbOpenTimeoutDefault  = 30 * time.Second
+	fredbBucketShardsDefault = 16
+)
+
+// fredbOpenRetry is the wait before locking the database again.
+const fredbOpenRetry = 100 * time.Millisecond
+
 type fredbcreator struct {
 }
 
 type fredbOptions struct {
 	Path      string
-	DBOptions fredb.Option
+	DBOptions []fredb.Option
 }
 
 type freDB struct {
-	p *properties.Properties
+	p    *properties.Properties
+	path string
+
+	// scanMissingStart is the prop.ScanMissingStartKey semantics.
+	scanMissingStart string
+
+	layout      string
+	fieldPerKey bool
+	// bucketShards is the number of shard buckets of a table, 0 if the
+	// rows are in the table bucket.
+	bucketShards int
+	// singleBucket is whether the tables share one bucket.
+	singleBucket bool
+
+	// updates and updateBytes are the updates and the bytes they wrote, to
+	// compare the layouts.
+	updates     int64
+	updateBytes int64
+	// txns are the write transactions and txnNanos their total duration.
+	txns     int64
+	txnNanos int64
 
 	db *fredb.DB
 
+	maxBatchSize  int
+	batchSingleTx bool
+	// lock is the lock file of the database, held until it's closed.
+	lock *os.File
+	// options open the database again after the load.
+	options         []fredb.Option
+	reopenAfterLoad bool
+	dropCaches      string
+	// compressor compresses the rows, nil if they aren't compressed.
+	compressor *rowCompressor
+	// keys rewrites the keys, nil if they are stored as they are.
+	keys *keyEncoder
+	// group commits the writes of the threads together, nil if every one is
+	// committed on its own.
+	group *groupCommit
+	// shards are the databases the rows are spread over with
+	// fredb.handle_per_thread, the first one is db, nil if it holds them all.
+	shards []*fredb.DB
+
+	// staleness measures the age of the rows read, read.staleness.
+	staleness bool
+
 	r       *util.RowCodec
 	bufPool *util.BufPool
 }
 
 func (c fredbcreator) Create(p *properties.Properties) (ycsb.DB, error) {
-	opts := getOptions(p)
+	// the properties are all checked before dropdata removes the data and
+	// the database is opened, so a mistake doesn't cost the data set or
+	// leave the file locked.
+	opts, err := getOptions(p)
+	if err != nil {
+		return nil, err
+	}
+
+	scanMissingStart := p.GetString(prop.ScanMissingStartKey, prop.ScanMissingStartKeyDefault)
+	switch scanMissingStart {
+	case "seek", "empty", "error":
+	default:
+		return nil, fmt.Errorf("unknown %s %s", prop.ScanMissingStartKey, scanMissingStart)
+	}
+
+	layout := p.GetString(fredbLayout, fredbLayoutDefault)
+	switch layout {
+	case "packed", "field":
+	default:
+		return nil, fmt.Errorf("unknown %s %s", fredbLayout, layout)
+	}
+	if layout == "field" && p.GetString(fredbCompression, "none") != "none" {
+		return nil, fmt.Errorf("%s only compresses the rows of the packed layout", fredbCompression)
+	}
+
+	staleness := p.GetBool(prop.ReadStaleness, prop.ReadStalenessDefault)
+	if staleness && !p.GetBool(prop.RowMetadata, prop.RowMetadataDefault) {
+		return nil, fmt.Errorf("%s needs %s, the rows have no write timestamp", prop.ReadStaleness, prop.RowMetadata)
+	}
+	if staleness && layout == "field" {
+		return nil, fmt.Errorf("%s only measures the rows of the packed layout", prop.ReadStaleness)
+	}
+
+	keys, err := newKeyEncoder(p)
+	if err != nil {
+		return nil, err
+	}
+	if keys != nil && p.GetBool(prop.Oracle, prop.OracleDefault) {
+		// the oracle expects the scanned keys in ascending order.
+		return nil, fmt.Errorf("%s checks the scans in key order, but %s %s scans in the stored order", prop.Oracle, fredbKeyEncoding, keys.name)
+	}
+
+	singleBucket := p.GetBool(fredbSingleBucket, false)
+	if singleBucket && layout == "field" {
+		return nil, fmt.Errorf("%s only prefixes the rows of the packed layout", fredbSingleBucket)
+	}
+
+	var bucketShards int
+	if p.GetBool(fredbNestedBuckets, false) {
+		if layout == "field" {
+			return nil, fmt.Errorf("%s only shards the rows of the packed layout", fredbNestedBuckets)
+		}
+		if bucketShards = p.GetInt(fredbBucketShards, fredbBucketShardsDefault); bucketShards <= 0 {
+			return nil, fmt.Errorf("%s %d must be positive", fredbBucketShards, bucketShards)
+		}
+	}
+
+	maxBatchSize := p.GetInt(fredbMaxBatchSize, 0)
+	if maxBatchSize < 0 {
+		return nil, fmt.Errorf("%s %d is negative", fredbMaxBatchSize, maxBatchSize)
+	}
+
+	handlePerThread := p.GetBool(fredbHandlePerThread, false)
+	groupInterval := p.GetParsedDuration(fredbGroupCommitInterval, 0)
+	if groupInterval > 0 && handlePerThread {
+		return nil, fmt.Errorf("%s can't be combined with %s", fredbGroupCommitInterval, fredbHandlePerThread)
+	}
+	var shardCount int
+	if handlePerThread {
+		if shardCount = p.GetInt(prop.ThreadCount, int(prop.ThreadCountDefault)); shardCount <= 0 {
+			return nil, fmt.Errorf("%s %d must be positive", prop.ThreadCount, shardCount)
+		}
+	}
+
+	drop := p.GetString(fredbDropCaches, "")
+	switch drop {
+	case "", "file", "all":
+	default:
+		return nil, fmt.Errorf("unknown %s %s, expected file or all", fredbDropCaches, drop)
+	}
+
+	compressor, err := newRowCompressor(p)
+	if err != nil {
+		return nil, err
+	}
+	// fail releases the coders of the compressor and the lock when the
+	// database can't be opened.
+	var lock *os.File
+	fail := func(err error) (ycsb.DB, error) {
+		if compressor != nil {
+			compressor.close()
+		}
+		if lock != nil {
+			lock.Close()
+		}
+		return nil, err
+	}
+
+	if p.GetBool(fredbUniquePath, false) {
+		// the later users of the path, like the pool, find the files there.
+		p.Set(fredbPath, opts.Path)
+		fmt.Printf("fredb: the database is in %s\n", opts.Path)
+	}
+
+	// the lock is taken before dropdata, the files may belong to another
+	// process.
+	if lock, err = lockDB(opts.Path, p.GetParsedDuration(fredbOpenTimeout, fredbOpenTimeoutDefault)); err != nil {
+		return fail(err)
+	}
 
 	if p.GetBool(prop.DropData, prop.DropDataDefault) {
 		os.RemoveAll(opts.Path)
+		removeShardFiles(opts.Path)
 	}
 
-	db, err := fredb.Open(opts.Path, opts.DBOptions)
+	if !p.GetBool(prop.DoTransactions, true) {
+		if err := checkFreeSpace(p, opts.Path); err != nil {
+			return fail(err)
+		}
+	}
+
+	if _, err := os.Stat(opts.Path); err == nil && drop != "" && p.GetBool(prop.DoTransactions, true) {
+		for i := 0; i < max(shardCount, 1); i++ {
+			if err := dropCaches(shardPath(opts.Path, i), drop == "all"); err != nil {
+				return fail(err)
+			}
+		}
+		fmt.Printf("fredb: dropped the %s caches before the run\n", drop)
+	}
+
+	db, err := fredb.Open(opts.Path, opts.DBOptions...)
 	if err != nil {
-		return nil, err
+		return fail(err)
 	}
 
-	return &freDB{
-		p:       p,
-		db:      db,
-		r:       util.NewRowCodec(p),
-		bufPool: util.NewBufPool(),
-	}, nil
+	fmt.Printf("fredb: scans from a missing start key use the '%s' semantics\n", scanMissingStart)
+	if keys != nil {
+		fmt.Printf("fredb: the keys are stored with the '%s' encoding, scans follow its order\n", keys.name)
+	}
+
+	fdb := &freDB{
+		p:                p,
+		path:             opts.Path,
+		scanMissingStart: scanMissingStart,
+		layout:           layout,
+		fieldPerKey:      layout == "field",
+		bucketShards:     bucketShards,
+		singleBucket:     singleBucket,
+		db:               db,
+		maxBatchSize:     maxBatchSize,
+		batchSingleTx:    p.GetBool(fredbBatchSingleTx, true),
+		lock:             lock,
+		options:          opts.DBOptions,
+		reopenAfterLoad:  p.GetBool(fredbReopenAfterLoad, false),
+		dropCaches:       drop,
+		compressor:       compressor,
+		keys:             keys,
+		staleness:        staleness,
+		r:                util.NewRowCodec(p),
+		bufPool:          util.NewBufPoolFromProps(p),
+	}
+	if shardCount > 0 {
+		if fdb.shards, err = fdb.openShards(); err != nil {
+			db.Close()
+			return fail(err)
+		}
+		fmt.Printf("fredb: the rows are spread by key over %d files, %s and %s.shard<i>\n", shardCount, opts.Path, opts.Path)
+	}
+	if groupInterval > 0 {
+		fdb.group = &groupCommit{db: fdb, interval: groupInterval}
+		fmt.Printf("fredb: the writes started within %s are committed together\n", groupInterval)
+	}
+	return fdb, nil
 }
 
-func getOptions(p *properties.Properties) fredbOptions {
-	path := p.GetString(fredbPath, "/tmp/fredb")
+// Analyze implements the AnalyzeDB Analyze interface, it's called once the
+// load is done. With fredb.reopen_after_load the database is closed and
+// opened again, after dropping the caches with fredb.drop_caches, so a run in
+// the same process doesn't inherit the warm state of the load.
+func (db *freDB) Analyze(_ context.Context, _ string) error {
+	if !db.reopenAfterLoad {
+		return nil
+	}
 
-	opts := fredb.DefaultOptions()
+	start := time.Now()
+	// the client doesn't check the error of Analyze.
+	if err := closeShards(db.handles()); err != nil {
+		util.Fatalf("fredb: close %s after the load failed %v", db.path, err)
+	}
+	if db.dropCaches != "" {
+		for i := range db.handles() {
+			if err := dropCaches(shardPath(db.path, i), db.dropCaches == "all"); err != nil {
+				fmt.Printf("fredb: %v\n", err)
+			}
+		}
+	}
+	handle, err := fredb.Open(db.path, db.options...)
+	if err != nil {
+		util.Fatalf("fredb: open %s again after the load failed %v", db.path, err)
+	}
+	db.db = handle
+	if db.shards != nil {
+		if db.shards, err = db.openShards(); err != nil {
+			util.Fatalf("fredb: open the shards of %s again after the load failed %v", db.path, err)
+		}
+	}
+	fmt.Printf("fredb: reopened %s after the load in %s\n", db.path, time.Now().Sub(start).Round(time.Millisecond))
+	return nil
+}
+
+func getOptions(p *properties.Properties) (fredbOptions, error) {
+	path := p.GetString(fredbPath, fredbPathDefault)
+	if p.GetBool(fredbUniquePath, false) {
+		var err error
+		if path, err = uniquePath(path); err != nil {
+			return fredbOptions{}, err
+		}
+	}
+
+	opts := []fredb.Option{fredb.DefaultOptions()}
+
+	switch sync := p.GetString(fredbSync, ""); sync {
+	case "":
+	case "full":
+		opts = append(opts, fredb.WithSyncEveryCommit())
+	case "off":
+		opts = append(opts, fredb.WithSyncOff())
+	case "normal":
+		return fredbOptions{}, fmt.Errorf("%s normal is not supported, fredb syncs every commit or never, use full or off", fredbSync)
+	default:
+		return fredbOptions{}, fmt.Errorf("unknown %s %s, expected full or off", fredbSync, sync)
+	}
+
+	cacheSizeMB := p.GetInt(fredbCacheSizeMB, 0)
+	if cacheSizeMB < 0 {
+		return fredbOptions{}, fmt.Errorf("%s %d is negative", fredbCacheSizeMB, cacheSizeMB)
+	}
+	if cacheSizeMB > 0 {
+		opts = append(opts, fredb.WithCacheSizeMB(cacheSizeMB))
+	}
+
+	passthrough, err := passthroughOptions(p)
+	if err != nil {
+		return fredbOptions{}, err
+	}
+	opts = append(opts, passthrough...)
 
 	return fredbOptions{
 		Path:      path,
 		DBOptions: opts,
+	}, nil
+}
+
+// uniquePath appends the current time and a random suffix to the path.
+func uniquePath(path string) (string, error) {
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return "", fmt.Errorf("%s: %v", fredbUniquePath, err)
 	}
+	return fmt.Sprintf("%s-%s-%s", path, time.Now().Format("20060102-150405"), hex.EncodeToString(suffix)), nil
+}
+
+// lockPath returns the file locked by the process using the database.
+func lockPath(path string) string {
+	return path + ".lock"
+}
+
+// lockDB takes the lock of the database, retrying while another process
+// holds it until the timeout expires instead of hanging on it. fredb doesn't
+// lock its file, so without it two processes on one path would both open
+// and corrupt it.
+func lockDB(path string, timeout time.Duration) (*os.File, error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := lockFile(lockPath(path))
+		if !errors.Is(err, syscall.EWOULDBLOCK) {
+			return f, err
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return nil, fmt.Errorf("lock %s timed out after %s (%s), another process uses the database", lockPath(path), timeout, fredbOpenTimeout)
+		}
+		time.Sleep(fredbOpenRetry)
+	}
+}
+
+// checkFreeSpace fails if the file system of the path doesn't have room for
+// the data set of the load, rather than letting the load die hours in on a
+// write error.
+func checkFreeSpace(p *properties.Properties, path string) error {
+	margin := p.GetFloat64(fredbSpaceMargin, 2)
+	if margin <= 0 {
+		return nil
+	}
+
+	free, err := util.FreeDiskSpace(filepath.Dir(path))
+	if err != nil {
+		fmt.Printf("fredb: skip the free disk space check, %v\n", err)
+		return nil
+	}
+	required := uint64(float64(util.EstimateLoadSize(p)) * margin)
+	fmt.Printf("fredb: the load requires %d MB of free disk space, %d MB are free in %s\n",
+		required>>20, free>>20, filepath.Dir(path))
+	if free < required {
+		return fmt.Errorf("not enough free disk space in %s for the load: %d MB free, %d MB required (%s=%g times the estimated data set), free up space, reduce %s or lower %s",
+			filepath.Dir(path), free>>20, required>>20, fredbSpaceMargin, margin, prop.RecordCount, fredbSpaceMargin)
+	}
+	return nil
 }
 
 func (db *freDB) Close() error {
-	return db.db.Close()
+	if updates := atomic.LoadInt64(&db.updates); updates > 0 {
+		updateBytes := atomic.LoadInt64(&db.updateBytes)
+		fmt.Printf("fredb: %d updates wrote %d bytes, %d bytes per update with the %s layout\n",
+			updates, updateBytes, updateBytes/updates, db.layout)
+	}
+	stats := db.bufPool.Stats()
+	fmt.Printf("fredb: buffer pool gets %d, hits %d, misses %d, dropped %d, allocated %d bytes\n",
+		stats.Gets, stats.Hits, stats.Misses, stats.Dropped, stats.Allocated)
+	if db.compressor != nil {
+		db.compressor.report()
+		db.compressor.close()
+	}
+	if db.group != nil {
+		db.group.report()
+	}
+	err := closeShards(db.handles())
+	db.lock.Close()
+	return err
+}
+
+// openShards opens the shard files next to db with fredb.handle_per_thread.
+func (db *freDB) openShards() ([]*fredb.DB, error) {
+	n := db.p.GetInt(prop.ThreadCount, int(prop.ThreadCountDefault))
+	return openShards(db.db, db.path, n, func(path string) (*fredb.DB, error) {
+		return fredb.Open(path, db.options...)
+	})
+}
+
+// updateTx runs fn in a write transaction of the handle and measures it as
+// WRITE_TXN, from its start to its commit.
+func (db *freDB) updateTx(handle *fredb.DB, fn func(tx *fredb.Tx) error) error {
+	start := time.Now()
+	err := handle.Update(fn)
+	latency := time.Now().Sub(start)
+	measurement.Measure("WRITE_TXN", start, latency)
+	atomic.AddInt64(&db.txns, 1)
+	atomic.AddInt64(&db.txnNanos, int64(latency))
+	return err
+}
+
+func (db *freDB) countUpdate(written int64) {
+	atomic.AddInt64(&db.updates, 1)
+	atomic.AddInt64(&db.updateBytes, written)
+}
+
+// inBatches calls fn with the parts of a batch of n operations, each one is
+// written in its own transaction. Without fredb.max_batch_size the batch is
+// a single part, otherwise every part is measured as BATCH_TXN. Without
+// fredb.batch_single_tx every operation is a part.
+func (db *freDB) inBatches(n int, fn func(i, j int) error) error {
+	size := db.maxBatchSize
+	if !db.batchSingleTx {
+		size = 1
+	}
+	if size <= 0 {
+		return fn(0, n)
+	}
+
+	for i := 0; i < n; i += size {
+		start := time.Now()
+		err := fn(i, min(i+size, n))
+		measurement.Measure("BATCH_TXN", start, time.Now().Sub(start))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 func (db *freDB) InitThread(ctx context.Context, _ int, _ int) context.Context {
@@ -105,91 +565,158 @@ func (db *freDB) InitThread(ctx context.Context, _ int, _ int) context.Context {
 func (db *freDB) CleanupThread(_ context.Context) {
 }
 
-func (db *freDB) Read(_ context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+func (db *freDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
 	var m map[string][]byte
-	err := db.db.View(func(tx *fredb.Tx) error {
-		bucket := tx.Bucket([]byte(table))
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.handle(key).View(func(tx *fredb.Tx) error {
+		bucket := db.table(tx, table)
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
 		}
 
-		row := bucket.Get([]byte(key))
+		if db.fieldPerKey {
+			if m = readFields(bucket.top, db.storedKey(key), fields); m == nil {
+				return fmt.Errorf("key not found: %s.%s", table, key)
+			}
+			return nil
+		}
+
+		row := bucket.Get([]byte(db.storedKey(key)))
 		if row == nil {
 			return fmt.Errorf("key not found: %s.%s", table, key)
 		}
 
 		var err error
-		m, err = db.r.Decode(row, fields)
+		decodeStart := time.Now()
+		m, err = db.readRow(row, fields)
+		tr.Timing("decode", decodeStart)
 		return err
 	})
+	tr.Timing("engine", start)
 	return m, err
 }
 
-func (db *freDB) BatchRead(_ context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
-	var m []map[string][]byte
-	err := db.db.View(func(tx *fredb.Tx) error {
-		bucket := tx.Bucket([]byte(table))
-		if bucket == nil {
-			return fmt.Errorf("table not found: %s", table)
-		}
-
-		for _, key := range keys {
-			row := bucket.Get([]byte(key))
-			if row == nil {
-				return fmt.Errorf("key not found: %s.%s", table, key)
+func (db *freDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	m := make([]map[string][]byte, len(keys))
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.byShard(keys, func(handle *fredb.DB, idx []int) error {
+		return handle.View(func(tx *fredb.Tx) error {
+			bucket := db.table(tx, table)
+			if bucket == nil {
+				return fmt.Errorf("table not found: %s", table)
 			}
 
-			e, err := db.r.Decode(row, fields)
-			if err != nil {
-				return err
+			if db.fieldPerKey {
+				for _, i := range idx {
+					e := readFields(bucket.top, db.storedKey(keys[i]), fields)
+					if e == nil {
+						return fmt.Errorf("key not found: %s.%s", table, keys[i])
+					}
+					m[i] = e
+				}
+				return nil
 			}
 
-			m = append(m, e)
-		}
+			stored := make([][]byte, len(idx))
+			for n, i := range idx {
+				stored[n] = []byte(db.storedKey(keys[i]))
+			}
+			return getSorted(bucket.Cursor(), stored, func(n int, row []byte) error {
+				i := idx[n]
+				if row == nil {
+					return fmt.Errorf("key not found: %s.%s", table, keys[i])
+				}
 
-		return nil
+				decodeStart := time.Now()
+				e, err := db.readRow(row, fields)
+				tr.Timing("decode", decodeStart)
+				m[i] = e
+				return err
+			})
+		})
 	})
+	tr.Timing("engine", start)
 	return m, err
 }
 
-func (db *freDB) Scan(_ context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
-	res := make([]map[string][]byte, count)
-	err := db.db.View(func(tx *fredb.Tx) error {
-		bucket := tx.Bucket([]byte(table))
-		if bucket == nil {
-			return fmt.Errorf("table not found: %s", table)
+func (db *freDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	_, res, err := db.ScanKeys(ctx, table, startKey, count, fields)
+	return res, err
+}
+
+func (db *freDB) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	keys := make([]string, 0, count)
+	res := make([]map[string][]byte, 0, count)
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.viewTable(table, func(cursor rowCursor) error {
+		if db.fieldPerKey {
+			var err error
+			keys, res, err = db.scanFields(cursor, table, startKey, count, fields)
+			return err
 		}
 
-		cursor := bucket.Cursor()
-		key, value := cursor.Seek([]byte(startKey))
+		storedStart := db.storedKey(startKey)
+		key, value := cursor.Seek([]byte(storedStart))
+		if string(key) != storedStart {
+			switch db.scanMissingStart {
+			case "empty":
+				return nil
+			case "error":
+				return fmt.Errorf("key not found: %s.%s", table, startKey)
+			}
+		}
 		for i := 0; key != nil && i < count; i++ {
-			m, err := db.r.Decode(value, fields)
+			decodeStart := time.Now()
+			m, err := db.readRow(value, fields)
+			tr.Timing("decode", decodeStart)
 			if err != nil {
 				return err
 			}
 
-			res[i] = m
+			keys = append(keys, db.rowKey(string(key)))
+			res = append(res, m)
 			key, value = cursor.Next()
 		}
 
 		return nil
 	})
-	return res, err
+	tr.Timing("engine", start)
+	return keys, res, err
 }
 
-func (db *freDB) Update(_ context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(tx *fredb.Tx) error {
-		bucket := tx.Bucket([]byte(table))
+func (db *freDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	// written is counted once committed, a group commit may run the
+	// transaction again.
+	var written int64
+	err := db.update(key, func(tx *fredb.Tx) error {
+		bucket := db.table(tx, table)
 		if bucket == nil {
 			return fmt.Errorf("table not found: %s", table)
 		}
 
-		value := bucket.Get([]byte(key))
+		if db.fieldPerKey {
+			// only the changed fields are written.
+			if !hasRow(bucket.top, db.storedKey(key)) {
+				return fmt.Errorf("key not found: %s.%s", table, key)
+			}
+			var err error
+			written, err = putFields(bucket.top, db.storedKey(key), values)
+			return err
+		}
+
+		value := bucket.Get([]byte(db.storedKey(key)))
 		if value == nil {
 			return fmt.Errorf("key not found: %s.%s", table, key)
 		}
 
-		data, err := db.r.Decode(value, nil)
+		decodeStart := time.Now()
+		data, err := db.decode(value, nil)
+		tr.Timing("decode", decodeStart)
 		if err != nil {
 			return err
 		}
@@ -203,104 +730,257 @@ func (db *freDB) Update(_ context.Context, table string, key string, values map[
 			db.bufPool.Put(buf)
 		}()
 
-		buf, err = db.r.Encode(buf, data)
+		encodeStart := time.Now()
+		var row []byte
+		buf, row, err = db.encode(buf, data)
+		tr.Timing("encode", encodeStart)
 		if err != nil {
 			return err
 		}
 
-		return bucket.Put([]byte(key), buf)
+		written = int64(len(key) + len(row))
+		return bucket.Put([]byte(db.storedKey(key)), row)
 	})
+	if err == nil {
+		db.countUpdate(written)
+	}
+	tr.Timing("engine", start)
 	return err
 }
 
-func (db *freDB) BatchUpdate(_ context.Context, table string, keys []string, values []map[string][]byte) error {
-	err := db.db.Update(func(tx *fredb.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
-		if err != nil {
-			return err
-		}
-
-		buf := db.bufPool.Get()
-		defer func() {
-			db.bufPool.Put(buf)
-		}()
+func (db *freDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	return db.inBatches(len(keys), func(i, j int) error {
+		return db.batchUpdate(ctx, table, keys[i:j], values[i:j])
+	})
+}
 
-		for i, key := range keys {
-			buf, err = db.r.Encode(buf, values[i])
+func (db *freDB) batchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.byShard(keys, func(handle *fredb.DB, idx []int) error {
+		return db.updateTx(handle, func(tx *fredb.Tx) error {
+			bucket, err := db.createTable(tx, table)
 			if err != nil {
 				return err
 			}
 
-			err = bucket.Put([]byte(key), buf)
-			if err != nil {
-				return err
+			if db.fieldPerKey {
+				for _, i := range idx {
+					written, err := putFields(bucket.top, db.storedKey(keys[i]), values[i])
+					db.countUpdate(written)
+					if err != nil {
+						return err
+					}
+				}
+				return nil
 			}
-		}
 
-		return nil
+			buf := db.bufPool.Get()
+			defer func() {
+				db.bufPool.Put(buf)
+			}()
+
+			for _, i := range idx {
+				encodeStart := time.Now()
+				var row []byte
+				buf, row, err = db.encode(buf, values[i])
+				tr.Timing("encode", encodeStart)
+				if err != nil {
+					return err
+				}
+
+				db.countUpdate(int64(len(keys[i]) + len(row)))
+				err = bucket.Put([]byte(db.storedKey(keys[i])), row)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
 	})
+	tr.Timing("engine", start)
 	return err
 }
 
-func (db *freDB) Insert(_ context.Context, table string, key string, values map[string][]byte) error {
-	err := db.db.Update(func(tx *fredb.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
+func (db *freDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.update(key, func(tx *fredb.Tx) error {
+		bucket, err := db.createTable(tx, table)
 		if err != nil {
 			return err
 		}
 
+		if db.fieldPerKey {
+			_, err := putFields(bucket.top, db.storedKey(key), values)
+			return err
+		}
+
 		buf := db.bufPool.Get()
 		defer func() {
 			db.bufPool.Put(buf)
 		}()
 
-		buf, err = db.r.Encode(buf, values)
+		encodeStart := time.Now()
+		var row []byte
+		buf, row, err = db.encode(buf, values)
+		tr.Timing("encode", encodeStart)
 		if err != nil {
 			return err
 		}
 
-		return bucket.Put([]byte(key), buf)
+		return bucket.Put([]byte(db.storedKey(key)), row)
 	})
+	tr.Timing("engine", start)
 	return err
 }
 
-func (db *freDB) BatchInsert(_ context.Context, table string, keys []string, values []map[string][]byte) error {
-	err := db.db.Update(func(tx *fredb.Tx) error {
-		bucket, err := tx.CreateBucketIfNotExists([]byte(table))
-		if err != nil {
-			return err
-		}
+func (db *freDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	return db.inBatches(len(keys), func(i, j int) error {
+		return db.batchInsert(ctx, table, keys[i:j], values[i:j])
+	})
+}
 
-		buf := db.bufPool.Get()
-		defer func() {
-			db.bufPool.Put(buf)
-		}()
+func (db *freDB) batchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	tr := measurement.TraceFrom(ctx)
+	start := time.Now()
+	err := db.byShard(keys, func(handle *fredb.DB, idx []int) error {
+		return db.updateTx(handle, func(tx *fredb.Tx) error {
+			bucket, err := db.createTable(tx, table)
+			if err != nil {
+				return err
+			}
+
+			if db.fieldPerKey {
+				for _, i := range idx {
+					if _, err := putFields(bucket.top, db.storedKey(keys[i]), values[i]); err != nil {
+						return err
+					}
+				}
+				return nil
+			}
+
+			buf := db.bufPool.Get()
+			defer func() {
+				db.bufPool.Put(buf)
+			}()
 
-		for i, key := range keys {
-			buf, err = db.r.Encode(buf, values[i])
+			for _, i := range idx {
+				encodeStart := time.Now()
+				var row []byte
+				buf, row, err = db.encode(buf, values[i])
+				tr.Timing("encode", encodeStart)
+				if err != nil {
+					return err
+				}
+
+				err = bucket.Put([]byte(db.storedKey(keys[i])), row)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+	tr.Timing("engine", start)
+	return err
+}
+
+func (db *freDB) IngestRows(ctx context.Context, table string, keys []string, rows [][]byte) error {
+	return db.inBatches(len(keys), func(i, j int) error {
+		return db.ingestRows(ctx, table, keys[i:j], rows[i:j])
+	})
+}
+
+func (db *freDB) ingestRows(ctx context.Context, table string, keys []string, rows [][]byte) error {
+	return db.byShard(keys, func(handle *fredb.DB, idx []int) error {
+		return db.updateTx(handle, func(tx *fredb.Tx) error {
+			bucket, err := db.createTable(tx, table)
 			if err != nil {
 				return err
 			}
 
-			err = bucket.Put([]byte(key), buf)
+			for _, i := range idx {
+				if db.fieldPerKey {
+					values, err := db.r.Decode(rows[i], nil)
+					if err != nil {
+						return err
+					}
+					if _, err := putFields(bucket.top, db.storedKey(keys[i]), values); err != nil {
+						return err
+					}
+					continue
+				}
+
+				row := rows[i]
+				if db.compressor != nil {
+					row = db.compressor.compressRow(row)
+				}
+				err = bucket.Put([]byte(db.storedKey(keys[i])), row)
+				if err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+func (db *freDB) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	if db.keys != nil {
+		next := fn
+		fn = func(key string, values map[string][]byte) error {
+			return next(db.keys.decode(key), values)
+		}
+	}
+
+	return db.viewTable(table, func(cursor rowCursor) error {
+		if db.fieldPerKey {
+			return iterateFields(cursor, fn)
+		}
+
+		for key, value := cursor.First(); key != nil; key, value = cursor.Next() {
+			m, err := db.decode(value, nil)
 			if err != nil {
 				return err
 			}
+
+			if err := fn(string(key), m); err != nil {
+				return err
+			}
 		}
 
 		return nil
 	})
-	return err
 }
 
-func (db *freDB) Delete(_ context.Context, table string, key string) error {
-	err := db.db.Update(func(tx *fredb.Tx) error {
-		bucket := tx.Bucket([]byte(table))
+func (db *freDB) DiskSize(_ context.Context) (int64, error) {
+	var size int64
+	for i := range db.handles() {
+		fi, err := os.Stat(shardPath(db.path, i))
+		if err != nil {
+			return 0, err
+		}
+		size += fi.Size()
+	}
+	return size, nil
+}
+
+func (db *freDB) Delete(ctx context.Context, table string, key string) error {
+	err := db.update(key, func(tx *fredb.Tx) error {
+		bucket := db.table(tx, table)
 		if bucket == nil {
 			return nil
 		}
 
-		err := bucket.Delete([]byte(key))
+		if db.fieldPerKey {
+			return deleteFields(bucket.top, db.storedKey(key))
+		}
+
+		err := bucket.Delete([]byte(db.storedKey(key)))
 		if err != nil {
 			return err
 		}
@@ -310,6 +990,68 @@ func (db *freDB) Delete(_ context.Context, table string, key string) error {
 	return err
 }
 
+func (db *freDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	return db.inBatches(len(keys), func(i, j int) error {
+		return db.batchDelete(ctx, table, keys[i:j])
+	})
+}
+
+func (db *freDB) batchDelete(ctx context.Context, table string, keys []string) error {
+	return db.byShard(keys, func(handle *fredb.DB, idx []int) error {
+		return db.updateTx(handle, func(tx *fredb.Tx) error {
+			bucket := db.table(tx, table)
+			if bucket == nil {
+				return nil
+			}
+
+			for _, i := range idx {
+				if db.fieldPerKey {
+					if err := deleteFields(bucket.top, db.storedKey(keys[i])); err != nil {
+						return err
+					}
+					continue
+				}
+
+				if err := bucket.Delete([]byte(db.storedKey(keys[i]))); err != nil {
+					return err
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+var _ ycsb.BatchDB = (*freDB)(nil)
+
+// fredbProperties are the properties of the binding, for the describe command.
+var fredbProperties = []ycsb.Property{
+	{Name: fredbPath, Default: fredbPathDefault, Description: "The database file path"},
+	{Name: fredbLayout, Default: fredbLayoutDefault, Description: "How the rows are stored: `packed` under their key or `field` with a key per field"},
+	{Name: fredbSync, Default: "", Description: "The durability of the commits: `full` syncs every commit, `off` never syncs, empty keeps the fredb default"},
+	{Name: fredbCacheSizeMB, Default: "0", Description: "The size in MB of the page cache, 0 keeps the fredb default"},
+	{Name: fredbHandlePerThread, Default: "false", Description: "Spread the rows by the hash of their key over a database file per client thread, the shared one and `<path>.shard<i>`"},
+	{Name: fredbReopenAfterLoad, Default: "false", Description: "Close and open the database again once the load is done"},
+	{Name: fredbDropCaches, Default: "", Description: "Evict the database from the page cache before the run: `file` or `all` the host caches, empty keeps them"},
+	{Name: fredbOpenTimeout, Default: fredbOpenTimeoutDefault.String(), Description: "How long opening waits while another process holds the lock file `<path>.lock`, 0 waits forever"},
+	{Name: fredbUniquePath, Default: "false", Description: "Append the start time and a random suffix to the path"},
+	{Name: fredbNestedBuckets, Default: "false", Description: "Spread the rows of a table over the shard buckets `<table>/<shard>` by the hash of their key, packed layout only"},
+	{Name: fredbBucketShards, Default: fmt.Sprint(fredbBucketShardsDefault), Description: "The number of shard buckets of a table with fredb.nested_buckets"},
+	{Name: fredbSingleBucket, Default: "false", Description: "Store every table in one bucket with `table\\x00key` keys, packed layout only"},
+	{Name: fredbKeyEncoding, Default: "raw", Description: "Rewrite the keys before they are stored: `raw`, `hash` to store them in random order or `reverse` to reverse their bytes"},
+	{Name: fredbCompression, Default: "none", Description: "Compress the rows with `snappy` or `zstd`, or `none`"},
+	{Name: fredbMaxBatchSize, Default: "0", Description: "The most operations of a batch written in one transaction, 0 writes a batch at once"},
+	{Name: fredbBatchSingleTx, Default: "true", Description: "Write the operations of a batch in one transaction, false writes every one in its own"},
+	{Name: fredbGroupCommitInterval, Default: "0", Description: "Commit the inserts, updates and deletes of the threads started within this interval in one transaction, 0 commits each on its own"},
+	{Name: fredbOptionPrefix + "<field>", Default: "", Description: "Set a field of fredb.Options by name, e.g. `fredb.option.max_readers` sets MaxReaders, after the other properties"},
+	{Name: fredbSpaceMargin, Default: "2", Description: "The factor of the estimated data set size that must be free before the load, 0 disables the check"},
+	{Name: prop.ScanMissingStartKey, Default: prop.ScanMissingStartKeyDefault, Description: "What a scan from a missing key does: `seek`, `empty` or `error`"},
+	{Name: prop.BufPoolInitialSize, Default: fmt.Sprint(prop.BufPoolInitialSizeDefault), Description: "The capacity in bytes of the new encoding buffers, 0 for the default"},
+	{Name: prop.BufPoolMaxRetained, Default: fmt.Sprint(prop.BufPoolMaxRetainedDefault), Description: "The capacity above which encoding buffers are dropped, 0 for the default"},
+}
+
 func init() {
 	ycsb.RegisterDBCreator("fredb", fredbcreator{})
+	ycsb.RegisterDBProperties("fredb", fredbProperties...)
+	ycsb.RegisterDBDataPath("fredb", ycsb.DataPath{Property: fredbPath, Default: fredbPathDefault})
 }
@@ -0,0 +1,176 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/alexhholmes/fredb"
+)
+
+// fieldSep separates the row key from the field name in the field-per-key
+// layout. It sorts before any other byte, so the fields of a row stay
+// together and the rows keep the order of their keys.
+const fieldSep = 0
+
+func rowPrefix(key string) []byte {
+	return append([]byte(key), fieldSep)
+}
+
+func fieldKey(key string, field string) []byte {
+	return append(rowPrefix(key), field...)
+}
+
+// splitFieldKey splits a key of the field-per-key layout into the row key
+// and the field name.
+func splitFieldKey(k []byte) (string, string, bool) {
+	i := bytes.IndexByte(k, fieldSep)
+	if i < 0 {
+		return "", "", false
+	}
+	return string(k[:i]), string(k[i+1:]), true
+}
+
+func wantField(fields []string, field string) bool {
+	if len(fields) == 0 {
+		return true
+	}
+	for _, f := range fields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// hasRow reports whether the row has any field in the bucket.
+func hasRow(bucket *fredb.Bucket, key string) bool {
+	prefix := rowPrefix(key)
+	k, _ := bucket.Cursor().Seek(prefix)
+	return k != nil && bytes.HasPrefix(k, prefix)
+}
+
+// readFields reads the fields of a row, it returns nil if the row doesn't
+// exist.
+func readFields(bucket *fredb.Bucket, key string, fields []string) map[string][]byte {
+	prefix := rowPrefix(key)
+	var m map[string][]byte
+	cursor := bucket.Cursor()
+	for k, v := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = cursor.Next() {
+		if m == nil {
+			m = make(map[string][]byte)
+		}
+		if field := string(k[len(prefix):]); wantField(fields, field) {
+			m[field] = append([]byte(nil), v...)
+		}
+	}
+	return m
+}
+
+// scanFields reads count rows from the start key, the first row is checked
+// like a packed row with the scanMissingStart semantics.
+func (db *freDB) scanFields(cursor rowCursor, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	keys := make([]string, 0, count)
+	res := make([]map[string][]byte, 0, count)
+
+	storedStart := db.storedKey(startKey)
+	var m map[string][]byte
+	for k, v := cursor.Seek([]byte(storedStart)); k != nil; k, v = cursor.Next() {
+		key, field, ok := splitFieldKey(k)
+		if !ok {
+			continue
+		}
+		if len(keys) == 0 || key != keys[len(keys)-1] {
+			if len(keys) == count {
+				break
+			}
+			if len(keys) == 0 && key != storedStart && db.scanMissingStart != "seek" {
+				break
+			}
+			m = make(map[string][]byte)
+			keys = append(keys, key)
+			res = append(res, m)
+		}
+		if wantField(fields, field) {
+			m[field] = append([]byte(nil), v...)
+		}
+	}
+
+	if len(keys) == 0 && db.scanMissingStart == "error" {
+		return nil, nil, fmt.Errorf("key not found: %s.%s", table, startKey)
+	}
+	for i, key := range keys {
+		keys[i] = db.rowKey(key)
+	}
+	return keys, res, nil
+}
+
+// putFields puts every field under its own key and returns the bytes
+// written.
+func putFields(bucket *fredb.Bucket, key string, values map[string][]byte) (int64, error) {
+	var written int64
+	for field, value := range values {
+		k := fieldKey(key, field)
+		if err := bucket.Put(k, value); err != nil {
+			return written, err
+		}
+		written += int64(len(k) + len(value))
+	}
+	return written, nil
+}
+
+func deleteFields(bucket *fredb.Bucket, key string) error {
+	prefix := rowPrefix(key)
+	var keys [][]byte
+	cursor := bucket.Cursor()
+	for k, _ := cursor.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		keys = append(keys, append([]byte(nil), k...))
+	}
+
+	for _, k := range keys {
+		if err := bucket.Delete(k); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// iterateFields calls fn with every row assembled from its fields.
+func iterateFields(cursor rowCursor, fn func(key string, values map[string][]byte) error) error {
+	var row string
+	var m map[string][]byte
+	for k, v := cursor.First(); k != nil; k, v = cursor.Next() {
+		key, field, ok := splitFieldKey(k)
+		if !ok {
+			continue
+		}
+		if m != nil && key != row {
+			if err := fn(row, m); err != nil {
+				return err
+			}
+			m = nil
+		}
+		if m == nil {
+			row = key
+			m = make(map[string][]byte)
+		}
+		m[field] = append([]byte(nil), v...)
+	}
+
+	if m != nil {
+		return fn(row, m)
+	}
+	return nil
+}
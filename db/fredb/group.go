@@ -0,0 +1,126 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/alexhholmes/fredb"
+)
+
+// groupCommit coalesces the single operation write transactions of the
+// threads started within an interval into one transaction, so they share
+// its fsync.
+type groupCommit struct {
+	db       *freDB
+	interval time.Duration
+
+	mu sync.Mutex
+	// pending is the group the next operations join, nil if none is open.
+	pending *commitGroup
+
+	// commits are the transactions committed and ops the operations in
+	// them.
+	commits int64
+	ops     int64
+}
+
+type commitGroup struct {
+	fns  []func(tx *fredb.Tx) error
+	errs []error
+	done chan struct{}
+}
+
+// update adds fn to the open group, opening one if needed, and waits for
+// the group to be committed.
+func (g *groupCommit) update(fn func(tx *fredb.Tx) error) error {
+	g.mu.Lock()
+	c := g.pending
+	if c == nil {
+		c = &commitGroup{done: make(chan struct{})}
+		g.pending = c
+		time.AfterFunc(g.interval, func() {
+			g.commit(c)
+		})
+	}
+	i := len(c.fns)
+	c.fns = append(c.fns, fn)
+	g.mu.Unlock()
+
+	<-c.done
+	return c.errs[i]
+}
+
+func (g *groupCommit) commit(c *commitGroup) {
+	g.mu.Lock()
+	g.pending = nil
+	g.mu.Unlock()
+
+	c.errs = make([]error, len(c.fns))
+	err := g.db.updateTx(g.db.db, func(tx *fredb.Tx) error {
+		for _, fn := range c.fns {
+			if err := fn(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		g.count(len(c.fns))
+	} else {
+		// a failed operation rolls the others back with it, so every one is
+		// committed on its own.
+		for i, fn := range c.fns {
+			if c.errs[i] = g.db.updateTx(g.db.db, fn); c.errs[i] == nil {
+				g.count(1)
+			}
+		}
+	}
+	close(c.done)
+}
+
+func (g *groupCommit) count(ops int) {
+	atomic.AddInt64(&g.commits, 1)
+	atomic.AddInt64(&g.ops, int64(ops))
+}
+
+// size returns the operations per committed transaction.
+func (g *groupCommit) size() float64 {
+	commits := atomic.LoadInt64(&g.commits)
+	if commits == 0 {
+		return 0
+	}
+	return float64(atomic.LoadInt64(&g.ops)) / float64(commits)
+}
+
+func (g *groupCommit) report() {
+	commits := atomic.LoadInt64(&g.commits)
+	if commits == 0 {
+		return
+	}
+	fmt.Printf("fredb: group commit wrote %d operations in %d transactions, %.1f per commit\n",
+		atomic.LoadInt64(&g.ops), commits, g.size())
+}
+
+// update runs fn in a write transaction of the shard of the key, the one of
+// its group with fredb.group_commit_interval.
+func (db *freDB) update(key string, fn func(tx *fredb.Tx) error) error {
+	if db.group == nil {
+		return db.updateTx(db.handle(key), fn)
+	}
+	return db.group.update(fn)
+}
@@ -0,0 +1,90 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"fmt"
+
+	"github.com/magiconair/properties"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// keyHashLen is the length of the hash the hash encoding puts before the
+// keys. It's in hex so the separator of the field layout can't appear in it.
+const keyHashLen = 16
+
+// keyEncoder rewrites the keys of the rows before they are stored and back
+// after they are read, so the order the B+tree sees is chosen apart from the
+// insertorder of the workload.
+type keyEncoder struct {
+	name string
+
+	encode func(key string) string
+	decode func(stored string) string
+}
+
+func newKeyEncoder(p *properties.Properties) (*keyEncoder, error) {
+	switch name := p.GetString(fredbKeyEncoding, "raw"); name {
+	case "raw":
+		return nil, nil
+	case "hash":
+		return &keyEncoder{
+			name: name,
+			encode: func(key string) string {
+				return fmt.Sprintf("%016x", uint64(util.StringHash64(key))) + key
+			},
+			decode: func(stored string) string {
+				if len(stored) < keyHashLen {
+					return stored
+				}
+				return stored[keyHashLen:]
+			},
+		}, nil
+	case "reverse":
+		return &keyEncoder{
+			name:   name,
+			encode: reverseKey,
+			decode: reverseKey,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unknown %s %s, expected raw, hash or reverse", fredbKeyEncoding, name)
+	}
+}
+
+// reverseKey reverses the bytes of the key, the keys sharing a prefix and
+// differing in their last digits end up far apart.
+func reverseKey(key string) string {
+	b := make([]byte, len(key))
+	for i := 0; i < len(key); i++ {
+		b[len(key)-1-i] = key[i]
+	}
+	return string(b)
+}
+
+// storedKey returns the key the row is stored under.
+func (db *freDB) storedKey(key string) string {
+	if db.keys == nil {
+		return key
+	}
+	return db.keys.encode(key)
+}
+
+// rowKey returns the key of the row stored under the key.
+func (db *freDB) rowKey(stored string) string {
+	if db.keys == nil {
+		return stored
+	}
+	return db.keys.decode(stored)
+}
@@ -0,0 +1,135 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alexhholmes/fredb"
+	"github.com/magiconair/properties"
+)
+
+// fredbOptionPrefix is the prefix of the properties set on the fields of
+// fredb.Options by name, e.g. fredb.option.max_readers sets MaxReaders, so
+// the knobs without a property of their own can be benchmarked too.
+const fredbOptionPrefix = "fredb.option."
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// optionFieldName returns the name of the field without the underscores and
+// in lower case, the way the properties name it.
+func optionFieldName(name string) string {
+	return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// optionFields returns the settable fields of fredb.Options by their
+// property name.
+func optionFields() map[string]reflect.StructField {
+	t := reflect.TypeOf(fredb.Options{})
+	fields := make(map[string]reflect.StructField, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		if f := t.Field(i); f.IsExported() {
+			fields[optionFieldName(f.Name)] = f
+		}
+	}
+	return fields
+}
+
+// parseOptionValue parses the value into a value of the type of the field.
+func parseOptionValue(t reflect.Type, s string) (reflect.Value, error) {
+	v := reflect.New(t).Elem()
+	if t == durationType {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(int64(d))
+		return v, nil
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return v, err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		n, err := strconv.ParseUint(s, 0, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, t.Bits())
+		if err != nil {
+			return v, err
+		}
+		v.SetFloat(f)
+	case reflect.String:
+		v.SetString(s)
+	default:
+		return v, fmt.Errorf("fields of type %s can't be set from a property", t)
+	}
+	return v, nil
+}
+
+// passthroughOptions returns the options setting the fields named by the
+// fredb.option. properties, they come after the ones of the other properties
+// and override them.
+func passthroughOptions(p *properties.Properties) ([]fredb.Option, error) {
+	overrides := p.FilterStripPrefix(fredbOptionPrefix)
+	if overrides.Len() == 0 {
+		return nil, nil
+	}
+
+	fields := optionFields()
+	keys := overrides.Keys()
+	sort.Strings(keys)
+	opts := make([]fredb.Option, 0, len(keys))
+	for _, key := range keys {
+		f, ok := fields[optionFieldName(key)]
+		if !ok {
+			names := make([]string, 0, len(fields))
+			for _, f := range fields {
+				names = append(names, f.Name)
+			}
+			sort.Strings(names)
+			return nil, fmt.Errorf("%s%s doesn't name a field of fredb.Options, expected one of %s", fredbOptionPrefix, key, strings.Join(names, ", "))
+		}
+		s, _ := overrides.Get(key)
+		v, err := parseOptionValue(f.Type, s)
+		if err != nil {
+			return nil, fmt.Errorf("%s%s %s: %v", fredbOptionPrefix, key, s, err)
+		}
+
+		index := f.Index
+		opts = append(opts, func(o *fredb.Options) {
+			reflect.ValueOf(o).Elem().FieldByIndex(index).Set(v)
+		})
+		fmt.Printf("fredb: set the option %s to %s\n", f.Name, s)
+	}
+	return opts, nil
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync/atomic"
+	"time"
+	"unicode"
+)
+
+// Stats implements the StatsDB Stats interface with the counters of fredb,
+// the cache hits and misses, the reads and writes of the store and the free
+// pages, and the ones of the binding. With fredb.handle_per_thread the
+// counters of every shard file are reported under `shard<i>_`.
+func (db *freDB) Stats(_ context.Context) (map[string]interface{}, error) {
+	stats := map[string]interface{}{
+		"updates":      atomic.LoadInt64(&db.updates),
+		"update_bytes": atomic.LoadInt64(&db.updateBytes),
+	}
+	if txns := atomic.LoadInt64(&db.txns); txns > 0 {
+		stats["write_txns"] = txns
+		stats["write_txn_avg_us"] = time.Duration(atomic.LoadInt64(&db.txnNanos) / txns).Microseconds()
+	}
+	for i, handle := range db.handles() {
+		prefix := ""
+		if db.shards != nil {
+			prefix = fmt.Sprintf("shard%d_", i)
+		}
+		addEngineStats(stats, prefix, reflect.ValueOf(handle.Stats()))
+	}
+	if db.group != nil {
+		stats["group_commits"] = atomic.LoadInt64(&db.group.commits)
+		stats["group_commit_size"] = db.group.size()
+	}
+	if db.compressor != nil {
+		stats["compressed_bytes"] = atomic.LoadInt64(&db.compressor.storedBytes)
+		stats["uncompressed_bytes"] = atomic.LoadInt64(&db.compressor.rawBytes)
+	}
+	return stats, nil
+}
+
+// addEngineStats adds the numeric fields of the stats of fredb under their
+// names in snake case, the fields of a nested struct after its own name,
+// like store_reads.
+func addEngineStats(stats map[string]interface{}, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := prefix + snakeCase(f.Name)
+		switch fv := v.Field(i); fv.Kind() {
+		case reflect.Struct:
+			addEngineStats(stats, name+"_", fv)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			stats[name] = fv.Int()
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			stats[name] = fv.Uint()
+		case reflect.Float32, reflect.Float64:
+			stats[name] = fv.Float()
+		}
+	}
+}
+
+func snakeCase(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// an underscore starts a word, not every letter of an acronym.
+			if i > 0 && (unicode.IsLower(runes[i-1]) || i+1 < len(runes) && unicode.IsLower(runes[i+1])) {
+				b.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
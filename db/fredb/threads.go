@@ -0,0 +1,150 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package fredb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alexhholmes/fredb"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// With fredb.handle_per_thread the rows are spread by the hash of their key
+// over a database file per client thread, the shared one and the shard files
+// next to it, to compare the contention on a single handle with a sharded
+// setup. Every thread reads and writes all the shards, so it finds the rows
+// the other threads wrote and both setups run the same operations.
+
+// shardPath returns the file of the shard, the first one is the shared file.
+func shardPath(path string, shard int) string {
+	if shard == 0 {
+		return path
+	}
+	return fmt.Sprintf("%s.shard%d", path, shard)
+}
+
+// removeShardFiles removes the shard files of an earlier run.
+func removeShardFiles(path string) {
+	paths, _ := filepath.Glob(path + ".shard*")
+	for _, path := range paths {
+		os.RemoveAll(path)
+	}
+}
+
+// openShards opens the files of the shards after the shared one, db.
+func openShards(db *fredb.DB, path string, n int, open func(path string) (*fredb.DB, error)) ([]*fredb.DB, error) {
+	shards := []*fredb.DB{db}
+	for i := 1; i < n; i++ {
+		handle, err := open(shardPath(path, i))
+		if err != nil {
+			closeShards(shards[1:])
+			return nil, err
+		}
+		shards = append(shards, handle)
+	}
+	return shards, nil
+}
+
+// closeShards closes the shards and returns the first error.
+func closeShards(shards []*fredb.DB) error {
+	var first error
+	for _, shard := range shards {
+		if err := shard.Close(); err != nil && first == nil {
+			first = err
+		}
+	}
+	return first
+}
+
+// handles returns the databases of all the shards, the shared one without
+// fredb.handle_per_thread.
+func (db *freDB) handles() []*fredb.DB {
+	if db.shards == nil {
+		return []*fredb.DB{db.db}
+	}
+	return db.shards
+}
+
+func (db *freDB) shard(key string) int {
+	return int(uint64(util.StringHash64(key)) % uint64(len(db.shards)))
+}
+
+// handle returns the database of the shard of the key, the shared one
+// without fredb.handle_per_thread.
+func (db *freDB) handle(key string) *fredb.DB {
+	if db.shards == nil {
+		return db.db
+	}
+	return db.shards[db.shard(key)]
+}
+
+// byShard calls fn with the database of every shard holding some of the keys
+// and the indexes of those keys.
+func (db *freDB) byShard(keys []string, fn func(handle *fredb.DB, idx []int) error) error {
+	if db.shards == nil {
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		return fn(db.db, idx)
+	}
+
+	idx := make([][]int, len(db.shards))
+	for i, key := range keys {
+		shard := db.shard(key)
+		idx[shard] = append(idx[shard], i)
+	}
+	for shard, idx := range idx {
+		if len(idx) == 0 {
+			continue
+		}
+		if err := fn(db.shards[shard], idx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// viewTable runs fn in a read transaction of every shard with a cursor over
+// the rows of the table in all of them, merged in key order.
+func (db *freDB) viewTable(table string, fn func(cursor rowCursor) error) error {
+	return viewAll(db.handles(), nil, func(txs []*fredb.Tx) error {
+		var cursors []rowCursor
+		for _, tx := range txs {
+			if bucket := db.table(tx, table); bucket != nil {
+				cursors = append(cursors, bucket.Cursor())
+			}
+		}
+		switch len(cursors) {
+		case 0:
+			return fmt.Errorf("table not found: %s", table)
+		case 1:
+			return fn(cursors[0])
+		}
+		return fn(newMergeCursor(cursors))
+	})
+}
+
+// viewAll opens a read transaction on every database in turn and calls fn
+// within all of them.
+func viewAll(handles []*fredb.DB, txs []*fredb.Tx, fn func(txs []*fredb.Tx) error) error {
+	if len(handles) == 0 {
+		return fn(txs)
+	}
+	return handles[0].View(func(tx *fredb.Tx) error {
+		return viewAll(handles[1:], append(txs, tx), fn)
+	})
+}
@@ -0,0 +1,146 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package remote
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/rpc"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/proxy"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// properties
+const (
+	remoteAddr        = "remote.addr"
+	remoteDialTimeout = "remote.dial_timeout"
+)
+
+const (
+	remoteAddrDefault        = "127.0.0.1:7380"
+	remoteDialTimeoutDefault = 5 * time.Second
+)
+
+type contextKey string
+
+const stateKey = contextKey("remoteDB")
+
+type remoteState struct {
+	client *rpc.Client
+}
+
+type remoteCreator struct{}
+
+// remoteDB forwards the operations to an agent hosting the database on
+// another machine, so the generation of the load and the storage don't
+// compete for the same CPUs and disks.
+type remoteDB struct {
+	addr    string
+	timeout time.Duration
+}
+
+func (c remoteCreator) Create(p *properties.Properties) (ycsb.DB, error) {
+	db := &remoteDB{
+		addr:    p.GetString(remoteAddr, remoteAddrDefault),
+		timeout: p.GetParsedDuration(remoteDialTimeout, remoteDialTimeoutDefault),
+	}
+
+	// fail early if the agent is not reachable.
+	conn, err := net.DialTimeout("tcp", db.addr, db.timeout)
+	if err != nil {
+		return nil, fmt.Errorf("connect to the agent at %s failed %v", db.addr, err)
+	}
+	conn.Close()
+	return db, nil
+}
+
+func (db *remoteDB) Close() error {
+	return nil
+}
+
+func (db *remoteDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	conn, err := net.DialTimeout("tcp", db.addr, db.timeout)
+	if err != nil {
+		util.Fatalf("connect to the agent at %s failed %v", db.addr, err)
+	}
+	client := rpc.NewClient(conn)
+	args := &proxy.InitThreadArgs{ThreadID: threadID, ThreadCount: threadCount}
+	if err := client.Call(proxy.MethodInitThread, args, &proxy.Empty{}); err != nil {
+		util.Fatalf("init thread %d in the agent at %s failed %v", threadID, db.addr, err)
+	}
+
+	return context.WithValue(ctx, stateKey, &remoteState{client: client})
+}
+
+func (db *remoteDB) CleanupThread(ctx context.Context) {
+	state := ctx.Value(stateKey).(*remoteState)
+	// the agent cleans up the thread when the connection is closed.
+	state.client.Close()
+}
+
+func call(ctx context.Context, method string, args interface{}, reply interface{}) error {
+	state := ctx.Value(stateKey).(*remoteState)
+	return state.client.Call(method, args, reply)
+}
+
+func (db *remoteDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	var reply proxy.RowReply
+	err := call(ctx, proxy.MethodRead, &proxy.ReadArgs{Table: table, Key: key, Fields: fields}, &reply)
+	return reply.Values, err
+}
+
+func (db *remoteDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	var reply proxy.RowsReply
+	err := call(ctx, proxy.MethodScan, &proxy.ScanArgs{Table: table, StartKey: startKey, Count: count, Fields: fields}, &reply)
+	return reply.Rows, err
+}
+
+func (db *remoteDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return call(ctx, proxy.MethodUpdate, &proxy.WriteArgs{Table: table, Key: key, Values: values}, &proxy.Empty{})
+}
+
+func (db *remoteDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return call(ctx, proxy.MethodInsert, &proxy.WriteArgs{Table: table, Key: key, Values: values}, &proxy.Empty{})
+}
+
+func (db *remoteDB) Delete(ctx context.Context, table string, key string) error {
+	return call(ctx, proxy.MethodDelete, &proxy.DeleteArgs{Table: table, Key: key}, &proxy.Empty{})
+}
+
+func (db *remoteDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	var reply proxy.RowsReply
+	err := call(ctx, proxy.MethodBatchRead, &proxy.BatchArgs{Table: table, Keys: keys, Fields: fields}, &reply)
+	return reply.Rows, err
+}
+
+func (db *remoteDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	return call(ctx, proxy.MethodBatchInsert, &proxy.BatchArgs{Table: table, Keys: keys, Values: values}, &proxy.Empty{})
+}
+
+func (db *remoteDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	return call(ctx, proxy.MethodBatchUpdate, &proxy.BatchArgs{Table: table, Keys: keys, Values: values}, &proxy.Empty{})
+}
+
+func (db *remoteDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	return call(ctx, proxy.MethodBatchDelete, &proxy.BatchArgs{Table: table, Keys: keys}, &proxy.Empty{})
+}
+
+func init() {
+	ycsb.RegisterDBCreator("remote", remoteCreator{})
+}
@@ -0,0 +1,160 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package selftest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// selfTestDB stores nothing. Writes are encoded and reads decode a template
+// row with the row codec, so a run against it measures how much load the
+// workload generators and the codec can produce on their own.
+type selfTestDB struct {
+	r       *util.RowCodec
+	bufPool *util.BufPool
+
+	// row is an encoded row with all the fields, used to serve reads.
+	row []byte
+}
+
+func (db *selfTestDB) InitThread(ctx context.Context, _ int, _ int) context.Context {
+	return ctx
+}
+
+func (db *selfTestDB) CleanupThread(_ context.Context) {
+}
+
+func (db *selfTestDB) Close() error {
+	return nil
+}
+
+func (db *selfTestDB) encode(values map[string][]byte) error {
+	start := time.Now()
+	buf := db.bufPool.Get()
+	buf, err := db.r.Encode(buf, values)
+	db.bufPool.Put(buf)
+	measurement.Measure("ENCODE", start, time.Now().Sub(start))
+	return err
+}
+
+func (db *selfTestDB) decode(fields []string) (map[string][]byte, error) {
+	start := time.Now()
+	m, err := db.r.Decode(db.row, fields)
+	measurement.Measure("DECODE", start, time.Now().Sub(start))
+	return m, err
+}
+
+func (db *selfTestDB) Read(_ context.Context, _ string, _ string, fields []string) (map[string][]byte, error) {
+	return db.decode(fields)
+}
+
+func (db *selfTestDB) BatchRead(_ context.Context, _ string, keys []string, fields []string) ([]map[string][]byte, error) {
+	res := make([]map[string][]byte, 0, len(keys))
+	for range keys {
+		m, err := db.decode(fields)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func (db *selfTestDB) Scan(_ context.Context, _ string, _ string, count int, fields []string) ([]map[string][]byte, error) {
+	res := make([]map[string][]byte, 0, count)
+	for i := 0; i < count; i++ {
+		m, err := db.decode(fields)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, m)
+	}
+	return res, nil
+}
+
+func (db *selfTestDB) Update(_ context.Context, _ string, _ string, values map[string][]byte) error {
+	return db.encode(values)
+}
+
+func (db *selfTestDB) BatchUpdate(_ context.Context, _ string, _ []string, values []map[string][]byte) error {
+	for _, v := range values {
+		if err := db.encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *selfTestDB) Insert(_ context.Context, _ string, _ string, values map[string][]byte) error {
+	return db.encode(values)
+}
+
+func (db *selfTestDB) BatchInsert(_ context.Context, _ string, _ []string, values []map[string][]byte) error {
+	for _, v := range values {
+		if err := db.encode(v); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *selfTestDB) Delete(_ context.Context, _ string, _ string) error {
+	return nil
+}
+
+func (db *selfTestDB) BatchDelete(_ context.Context, _ string, _ []string) error {
+	return nil
+}
+
+type selfTestCreator struct{}
+
+func (selfTestCreator) Create(p *properties.Properties) (ycsb.DB, error) {
+	if p.GetBool(prop.DataIntegrity, prop.DataIntegrityDefault) {
+		return nil, fmt.Errorf("selftest stores no data, %s must be disabled", prop.DataIntegrity)
+	}
+
+	db := &selfTestDB{
+		r:       util.NewRowCodec(p),
+		bufPool: util.NewBufPool(),
+	}
+
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	fieldLength := p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
+	values := make(map[string][]byte, fieldCount)
+	for i := int64(0); i < fieldCount; i++ {
+		v := make([]byte, fieldLength)
+		for j := range v {
+			v[j] = 'a'
+		}
+		values[fmt.Sprintf("field%d", i)] = v
+	}
+
+	var err error
+	if db.row, err = db.r.Encode(nil, values); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+func init() {
+	ycsb.RegisterDBCreator("selftest", selfTestCreator{})
+}
@@ -0,0 +1,195 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package spill
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+type contextKey string
+
+const stateKey = contextKey("spillDB")
+
+var errInsertOnly = errors.New("the spill database only supports inserts")
+
+type spillState struct {
+	w *util.SpillWriter
+}
+
+// spillDB writes the encoded rows of the load phase to one spill file per
+// thread, they can be ingested into a real database later with the ingest
+// command so generation and ingestion don't compete for CPU.
+type spillDB struct {
+	dir string
+
+	r       *util.RowCodec
+	bufPool *util.BufPool
+}
+
+func (db *spillDB) InitThread(ctx context.Context, threadID int, _ int) context.Context {
+	path := filepath.Join(db.dir, fmt.Sprintf("%d%s", threadID, util.SpillFileExt))
+	w, err := util.NewSpillWriter(path)
+	if err != nil {
+		util.Fatalf("create spill file %s failed %v", path, err)
+	}
+
+	return context.WithValue(ctx, stateKey, &spillState{w: w})
+}
+
+func (db *spillDB) CleanupThread(ctx context.Context) {
+	state := ctx.Value(stateKey).(*spillState)
+	if err := state.w.Close(); err != nil {
+		fmt.Printf("close spill file failed %v\n", err)
+	}
+}
+
+func (db *spillDB) Close() error {
+	return nil
+}
+
+func (db *spillDB) Read(_ context.Context, _ string, _ string, _ []string) (map[string][]byte, error) {
+	return nil, errInsertOnly
+}
+
+func (db *spillDB) BatchRead(_ context.Context, _ string, _ []string, _ []string) ([]map[string][]byte, error) {
+	return nil, errInsertOnly
+}
+
+func (db *spillDB) Scan(_ context.Context, _ string, _ string, _ int, _ []string) ([]map[string][]byte, error) {
+	return nil, errInsertOnly
+}
+
+func (db *spillDB) Update(_ context.Context, _ string, _ string, _ map[string][]byte) error {
+	return errInsertOnly
+}
+
+func (db *spillDB) BatchUpdate(_ context.Context, _ string, _ []string, _ []map[string][]byte) error {
+	return errInsertOnly
+}
+
+func (db *spillDB) Insert(ctx context.Context, _ string, key string, values map[string][]byte) error {
+	state := ctx.Value(stateKey).(*spillState)
+
+	buf := db.bufPool.Get()
+	defer func() {
+		db.bufPool.Put(buf)
+	}()
+
+	buf, err := db.r.Encode(buf, values)
+	if err != nil {
+		return err
+	}
+	return state.w.Write(key, buf)
+}
+
+func (db *spillDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	for i, key := range keys {
+		if err := db.Insert(ctx, table, key, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *spillDB) Delete(_ context.Context, _ string, _ string) error {
+	return errInsertOnly
+}
+
+func (db *spillDB) BatchDelete(_ context.Context, _ string, _ []string) error {
+	return errInsertOnly
+}
+
+// Iterate reads the rows back from the spill files, which hold the rows of
+// any table.
+func (db *spillDB) Iterate(ctx context.Context, _ string, fn func(key string, values map[string][]byte) error) error {
+	files, err := filepath.Glob(filepath.Join(db.dir, "*"+util.SpillFileExt))
+	if err != nil {
+		return err
+	}
+
+	for _, file := range files {
+		if err := db.iterateFile(ctx, file, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *spillDB) iterateFile(ctx context.Context, file string, fn func(key string, values map[string][]byte) error) error {
+	r, err := util.NewSpillReader(file)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		key, row, err := r.Next()
+		if err == io.EOF {
+			return nil
+		} else if err != nil {
+			return err
+		}
+
+		values, err := db.r.Decode(row, nil)
+		if err != nil {
+			return err
+		}
+		if err := fn(key, values); err != nil {
+			return err
+		}
+	}
+}
+
+type spillCreator struct{}
+
+func (spillCreator) Create(p *properties.Properties) (ycsb.DB, error) {
+	dir := p.GetString(prop.SpillDir, prop.SpillDirDefault)
+	if p.GetBool(prop.DropData, prop.DropDataDefault) {
+		os.RemoveAll(dir)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	// a load with fewer threads than the last one would leave the files of
+	// the others behind for the ingest.
+	if !p.GetBool(prop.DoTransactions, true) {
+		if err := util.RemoveSpillFiles(dir); err != nil {
+			return nil, err
+		}
+	}
+
+	return &spillDB{
+		dir:     dir,
+		r:       util.NewRowCodec(p),
+		bufPool: util.NewBufPool(),
+	}, nil
+}
+
+func init() {
+	ycsb.RegisterDBCreator("spill", spillCreator{})
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+)
+
+// maxAnnotationSize limits the size of an annotation posted as request body.
+const maxAnnotationSize = 4096
+
+// serveAnnotate records the text query parameter, or the request body if it
+// is not set, as an annotation.
+func serveAnnotate(w http.ResponseWriter, r *http.Request) {
+	text := r.FormValue("text")
+	if text == "" {
+		body, err := io.ReadAll(io.LimitReader(r.Body, maxAnnotationSize))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		text = strings.TrimSpace(string(body))
+	}
+	if text == "" {
+		http.Error(w, "empty annotation", http.StatusBadRequest)
+		return
+	}
+
+	measurement.Annotate(text)
+	io.WriteString(w, "ok\n")
+}
+
+// annotateOnSignal records an annotation named after the signal every time
+// one of the annotation signals is received.
+func annotateOnSignal(ctx context.Context) {
+	sigs := annotationSignals()
+	if len(sigs) == 0 {
+		return
+	}
+
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, sigs...)
+	defer signal.Stop(ch)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case sig := <-ch:
+			measurement.Annotate("signal " + sig.String())
+		}
+	}
+}
+
+func init() {
+	// served on the debug.pprof address.
+	http.HandleFunc("/annotate", serveAnnotate)
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"runtime"
+	"runtime/debug"
+	"sort"
+	"strings"
+)
+
+// engineModules are the modules of the engines whose versions are recorded
+// with the results.
+var engineModules = []string{
+	"github.com/alexhholmes/fredb",
+}
+
+// BuildInfo describes the binary the results come from.
+type BuildInfo struct {
+	Version string `json:"version"`
+	// GitSHA is the git revision, it ends with -dirty if the tree had local
+	// changes.
+	GitSHA    string `json:"git_sha,omitempty"`
+	BuildTime string `json:"build_time,omitempty"`
+	GoVersion string `json:"go_version"`
+	Tags      string `json:"tags,omitempty"`
+	// Engines are the versions of the engine modules built in, by module
+	// path.
+	Engines map[string]string `json:"engines,omitempty"`
+}
+
+// CurrentBuild returns the build information embedded in the binary.
+func CurrentBuild() BuildInfo {
+	b := BuildInfo{Version: "unknown", GoVersion: runtime.Version()}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return b
+	}
+	b.Version = info.Main.Version
+
+	modified := false
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			b.GitSHA = s.Value
+		case "vcs.modified":
+			modified = s.Value == "true"
+		case "vcs.time":
+			b.BuildTime = s.Value
+		case "-tags":
+			b.Tags = s.Value
+		}
+	}
+	if b.GitSHA != "" && modified {
+		b.GitSHA += "-dirty"
+	}
+
+	for _, dep := range info.Deps {
+		for _, path := range engineModules {
+			if dep.Path != path {
+				continue
+			}
+			if b.Engines == nil {
+				b.Engines = make(map[string]string)
+			}
+			b.Engines[path] = moduleVersion(dep)
+		}
+	}
+	return b
+}
+
+// moduleVersion returns the version of the module, with its replacement if
+// it's replaced.
+func moduleVersion(m *debug.Module) string {
+	if m.Replace == nil {
+		return m.Version
+	}
+	if m.Replace.Version == "" {
+		return fmt.Sprintf("%s => %s", m.Version, m.Replace.Path)
+	}
+	return fmt.Sprintf("%s => %s %s", m.Version, m.Replace.Path, m.Replace.Version)
+}
+
+// EngineChanges returns the engines whose version differs from the one in
+// other, as "<module> <other version> -> <version>".
+func (b BuildInfo) EngineChanges(other BuildInfo) []string {
+	var changes []string
+	for _, path := range engineModules {
+		if b.Engines[path] != other.Engines[path] {
+			changes = append(changes, fmt.Sprintf("%s %q -> %q", path, other.Engines[path], b.Engines[path]))
+		}
+	}
+	return changes
+}
+
+func (b BuildInfo) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "go-ycsb %s", b.Version)
+	if b.GitSHA != "" {
+		fmt.Fprintf(&sb, ", revision %s", b.GitSHA)
+	}
+	if b.BuildTime != "" {
+		fmt.Fprintf(&sb, " from %s", b.BuildTime)
+	}
+	fmt.Fprintf(&sb, "\n%s %s/%s", b.GoVersion, runtime.GOOS, runtime.GOARCH)
+	if b.Tags != "" {
+		fmt.Fprintf(&sb, ", tags %s", b.Tags)
+	}
+	paths := make([]string, 0, len(b.Engines))
+	for path := range b.Engines {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	for _, path := range paths {
+		fmt.Fprintf(&sb, "\n%s %s", path, b.Engines[path])
+	}
+	return sb.String()
+}
@@ -15,15 +15,18 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math/rand"
 	"os"
-	"sync"
+	"runtime/debug"
+	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/measurement"
 	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
@@ -39,6 +42,19 @@ type worker struct {
 	threadID        int
 	targetOpsTickNs int64
 	opsDone         int64
+	threadCount     int
+	watchdog        *watchdog
+	panics          *panicRecorder
+	errors          *errorClasses
+
+	// targetChanged is closed once the target changes, the throttle then
+	// starts over from throttleStart and throttleOps.
+	targetChanged <-chan struct{}
+	throttleStart time.Time
+	throttleOps   int64
+	paused        bool
+	// behind is the share of the worker in inFlight.behind.
+	behind int64
 }
 
 func newWorker(p *properties.Properties, threadID int, threadCount int, workload ycsb.Workload, db ycsb.DB) *worker {
@@ -50,6 +66,7 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 		w.doBatch = true
 	}
 	w.threadID = threadID
+	w.threadCount = threadCount
 	w.workload = workload
 	w.workDB = db
 
@@ -80,70 +97,119 @@ func newWorker(p *properties.Properties, threadID int, threadCount int, workload
 		w.opCount++
 	}
 
-	targetPerThreadPerms := float64(-1)
-	if v := p.GetInt64(prop.Target, 0); v > 0 {
-		targetPerThread := float64(v) / float64(threadCount)
-		targetPerThreadPerms = targetPerThread / 1000.0
-	}
+	var ops int64
+	ops, w.targetChanged = target.get()
+	w.setTarget(ops)
+
+	return w
+}
 
-	if targetPerThreadPerms > 0 {
-		w.targetOpsPerMs = targetPerThreadPerms
+// setTarget sets the share of the worker of the total target.
+func (w *worker) setTarget(ops int64) {
+	w.targetOpsPerMs = 0
+	w.targetOpsTickNs = 0
+	w.paused = ops == targetPaused
+	if ops > 0 {
+		targetPerThread := float64(ops) / float64(w.threadCount)
+		w.targetOpsPerMs = targetPerThread / 1000.0
 		w.targetOpsTickNs = int64(1000000.0 / w.targetOpsPerMs)
 	}
-
-	return w
+	w.throttleStart = time.Now()
+	w.throttleOps = w.opsDone
 }
 
-func (w *worker) throttle(ctx context.Context, startTime time.Time) {
+func (w *worker) throttle(ctx context.Context) {
+	for {
+		select {
+		case <-w.targetChanged:
+			var ops int64
+			ops, w.targetChanged = target.get()
+			w.setTarget(ops)
+		default:
+		}
+		if !w.paused {
+			break
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-w.targetChanged:
+		}
+	}
+
 	if w.targetOpsPerMs <= 0 {
+		w.setBehind(0)
 		return
 	}
 
-	d := time.Duration(w.opsDone * w.targetOpsTickNs)
-	d = startTime.Add(d).Sub(time.Now())
+	d := time.Duration((w.opsDone - w.throttleOps) * w.targetOpsTickNs)
+	d = w.throttleStart.Add(d).Sub(time.Now())
 	if d < 0 {
+		w.setBehind(int64(-d) / w.targetOpsTickNs)
 		return
 	}
+	w.setBehind(0)
 	select {
 	case <-ctx.Done():
+	case <-w.targetChanged:
 	case <-time.After(d):
 	}
 }
 
+// setBehind sets how many operations the worker is behind the target schedule.
+func (w *worker) setBehind(ops int64) {
+	if ops != w.behind {
+		atomic.AddInt64(&inFlight.behind, ops-w.behind)
+		w.behind = ops
+	}
+}
+
 func (w *worker) run(ctx context.Context) {
+	defer w.setBehind(0)
+
 	// spread the thread operation out so they don't all hit the DB at the same time
 	if w.targetOpsPerMs > 0.0 && w.targetOpsPerMs <= 1.0 {
 		time.Sleep(time.Duration(rand.Int63n(w.targetOpsTickNs)))
 	}
 
-	startTime := time.Now()
+	w.throttleStart = time.Now()
 
 	for w.opCount == 0 || w.opsDone < w.opCount {
-		var err error
-		opsCount := 1
-		if w.doTransactions {
-			if w.doBatch {
-				err = w.workload.DoBatchTransaction(ctx, w.batchSize, w.workDB)
-				opsCount = w.batchSize
-			} else {
-				err = w.workload.DoTransaction(ctx, w.workDB)
-			}
-		} else {
-			if w.doBatch {
-				err = w.workload.DoBatchInsert(ctx, w.batchSize, w.workDB)
-				opsCount = w.batchSize
-			} else {
-				err = w.workload.DoInsert(ctx, w.workDB)
-			}
+		batchSize := w.batchSize
+		if w.opCount > 0 && w.opCount-w.opsDone < int64(batchSize) {
+			// the last batch ends with the operations of the thread.
+			batchSize = int(w.opCount - w.opsDone)
 		}
+		submitted := int64(1)
+		if w.doBatch {
+			submitted = int64(batchSize)
+		}
+		inFlight.begin(submitted)
+		opsCount, err := w.doOperation(ctx, batchSize)
+		inFlight.end(submitted)
+
+		w.watchdog.done()
 
+		if errors.Is(err, ycsb.ErrNoMoreKeys) {
+			// the warm-up used the keys of the thread up before its
+			// operations were done.
+			fmt.Printf("thread %d: %v after %d operations\n", w.threadID, err, w.opsDone)
+			return
+		}
 		if err != nil && !w.p.GetBool(prop.Silence, prop.SilenceDefault) {
 			fmt.Printf("operation err: %v\n", err)
 		}
+		if err != nil && w.errors != nil {
+			w.errors.record(err)
+		}
 
 		if measurement.IsWarmUpFinished() {
 			w.opsDone += int64(opsCount)
-			w.throttle(ctx, startTime)
+			if err == nil || !measurement.SuccessOnly() {
+				atomic.AddInt64(&completedOps, int64(opsCount))
+			}
+			w.throttle(ctx)
 		}
 
 		select {
@@ -154,6 +220,36 @@ func (w *worker) run(ctx context.Context) {
 	}
 }
 
+func (w *worker) doOperation(ctx context.Context, batchSize int) (opsCount int, err error) {
+	if w.panics != nil {
+		start := time.Now()
+		defer func() {
+			if v := recover(); v != nil {
+				w.panics.record(w.threadID, start, v, debug.Stack())
+				err = fmt.Errorf("recovered from panic: %v", v)
+			}
+		}()
+	}
+
+	opsCount = 1
+	if w.doTransactions {
+		if w.doBatch {
+			err = w.workload.DoBatchTransaction(ctx, batchSize, w.workDB)
+			opsCount = batchSize
+		} else {
+			err = w.workload.DoTransaction(ctx, w.workDB)
+		}
+	} else {
+		if w.doBatch {
+			err = w.workload.DoBatchInsert(ctx, batchSize, w.workDB)
+			opsCount = batchSize
+		} else {
+			err = w.workload.DoInsert(ctx, w.workDB)
+		}
+	}
+	return opsCount, err
+}
+
 // Client is a struct which is used the run workload to a specific DB.
 type Client struct {
 	p        *properties.Properties
@@ -169,10 +265,51 @@ func NewClient(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *Cl
 
 // Run runs the workload to the target DB, and blocks until all workers end.
 func (c *Client) Run(ctx context.Context) {
-	var wg sync.WaitGroup
+	var workers workerGroup
 	threadCount := c.p.GetInt(prop.ThreadCount, 1)
 
-	wg.Add(threadCount)
+	started := time.Now()
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+	defer startDeadline(c.p, cancel)()
+
+	limiter := newMemoryLimiter(c.p)
+	if limiter != nil {
+		go limiter.run(ctx, cancel)
+	}
+
+	panics := newPanicRecorder(c.p)
+
+	target.set(c.p.GetInt64(prop.Target, 0))
+	if path := c.p.GetString(prop.TargetFile, ""); path != "" {
+		go reloadTargetOnHangup(ctx, path)
+	}
+	atomic.StoreInt64(&completedOps, 0)
+	if m := newMaintenance(c.p); m != nil {
+		go m.run(ctx)
+	}
+	if l := newLoadPattern(c.p); l != nil {
+		go l.run(ctx)
+	}
+
+	outliers = newOutlierRecorder(c.p, c.db)
+	stalls = newStallDetector(c.p)
+
+	wd := newWatchdog(c.p, c.db)
+	if wd != nil {
+		go wd.run(ctx, cancel)
+	}
+
+	if d := newDashboard(c.p); d != nil {
+		go d.run(ctx)
+	}
+	go annotateOnSignal(ctx)
+
+	meter := newEnergyMeter(c.p)
+	report := newRunReport(c.p)
+	notify := newNotifier(c.p)
+
+	workers.add(threadCount)
 	measureCtx, measureCancel := context.WithCancel(ctx)
 	measureCh := make(chan struct{}, 1)
 	go func() {
@@ -195,21 +332,95 @@ func (c *Client) Run(ctx context.Context) {
 		t := time.NewTicker(time.Duration(dur) * time.Second)
 		defer t.Stop()
 
+		logInFlight := c.p.GetBool(prop.MeasurementInFlight, prop.MeasurementInFlightDefault)
+		logOS := c.p.GetBool(prop.MeasurementOS, prop.MeasurementOSDefault)
+		var sampler util.OSSampler
+		if logOS {
+			sampler.Sample()
+		}
+
+		var energyTick <-chan time.Time
+		if meter != nil {
+			meter.begin()
+			et := time.NewTicker(energyPoll)
+			defer et.Stop()
+			energyTick = et.C
+		}
+
+		var reportTick <-chan time.Time
+		if report != nil {
+			report.begin()
+			rt := time.NewTicker(reportPoll)
+			defer rt.Stop()
+			reportTick = rt.C
+		}
+
 		for {
 			select {
 			case <-t.C:
 				measurement.Summary()
+				if logInFlight {
+					s := inFlight.sample(inFlightLog)
+					fmt.Printf("[INFLIGHT] running %d, peak %d, queued %d, behind the target %d\n",
+						s.Running, s.Peak, s.Queued, s.Behind)
+				}
+				if logOS {
+					if stats, ok := sampler.Sample(); ok {
+						fmt.Printf("[OS] dirty %d MB, writeback %d MB, process I/O wait %.1f%%, CPU iowait %.1f%%\n",
+							stats.DirtyBytes>>20, stats.WritebackBytes>>20, stats.IOWait*100, stats.CPUIOWait*100)
+					}
+				}
+			case <-energyTick:
+				meter.sample()
+			case <-reportTick:
+				report.sample()
 			case <-measureCtx.Done():
+				if meter != nil {
+					meter.end()
+				}
 				return
 			}
 		}
 	}()
 
+	workDB := c.db
+	var cm *committer
+	if !c.p.GetBool(prop.DoTransactions, true) {
+		if cm = newCommitter(c.p, c.db); cm != nil {
+			workDB = committerDB{DB: c.db, c: cm}
+			if outliers != nil {
+				outliers.queue = cm.queue
+			}
+			inFlight.queue.Store(&cm.queue)
+			cmCtx := c.db.InitThread(ctx, threadCount, threadCount+1)
+			go func() {
+				cm.run(cmCtx)
+				c.db.CleanupThread(cmCtx)
+			}()
+		}
+	}
+
+	if outliers != nil {
+		go outliers.run(ctx)
+	}
+
+	verify := newVerifier(c.p, c.workload, c.db)
+	verifyCtx, verifyCancel := context.WithCancel(ctx)
+	defer verifyCancel()
+	if verify != nil {
+		go verify.run(verifyCtx, threadCount)
+	}
+
 	for i := 0; i < threadCount; i++ {
 		go func(threadId int) {
-			defer wg.Done()
+			defer workers.done()
 
-			w := newWorker(c.p, threadId, threadCount, c.workload, c.db)
+			w := newWorker(c.p, threadId, threadCount, c.workload, workDB)
+			w.watchdog = wd
+			w.panics = panics
+			if notify != nil {
+				w.errors = notify.errors
+			}
 			ctx := c.workload.InitThread(ctx, threadId, threadCount)
 			ctx = c.db.InitThread(ctx, threadId, threadCount)
 			w.run(ctx)
@@ -218,7 +429,19 @@ func (c *Client) Run(ctx context.Context) {
 		}(i)
 	}
 
-	wg.Wait()
+	stuck := workers.wait(ctx, c.p.GetParsedDuration(prop.MaxExecutionTimeGrace, prop.MaxExecutionTimeGraceDefault))
+	verifyCancel()
+	if verify != nil {
+		verify.report()
+	}
+	// the cause is set when the run stops early.
+	cause := context.Cause(ctx)
+	// the workers left behind may still send to the committer queue.
+	if cm != nil && stuck == 0 {
+		cm.close()
+		cm.report()
+		inFlight.queue.Store(nil)
+	}
 	if !c.p.GetBool(prop.DoTransactions, true) {
 		// when loading is finished, try to analyze table if possible.
 		if analyzeDB, ok := c.db.(ycsb.AnalyzeDB); ok {
@@ -227,4 +450,30 @@ func (c *Client) Run(ctx context.Context) {
 	}
 	measureCancel()
 	<-measureCh
+
+	if meter != nil {
+		meter.report()
+	}
+	if limiter != nil {
+		limiter.report()
+	}
+	if panics != nil {
+		panics.report()
+	}
+	if outliers != nil {
+		outliers.close()
+		outliers.report()
+		outliers = nil
+	}
+	if stalls != nil {
+		stalls.close()
+		stalls.report()
+		stalls = nil
+	}
+	if report != nil {
+		report.write()
+	}
+	if notify != nil {
+		notify.send(started, cause)
+	}
 }
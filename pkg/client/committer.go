@@ -0,0 +1,215 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+var errCommitterOnly = errors.New("only inserts are allowed in the committer load mode")
+
+type committerItem struct {
+	key    string
+	values map[string][]byte
+	row    []byte
+}
+
+// committer is the single writer of the committer load mode. The workers
+// generate and encode the rows and hand them over through a bounded queue,
+// the committer writes them in large transactions.
+type committer struct {
+	db        ycsb.DB
+	table     string
+	batchSize int
+	bulk      bool
+	codec     *util.RowCodec
+
+	queue chan committerItem
+	done  chan struct{}
+
+	txns       int64
+	rows       int64
+	errs       int64
+	depthSum   int64
+	depthMax   int
+	depthCount int64
+}
+
+func newCommitter(p *properties.Properties, db ycsb.DB) *committer {
+	if !p.GetBool(prop.LoadCommitter, prop.LoadCommitterDefault) {
+		return nil
+	}
+
+	bulk := false
+	if w, ok := db.(DbWrapper); ok {
+		bulk = canIngest(w.DB)
+	}
+
+	return &committer{
+		db:        db,
+		table:     p.GetString(prop.TableName, prop.TableNameDefault),
+		batchSize: p.GetInt(prop.LoadCommitterBatch, prop.LoadCommitterBatchDefault),
+		bulk:      bulk,
+		codec:     util.NewRowCodec(p),
+		queue:     make(chan committerItem, p.GetInt(prop.LoadCommitterQueue, prop.LoadCommitterQueueDefault)),
+		done:      make(chan struct{}),
+	}
+}
+
+// run drains the queue until it is closed.
+func (c *committer) run(ctx context.Context) {
+	defer close(c.done)
+
+	keys := make([]string, 0, c.batchSize)
+	values := make([]map[string][]byte, 0, c.batchSize)
+	rows := make([][]byte, 0, c.batchSize)
+	for item := range c.queue {
+		keys = append(keys, item.key)
+		values = append(values, item.values)
+		rows = append(rows, item.row)
+
+		if len(keys) < c.batchSize && len(c.queue) > 0 {
+			continue
+		}
+
+		depth := len(c.queue)
+		c.depthSum += int64(depth)
+		c.depthCount++
+		if depth > c.depthMax {
+			c.depthMax = depth
+		}
+
+		c.commit(ctx, keys, values, rows)
+		keys, values, rows = keys[:0], values[:0], rows[:0]
+	}
+}
+
+func (c *committer) commit(ctx context.Context, keys []string, values []map[string][]byte, rows [][]byte) {
+	var err error
+	switch {
+	case c.bulk:
+		err = c.db.(DbWrapper).IngestRows(ctx, c.table, keys, rows)
+	case len(keys) == 1:
+		err = c.db.Insert(ctx, c.table, keys[0], values[0])
+	default:
+		err = c.db.(ycsb.BatchDB).BatchInsert(ctx, c.table, keys, values)
+	}
+
+	c.txns++
+	if err != nil {
+		c.errs++
+		fmt.Printf("committer err: %v\n", err)
+		return
+	}
+	c.rows += int64(len(keys))
+}
+
+func (c *committer) enqueue(ctx context.Context, key string, values map[string][]byte) error {
+	item := committerItem{key: key}
+	if c.bulk {
+		row, err := c.codec.Encode(nil, values)
+		if err != nil {
+			return err
+		}
+		item.row = row
+	} else {
+		// the workload recycles the value buffers once the insert returns.
+		item.values = copyValues(values)
+	}
+
+	start := time.Now()
+	select {
+	case c.queue <- item:
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+	measurement.Measure("ENQUEUE", start, time.Now().Sub(start))
+	return nil
+}
+
+// close closes the queue and waits for the committer to write what's left.
+func (c *committer) close() {
+	close(c.queue)
+	<-c.done
+}
+
+func (c *committer) report() {
+	avgBatch, avgDepth := float64(0), float64(0)
+	if c.txns > 0 {
+		avgBatch = float64(c.rows) / float64(c.txns)
+	}
+	if c.depthCount > 0 {
+		avgDepth = float64(c.depthSum) / float64(c.depthCount)
+	}
+	fmt.Printf("[COMMITTER] rows: %d, transactions: %d, failed transactions: %d, avg rows per transaction: %.1f, avg queue depth: %.1f, max queue depth: %d/%d\n",
+		c.rows, c.txns, c.errs, avgBatch, avgDepth, c.depthMax, cap(c.queue))
+}
+
+// committerDB is the DB the workers use in the committer load mode, inserts
+// are queued for the committer.
+type committerDB struct {
+	ycsb.DB
+
+	c *committer
+}
+
+func (db committerDB) Read(_ context.Context, _ string, _ string, _ []string) (map[string][]byte, error) {
+	return nil, errCommitterOnly
+}
+
+func (db committerDB) Scan(_ context.Context, _ string, _ string, _ int, _ []string) ([]map[string][]byte, error) {
+	return nil, errCommitterOnly
+}
+
+func (db committerDB) Update(_ context.Context, _ string, _ string, _ map[string][]byte) error {
+	return errCommitterOnly
+}
+
+func (db committerDB) Delete(_ context.Context, _ string, _ string) error {
+	return errCommitterOnly
+}
+
+func (db committerDB) Insert(ctx context.Context, _ string, key string, values map[string][]byte) error {
+	return db.c.enqueue(ctx, key, values)
+}
+
+func (db committerDB) BatchInsert(ctx context.Context, _ string, keys []string, values []map[string][]byte) error {
+	for i, key := range keys {
+		if err := db.c.enqueue(ctx, key, values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db committerDB) BatchRead(_ context.Context, _ string, _ []string, _ []string) ([]map[string][]byte, error) {
+	return nil, errCommitterOnly
+}
+
+func (db committerDB) BatchUpdate(_ context.Context, _ string, _ []string, _ []map[string][]byte) error {
+	return errCommitterOnly
+}
+
+func (db committerDB) BatchDelete(_ context.Context, _ string, _ []string) error {
+	return errCommitterOnly
+}
@@ -0,0 +1,178 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+//go:embed dashboard.html
+var dashboardPage []byte
+
+// dashboardBacklog is the number of events queued for a client, a client
+// falling further behind misses events.
+const dashboardBacklog = 16
+
+// dashboardEvent is pushed to the WebSocket clients after every interval.
+type dashboardEvent struct {
+	Time    time.Time                            `json:"time"`
+	Elapsed float64                              `json:"elapsed"`
+	Ops     map[string]measurement.IntervalStats `json:"ops"`
+	// Annotations are the annotations recorded in the interval.
+	Annotations []measurement.Annotation `json:"annotations,omitempty"`
+	// System are the writeback and I/O wait statistics of the kernel, only
+	// on Linux.
+	System *util.OSStats `json:"system,omitempty"`
+	// InFlight are the operations in flight in the interval.
+	InFlight inFlightSample `json:"in_flight"`
+}
+
+// dashboard serves a static page showing the interval metrics, which are
+// pushed to the page over a WebSocket.
+type dashboard struct {
+	addr     string
+	interval time.Duration
+
+	mu      sync.Mutex
+	clients map[chan []byte]struct{}
+	// done is closed when the run ends to disconnect the clients.
+	done chan struct{}
+}
+
+func newDashboard(p *properties.Properties) *dashboard {
+	addr := p.GetString(prop.DashboardAddr, prop.DashboardAddrDefault)
+	if addr == "" {
+		return nil
+	}
+
+	return &dashboard{
+		addr:     addr,
+		interval: p.GetParsedDuration(prop.DashboardInterval, prop.DashboardIntervalDefault),
+		clients:  make(map[chan []byte]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+func (d *dashboard) serveWebSocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := util.UpgradeWebSocket(w, r)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	ch := make(chan []byte, dashboardBacklog)
+	d.mu.Lock()
+	d.clients[ch] = struct{}{}
+	d.mu.Unlock()
+	defer func() {
+		d.mu.Lock()
+		delete(d.clients, ch)
+		d.mu.Unlock()
+	}()
+
+	closed := make(chan struct{})
+	go func() {
+		conn.Discard()
+		close(closed)
+	}()
+
+	for {
+		select {
+		case msg := <-ch:
+			if err := conn.WriteText(msg); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		case <-d.done:
+			return
+		}
+	}
+}
+
+func (d *dashboard) broadcast(msg []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for ch := range d.clients {
+		select {
+		case ch <- msg:
+		default:
+		}
+	}
+}
+
+func (d *dashboard) run(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.Write(dashboardPage)
+	})
+	mux.HandleFunc("/ws", d.serveWebSocket)
+	mux.HandleFunc("/annotate", serveAnnotate)
+	mux.HandleFunc("/target", serveTarget)
+
+	l, err := net.Listen("tcp", d.addr)
+	if err != nil {
+		fmt.Printf("[DASHBOARD] listen on %s failed %v\n", d.addr, err)
+		return
+	}
+	srv := &http.Server{Handler: mux}
+	go srv.Serve(l)
+	defer srv.Close()
+	defer close(d.done)
+	fmt.Printf("[DASHBOARD] serving on http://%s\n", l.Addr())
+
+	start := time.Now()
+	measurement.Interval()
+	_, seen := measurement.Annotations(0)
+	var sampler util.OSSampler
+	sampler.Sample()
+	t := time.NewTicker(d.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			event := dashboardEvent{
+				Time:     now,
+				Elapsed:  now.Sub(start).Seconds(),
+				Ops:      measurement.Interval(),
+				InFlight: inFlight.sample(inFlightDashboard),
+			}
+			event.Annotations, seen = measurement.Annotations(seen)
+			if stats, ok := sampler.Sample(); ok {
+				event.System = &stats
+			}
+			msg, err := json.Marshal(event)
+			if err != nil {
+				continue
+			}
+			d.broadcast(msg)
+		}
+	}
+}
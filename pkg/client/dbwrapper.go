@@ -15,6 +15,7 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,15 +28,67 @@ type DbWrapper struct {
 	DB ycsb.DB
 }
 
-func measure(start time.Time, op string, err error) {
-	lan := time.Now().Sub(start)
-	if err != nil {
-		measurement.Measure(fmt.Sprintf("%s_ERROR", op), start, lan)
+// errNotFound is measured for the reads returning no rows when they count as
+// failures, it's never returned to the workload.
+var errNotFound = errors.New("not found")
+
+// found returns errNotFound for a successful read of no rows if such reads
+// count as failures.
+func found(err error, rows int) error {
+	if err == nil && rows == 0 && measurement.SuccessOnly() {
+		return errNotFound
+	}
+	return err
+}
+
+func measure(ctx context.Context, start time.Time, op string, err error) {
+	if measurement.Unmeasured(ctx) {
 		return
 	}
+	lan := time.Now().Sub(start)
+	if err == errNotFound {
+		op = fmt.Sprintf("%s_NOT_FOUND", op)
+	} else if err != nil {
+		op = fmt.Sprintf("%s_ERROR", op)
+	}
 
 	measurement.Measure(op, start, lan)
-	measurement.Measure("TOTAL", start, lan)
+	outliers.check(op, start, lan)
+	if label := measurement.Label(ctx); label != "" {
+		measurement.Measure(label+"-"+op, start, lan)
+	}
+	if err == nil {
+		measurement.Measure("TOTAL", start, lan)
+		stalls.check(op, start, lan)
+	}
+}
+
+// measureBytes counts the bytes of the successful operation.
+func measureBytes(ctx context.Context, op string, err error, read int64, written int64) {
+	if err != nil || measurement.Unmeasured(ctx) {
+		return
+	}
+	measurement.AddBytes(op, read, written)
+	if label := measurement.Label(ctx); label != "" {
+		measurement.AddBytes(label+"-"+op, read, written)
+	}
+	measurement.AddBytes("TOTAL", read, written)
+}
+
+func valuesSize(values map[string][]byte) int64 {
+	var n int64
+	for _, v := range values {
+		n += int64(len(v))
+	}
+	return n
+}
+
+func rowsSize(rows []map[string][]byte) int64 {
+	var n int64
+	for _, values := range rows {
+		n += valuesSize(values)
+	}
+	return n
 }
 
 func (db DbWrapper) Close() error {
@@ -50,21 +103,23 @@ func (db DbWrapper) CleanupThread(ctx context.Context) {
 	db.DB.CleanupThread(ctx)
 }
 
-func (db DbWrapper) Read(ctx context.Context, table string, key string, fields []string) (_ map[string][]byte, err error) {
+func (db DbWrapper) Read(ctx context.Context, table string, key string, fields []string) (values map[string][]byte, err error) {
 	start := time.Now()
 	defer func() {
-		measure(start, "READ", err)
+		measure(ctx, start, "READ", found(err, len(values)))
+		measureBytes(ctx, "READ", err, valuesSize(values), 0)
 	}()
 
 	return db.DB.Read(ctx, table, key, fields)
 }
 
-func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string, fields []string) (_ []map[string][]byte, err error) {
+func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string, fields []string) (values []map[string][]byte, err error) {
 	batchDB, ok := db.DB.(ycsb.BatchDB)
 	if ok {
 		start := time.Now()
 		defer func() {
-			measure(start, "BATCH_READ", err)
+			measure(ctx, start, "BATCH_READ", err)
+			measureBytes(ctx, "BATCH_READ", err, rowsSize(values), 0)
 		}()
 		return batchDB.BatchRead(ctx, table, keys, fields)
 	}
@@ -77,10 +132,11 @@ func (db DbWrapper) BatchRead(ctx context.Context, table string, keys []string,
 	return nil, nil
 }
 
-func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (_ []map[string][]byte, err error) {
+func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (values []map[string][]byte, err error) {
 	start := time.Now()
 	defer func() {
-		measure(start, "SCAN", err)
+		measure(ctx, start, "SCAN", found(err, len(values)))
+		measureBytes(ctx, "SCAN", err, rowsSize(values), 0)
 	}()
 
 	return db.DB.Scan(ctx, table, startKey, count, fields)
@@ -89,7 +145,8 @@ func (db DbWrapper) Scan(ctx context.Context, table string, startKey string, cou
 func (db DbWrapper) Update(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
 	start := time.Now()
 	defer func() {
-		measure(start, "UPDATE", err)
+		measure(ctx, start, "UPDATE", err)
+		measureBytes(ctx, "UPDATE", err, 0, valuesSize(values))
 	}()
 
 	return db.DB.Update(ctx, table, key, values)
@@ -100,7 +157,8 @@ func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string
 	if ok {
 		start := time.Now()
 		defer func() {
-			measure(start, "BATCH_UPDATE", err)
+			measure(ctx, start, "BATCH_UPDATE", err)
+			measureBytes(ctx, "BATCH_UPDATE", err, 0, rowsSize(values))
 		}()
 		return batchDB.BatchUpdate(ctx, table, keys, values)
 	}
@@ -116,7 +174,8 @@ func (db DbWrapper) BatchUpdate(ctx context.Context, table string, keys []string
 func (db DbWrapper) Insert(ctx context.Context, table string, key string, values map[string][]byte) (err error) {
 	start := time.Now()
 	defer func() {
-		measure(start, "INSERT", err)
+		measure(ctx, start, "INSERT", err)
+		measureBytes(ctx, "INSERT", err, 0, valuesSize(values))
 	}()
 
 	return db.DB.Insert(ctx, table, key, values)
@@ -127,7 +186,8 @@ func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string
 	if ok {
 		start := time.Now()
 		defer func() {
-			measure(start, "BATCH_INSERT", err)
+			measure(ctx, start, "BATCH_INSERT", err)
+			measureBytes(ctx, "BATCH_INSERT", err, 0, rowsSize(values))
 		}()
 		return batchDB.BatchInsert(ctx, table, keys, values)
 	}
@@ -143,7 +203,7 @@ func (db DbWrapper) BatchInsert(ctx context.Context, table string, keys []string
 func (db DbWrapper) Delete(ctx context.Context, table string, key string) (err error) {
 	start := time.Now()
 	defer func() {
-		measure(start, "DELETE", err)
+		measure(ctx, start, "DELETE", err)
 	}()
 
 	return db.DB.Delete(ctx, table, key)
@@ -154,7 +214,7 @@ func (db DbWrapper) BatchDelete(ctx context.Context, table string, keys []string
 	if ok {
 		start := time.Now()
 		defer func() {
-			measure(start, "BATCH_DELETE", err)
+			measure(ctx, start, "BATCH_DELETE", err)
 		}()
 		return batchDB.BatchDelete(ctx, table, keys)
 	}
@@ -167,9 +227,53 @@ func (db DbWrapper) BatchDelete(ctx context.Context, table string, keys []string
 	return nil
 }
 
+func (db DbWrapper) IngestRows(ctx context.Context, table string, keys []string, rows [][]byte) (err error) {
+	ingestDB, ok := db.DB.(ycsb.BulkIngestDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the BulkIngestDB interface", db.DB)
+	}
+
+	start := time.Now()
+	defer func() {
+		measure(ctx, start, "INGEST", err)
+	}()
+	return ingestDB.IngestRows(ctx, table, keys, rows)
+}
+
 func (db DbWrapper) Analyze(ctx context.Context, table string) error {
 	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
 		return analyzeDB.Analyze(ctx, table)
 	}
 	return nil
 }
+
+func (db DbWrapper) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	iterateDB, ok := db.DB.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db.DB)
+	}
+	return iterateDB.Iterate(ctx, table, fn)
+}
+
+func (db DbWrapper) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db.DB)
+	}
+	return scanDB.ScanKeys(ctx, table, startKey, count, fields)
+}
+
+func (db DbWrapper) Stats(ctx context.Context) (map[string]interface{}, error) {
+	if statsDB, ok := db.DB.(ycsb.StatsDB); ok {
+		return statsDB.Stats(ctx)
+	}
+	return nil, nil
+}
+
+func (db DbWrapper) DiskSize(ctx context.Context) (int64, error) {
+	sizeDB, ok := db.DB.(ycsb.SizeDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the SizeDB interface", db.DB)
+	}
+	return sizeDB.DiskSize(ctx)
+}
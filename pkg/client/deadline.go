@@ -0,0 +1,87 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// errMaxExecutionTime is the cause of a run stopped by maxexecutiontime.
+var errMaxExecutionTime = errors.New("the maximum execution time is reached")
+
+// startDeadline stops the run once it ran for maxexecutiontime seconds, the
+// returned func stops the timer.
+func startDeadline(p *properties.Properties, cancel context.CancelCauseFunc) func() bool {
+	seconds := p.GetInt64(prop.MaxExecutiontime, 0)
+	if seconds <= 0 {
+		return func() bool { return false }
+	}
+	t := time.AfterFunc(time.Duration(seconds)*time.Second, func() {
+		fmt.Printf("[DEADLINE] stopping the run after %ds\n", seconds)
+		cancel(errMaxExecutionTime)
+	})
+	return t.Stop
+}
+
+// workerGroup waits for the workers, but only up to the grace period once
+// the run is stopped, so an operation stuck in the database doesn't keep the
+// measurements and the artifacts from being written.
+type workerGroup struct {
+	wg      sync.WaitGroup
+	running int64
+}
+
+func (g *workerGroup) add(n int) {
+	g.wg.Add(n)
+	atomic.AddInt64(&g.running, int64(n))
+}
+
+func (g *workerGroup) done() {
+	atomic.AddInt64(&g.running, -1)
+	g.wg.Done()
+}
+
+// wait returns the number of workers left behind.
+func (g *workerGroup) wait(ctx context.Context, grace time.Duration) int64 {
+	done := make(chan struct{})
+	go func() {
+		g.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+	}
+
+	t := time.NewTimer(grace)
+	defer t.Stop()
+	select {
+	case <-done:
+		return 0
+	case <-t.C:
+	}
+	stuck := atomic.LoadInt64(&g.running)
+	fmt.Printf("[DEADLINE] %d workers are still in an operation %s after the run stopped, they are left behind\n", stuck, grace)
+	return stuck
+}
@@ -0,0 +1,152 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// energyPoll is how often the energy counters are read, often enough to never
+// miss a wrap around of a counter.
+const energyPoll = time.Second
+
+// energyZone is a RAPL energy counter of the powercap framework.
+type energyZone struct {
+	name string
+	// path is the energy_uj file of the zone.
+	path string
+	// max is the value the counter wraps around at.
+	max uint64
+
+	last  uint64
+	total uint64
+}
+
+func (z *energyZone) read() (uint64, error) {
+	data, err := os.ReadFile(z.path)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(strings.TrimSpace(string(data)), 10, 64)
+}
+
+// sample adds the microjoules used since the last sample to the total.
+func (z *energyZone) sample() {
+	v, err := z.read()
+	if err != nil {
+		return
+	}
+	if v >= z.last {
+		z.total += v - z.last
+	} else {
+		z.total += z.max - z.last + v
+	}
+	z.last = v
+}
+
+// energyMeter measures the energy used by the CPU packages during the measured
+// window. Other processes of the host are included, so the numbers are only
+// meaningful on an otherwise idle machine.
+type energyMeter struct {
+	zones []*energyZone
+
+	started  bool
+	start    time.Time
+	startOps int64
+	elapsed  time.Duration
+	ops      int64
+}
+
+func newEnergyMeter(p *properties.Properties) *energyMeter {
+	if !p.GetBool(prop.Energy, prop.EnergyDefault) {
+		return nil
+	}
+
+	zones, err := energyZones()
+	if err == nil && len(zones) == 0 {
+		err = fmt.Errorf("no RAPL zones")
+	}
+	if err != nil {
+		fmt.Printf("[ENERGY] the energy counters are not available, %v\n", err)
+		return nil
+	}
+	return &energyMeter{zones: zones}
+}
+
+// begin is called when the measured window starts.
+func (m *energyMeter) begin() {
+	for _, z := range m.zones {
+		z.last, _ = z.read()
+	}
+	m.started = true
+	m.start = time.Now()
+	m.startOps = atomic.LoadInt64(&completedOps)
+}
+
+func (m *energyMeter) sample() {
+	for _, z := range m.zones {
+		z.sample()
+	}
+}
+
+// end is called when the measured window ends.
+func (m *energyMeter) end() {
+	m.sample()
+	m.elapsed = time.Since(m.start)
+	m.ops = atomic.LoadInt64(&completedOps) - m.startOps
+}
+
+// joules returns the energy used by the CPU packages, psys covers the whole
+// platform so it's only used if there is no package zone.
+func (m *energyMeter) joules() float64 {
+	var packages, psys uint64
+	for _, z := range m.zones {
+		if strings.HasPrefix(z.name, "package") {
+			packages += z.total
+		} else {
+			psys += z.total
+		}
+	}
+	if packages == 0 {
+		return float64(psys) / 1e6
+	}
+	return float64(packages) / 1e6
+}
+
+func (m *energyMeter) report() {
+	if !m.started {
+		return
+	}
+
+	for _, z := range m.zones {
+		fmt.Printf("[ENERGY] %s: %.1f J\n", z.name, float64(z.total)/1e6)
+	}
+	joules := m.joules()
+	if joules == 0 {
+		fmt.Println("[ENERGY] no energy used, the counters are not updated")
+		return
+	}
+	fmt.Printf("[ENERGY] %.1f J in %s, %.1f W, %.1f ops/J\n",
+		joules, m.elapsed.Round(time.Millisecond), joules/m.elapsed.Seconds(), float64(m.ops)/joules)
+	measurement.SetVariable("joules", joules)
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+
+package client
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// energyZones returns the top level RAPL zones, the CPU packages and psys on
+// some machines. The subzones are part of their package so they are skipped.
+// The counters are only readable by root since Linux 5.10.
+func energyZones() ([]*energyZone, error) {
+	dirs, err := filepath.Glob("/sys/class/powercap/intel-rapl:*")
+	if err != nil {
+		return nil, err
+	}
+
+	var zones []*energyZone
+	for _, dir := range dirs {
+		if strings.Count(filepath.Base(dir), ":") != 1 {
+			continue
+		}
+		name, err := os.ReadFile(filepath.Join(dir, "name"))
+		if err != nil {
+			return nil, err
+		}
+		maxRange, err := os.ReadFile(filepath.Join(dir, "max_energy_range_uj"))
+		if err != nil {
+			return nil, err
+		}
+		z := &energyZone{
+			name: strings.TrimSpace(string(name)),
+			path: filepath.Join(dir, "energy_uj"),
+		}
+		if z.max, err = strconv.ParseUint(strings.TrimSpace(string(maxRange)), 10, 64); err != nil {
+			return nil, err
+		}
+		if _, err = z.read(); err != nil {
+			return nil, err
+		}
+		zones = append(zones, z)
+	}
+	return zones, nil
+}
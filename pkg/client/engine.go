@@ -0,0 +1,45 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// OutputEngineStats prints the statistics of the engine after the
+// measurements, if the database reports them.
+func OutputEngineStats(ctx context.Context, db ycsb.DB) {
+	statsDB, ok := db.(ycsb.StatsDB)
+	if !ok {
+		return
+	}
+	stats, err := statsDB.Stats(ctx)
+	if err != nil {
+		fmt.Printf("[ENGINE] get the engine stats failed %v\n", err)
+		return
+	}
+
+	names := make([]string, 0, len(stats))
+	for name := range stats {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Printf("[ENGINE] %s=%v\n", name, stats[name])
+	}
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// Export writes the rows of the table to a spill file in spill.dir, which
+// the ingest command can load into any database. The keys are hashed with
+// export.hmac_key and the values redacted with export.redact, so a data set
+// derived from production data can be shared. It returns the number of
+// exported rows.
+func Export(ctx context.Context, p *properties.Properties, db ycsb.DB) (int64, error) {
+	iterateDB, ok := db.(ycsb.IterateDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the IterateDB interface", db)
+	}
+
+	dir := p.GetString(prop.SpillDir, prop.SpillDirDefault)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+	if err := util.RemoveSpillFiles(dir); err != nil {
+		return 0, err
+	}
+	path := filepath.Join(dir, "0"+util.SpillFileExt)
+	w, err := util.NewSpillWriter(path)
+	if err != nil {
+		return 0, err
+	}
+
+	anonymizer := util.NewAnonymizer([]byte(p.GetString(prop.ExportHMACKey, "")),
+		p.GetBool(prop.ExportRedact, prop.ExportRedactDefault))
+	codec := util.NewRowCodec(p)
+	table := p.GetString(prop.TableName, prop.TableNameDefault)
+
+	ctx = db.InitThread(ctx, 0, 1)
+	defer db.CleanupThread(ctx)
+
+	var rows int64
+	var buf []byte
+	err = iterateDB.Iterate(ctx, table, func(key string, values map[string][]byte) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		row, err := codec.Encode(buf, anonymizer.Values(key, values))
+		if err != nil {
+			return err
+		}
+		buf = row
+		if err := w.Write(anonymizer.Key(key), row); err != nil {
+			return err
+		}
+		rows++
+		return nil
+	})
+	if cerr := w.Close(); err == nil {
+		err = cerr
+	}
+	return rows, err
+}
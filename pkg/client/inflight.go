@@ -0,0 +1,83 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"sync/atomic"
+)
+
+// inFlight is the gauge of the operations of the running client.
+var inFlight inFlightGauge
+
+// The readers of the gauge, each one has its own peak.
+const (
+	inFlightLog = iota
+	inFlightDashboard
+	inFlightReaders
+)
+
+// inFlightGauge counts the operations submitted but not completed. Saturated
+// workers run all their threads with nothing behind the target schedule,
+// while an overloaded open loop also falls behind the schedule or fills the
+// committer queue.
+type inFlightGauge struct {
+	// running are the operations the workers are running, a batch counts
+	// as its size.
+	running int64
+	peaks   [inFlightReaders]int64
+	// behind are the operations the workers are behind the target schedule.
+	behind int64
+	queue  atomic.Pointer[chan committerItem]
+}
+
+// inFlightSample is the state of the gauge in an interval.
+type inFlightSample struct {
+	Running int64 `json:"running"`
+	Peak    int64 `json:"peak"`
+	Queued  int64 `json:"queued"`
+	Behind  int64 `json:"behind"`
+}
+
+func (g *inFlightGauge) begin(n int64) {
+	running := atomic.AddInt64(&g.running, n)
+	for i := range g.peaks {
+		for {
+			peak := atomic.LoadInt64(&g.peaks[i])
+			if running <= peak || atomic.CompareAndSwapInt64(&g.peaks[i], peak, running) {
+				break
+			}
+		}
+	}
+}
+
+func (g *inFlightGauge) end(n int64) {
+	atomic.AddInt64(&g.running, -n)
+}
+
+// sample returns the state of the gauge and starts a new interval for the
+// reader.
+func (g *inFlightGauge) sample(reader int) inFlightSample {
+	s := inFlightSample{
+		Running: atomic.LoadInt64(&g.running),
+		Behind:  atomic.LoadInt64(&g.behind),
+	}
+	s.Peak = atomic.SwapInt64(&g.peaks[reader], s.Running)
+	if s.Peak < s.Running {
+		s.Peak = s.Running
+	}
+	if queue := g.queue.Load(); queue != nil {
+		s.Queued = int64(len(*queue))
+	}
+	return s
+}
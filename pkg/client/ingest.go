@@ -0,0 +1,147 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// Ingest streams the spill files written by the spill binding into the db.
+// Every file is ingested by its own goroutine, at most threadcount at a time,
+// in batches of batch.size rows. Bindings implementing ycsb.BulkIngestDB get
+// the encoded rows as they are, the others get decoded rows through
+// BatchInsert. It returns the number of ingested rows.
+func Ingest(ctx context.Context, p *properties.Properties, db DbWrapper) (int64, error) {
+	dir := p.GetString(prop.SpillDir, prop.SpillDirDefault)
+	files, err := filepath.Glob(filepath.Join(dir, "*"+util.SpillFileExt))
+	if err != nil {
+		return 0, err
+	}
+	if len(files) == 0 {
+		return 0, fmt.Errorf("no spill files found in %s", dir)
+	}
+
+	table := p.GetString(prop.TableName, prop.TableNameDefault)
+	batchSize := p.GetInt(prop.BatchSize, prop.DefaultBatchSize)
+	threadCount := p.GetInt(prop.ThreadCount, 1)
+	bulk := canIngest(db.DB)
+	codec := util.NewRowCodec(p)
+
+	var (
+		wg       sync.WaitGroup
+		rows     int64
+		errOnce  sync.Once
+		firstErr error
+	)
+	sem := make(chan struct{}, threadCount)
+	for i, file := range files {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(threadID int, file string) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+
+			ctx := db.InitThread(ctx, threadID, len(files))
+			defer db.CleanupThread(ctx)
+
+			n, err := ingestFile(ctx, db, codec, bulk, table, batchSize, file)
+			atomic.AddInt64(&rows, n)
+			if err != nil {
+				errOnce.Do(func() {
+					firstErr = fmt.Errorf("ingest %s failed %v", file, err)
+				})
+			}
+		}(i, file)
+	}
+	wg.Wait()
+
+	return atomic.LoadInt64(&rows), firstErr
+}
+
+func ingestFile(ctx context.Context, db DbWrapper, codec *util.RowCodec, bulk bool, table string, batchSize int, file string) (int64, error) {
+	r, err := util.NewSpillReader(file)
+	if err != nil {
+		return 0, err
+	}
+	defer r.Close()
+
+	var (
+		n    int64
+		keys = make([]string, 0, batchSize)
+		rows = make([][]byte, 0, batchSize)
+	)
+	flush := func() error {
+		if len(keys) == 0 {
+			return nil
+		}
+
+		var err error
+		if bulk {
+			err = db.IngestRows(ctx, table, keys, rows)
+		} else {
+			values := make([]map[string][]byte, len(rows))
+			for i, row := range rows {
+				if values[i], err = codec.Decode(row, nil); err != nil {
+					return err
+				}
+			}
+			if len(keys) == 1 {
+				err = db.Insert(ctx, table, keys[0], values[0])
+			} else {
+				err = db.BatchInsert(ctx, table, keys, values)
+			}
+		}
+		if err != nil {
+			return err
+		}
+
+		n += int64(len(keys))
+		keys, rows = keys[:0], rows[:0]
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return n, ctx.Err()
+		default:
+		}
+
+		key, row, err := r.Next()
+		if err == io.EOF {
+			return n, flush()
+		} else if err != nil {
+			return n, err
+		}
+
+		keys = append(keys, key)
+		rows = append(rows, row)
+		if len(keys) >= batchSize {
+			if err := flush(); err != nil {
+				return n, err
+			}
+		}
+	}
+}
@@ -0,0 +1,120 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// memoryLimiter samples the process RSS and reacts once it goes above the
+// configured ceiling, either by aborting the run or by flagging it.
+type memoryLimiter struct {
+	maxRSS   uint64
+	interval time.Duration
+	abort    bool
+	dir      string
+
+	breached int32
+	peakRSS  uint64
+}
+
+func newMemoryLimiter(p *properties.Properties) *memoryLimiter {
+	maxRSSMB := p.GetInt64(prop.LimitsMaxRSSMB, prop.LimitsMaxRSSMBDefault)
+	if maxRSSMB <= 0 {
+		return nil
+	}
+
+	action := p.GetString(prop.LimitsAction, prop.LimitsActionDefault)
+	switch action {
+	case "abort", "flag":
+	default:
+		util.Fatalf("unknown %s %s", prop.LimitsAction, action)
+	}
+
+	return &memoryLimiter{
+		maxRSS:   uint64(maxRSSMB) << 20,
+		interval: p.GetParsedDuration(prop.LimitsCheckInterval, prop.LimitsCheckIntervalDefault),
+		abort:    action == "abort",
+		dir:      p.GetString(prop.OutputDir, prop.OutputDirDefault),
+	}
+}
+
+func (m *memoryLimiter) run(ctx context.Context, cancel context.CancelCauseFunc) {
+	t := time.NewTicker(m.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		rss := util.ProcessRSS()
+		if rss > atomic.LoadUint64(&m.peakRSS) {
+			atomic.StoreUint64(&m.peakRSS, rss)
+		}
+		if rss <= m.maxRSS || !atomic.CompareAndSwapInt32(&m.breached, 0, 1) {
+			continue
+		}
+
+		fmt.Printf("[LIMIT] process RSS %d MB exceeds %s=%d MB\n", rss>>20, prop.LimitsMaxRSSMB, m.maxRSS>>20)
+		if path, err := m.writeHeapProfile(); err != nil {
+			fmt.Printf("[LIMIT] write heap profile failed %v\n", err)
+		} else {
+			fmt.Printf("[LIMIT] heap profile written to %s\n", path)
+		}
+
+		if m.abort {
+			fmt.Println("[LIMIT] aborting the run")
+			cancel(fmt.Errorf("process RSS %d MB exceeds %s=%d MB", rss>>20, prop.LimitsMaxRSSMB, m.maxRSS>>20))
+			return
+		}
+	}
+}
+
+func (m *memoryLimiter) writeHeapProfile() (string, error) {
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(m.dir, fmt.Sprintf("heap-rss-%d.pprof", time.Now().Unix()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	return path, pprof.WriteHeapProfile(f)
+}
+
+// report prints the limit outcome at the end of the run.
+func (m *memoryLimiter) report() {
+	if atomic.LoadInt32(&m.breached) == 0 {
+		return
+	}
+
+	fmt.Printf("[LIMIT] FAILED: memory limit %d MB breached, peak RSS %d MB\n",
+		m.maxRSS>>20, atomic.LoadUint64(&m.peakRSS)>>20)
+}
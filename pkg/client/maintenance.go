@@ -0,0 +1,124 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// recoveryPoll is how often the throughput is sampled after a maintenance
+// window, the throughput is the one of the last second.
+const recoveryPoll = 100 * time.Millisecond
+
+// maintenance lowers the target or pauses the workers during periodic
+// windows, like deployments throttling the traffic during backups, and
+// measures how long the throughput takes to recover afterwards.
+type maintenance struct {
+	interval time.Duration
+	duration time.Duration
+	target   int64
+	recovery float64
+}
+
+func newMaintenance(p *properties.Properties) *maintenance {
+	interval := p.GetParsedDuration(prop.MaintenanceInterval, prop.MaintenanceIntervalDefault)
+	if interval <= 0 {
+		return nil
+	}
+
+	m := &maintenance{
+		interval: interval,
+		duration: p.GetParsedDuration(prop.MaintenanceDuration, prop.MaintenanceDurationDefault),
+		target:   p.GetInt64(prop.MaintenanceTarget, prop.MaintenanceTargetDefault),
+		recovery: p.GetFloat64(prop.MaintenanceRecovery, prop.MaintenanceRecoveryDefault),
+	}
+	if m.target == 0 {
+		m.target = targetPaused
+	}
+	return m
+}
+
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-time.After(time.Until(t)):
+		return true
+	}
+}
+
+func (m *maintenance) run(ctx context.Context) {
+	next := time.Now().Add(m.interval)
+	for i := 1; ; i++ {
+		start, startOps := time.Now(), atomic.LoadInt64(&completedOps)
+		if !sleepUntil(ctx, next) {
+			return
+		}
+		baseline := float64(atomic.LoadInt64(&completedOps)-startOps) / time.Since(start).Seconds()
+
+		ops, _ := target.get()
+		measurement.Annotate(fmt.Sprintf("maintenance window %d started", i))
+		target.set(m.target)
+		if !sleepUntil(ctx, time.Now().Add(m.duration)) {
+			return
+		}
+		target.set(ops)
+		measurement.Annotate(fmt.Sprintf("maintenance window %d ended", i))
+
+		end := time.Now()
+		next = end.Add(m.interval)
+		m.waitRecovery(ctx, i, baseline, end, next)
+	}
+}
+
+// waitRecovery waits until the throughput of the last second is back to the
+// recovery fraction of the baseline, at most until the deadline.
+func (m *maintenance) waitRecovery(ctx context.Context, window int, baseline float64, end time.Time, deadline time.Time) {
+	samples := []int64{atomic.LoadInt64(&completedOps)}
+	keep := int(time.Second / recoveryPoll)
+	t := time.NewTicker(recoveryPoll)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-t.C:
+			samples = append(samples, atomic.LoadInt64(&completedOps))
+			if len(samples) > keep+1 {
+				samples = samples[1:]
+			}
+			elapsed := time.Duration(len(samples)-1) * recoveryPoll
+			rate := float64(samples[len(samples)-1]-samples[0]) / elapsed.Seconds()
+			if len(samples) == keep+1 && rate >= m.recovery*baseline {
+				lan := now.Sub(end)
+				measurement.Measure("RECOVERY", end, lan)
+				fmt.Printf("[MAINTENANCE] window %d: throughput back to %.0f ops/sec of %.0f ops/sec after %s\n",
+					window, rate, baseline, lan.Round(time.Millisecond))
+				return
+			}
+			if now.After(deadline) {
+				fmt.Printf("[MAINTENANCE] window %d: throughput did not recover to %.0f%% of %.0f ops/sec before the next window\n",
+					window, m.recovery*100, baseline)
+				return
+			}
+		}
+	}
+}
@@ -0,0 +1,227 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// manifestFile is the file in the output directory describing the loaded data
+// set and the last run.
+const manifestFile = "manifest.json"
+
+// ManifestTable is the fingerprint of a loaded table.
+type ManifestTable struct {
+	Name        string `json:"name"`
+	Records     int64  `json:"records"`
+	Fingerprint string `json:"fingerprint"`
+}
+
+// ManifestEnvironment describes the machine of a run.
+type ManifestEnvironment struct {
+	OS         string `json:"os"`
+	Arch       string `json:"arch"`
+	Kernel     string `json:"kernel,omitempty"`
+	Hostname   string `json:"hostname"`
+	CPUs       int    `json:"cpus"`
+	GoMaxProcs int    `json:"gomaxprocs"`
+}
+
+// ManifestRun describes a load or run with everything needed to execute it
+// again: the binary, the effective properties, which include the seed, and
+// the environment.
+type ManifestRun struct {
+	Started time.Time `json:"started"`
+	Command string    `json:"command"`
+	DB      string    `json:"db"`
+	BuildInfo
+	Seed int64 `json:"seed"`
+	// WriteOrdering is the prop.WriteOrdering guarantee of the run.
+	WriteOrdering string              `json:"write_ordering"`
+	Properties    map[string]string   `json:"properties"`
+	Environment   ManifestEnvironment `json:"environment"`
+}
+
+// Manifest describes the data set written by the load phase, so the later
+// phases can check they run on the same data, and the last load or run.
+type Manifest struct {
+	Created time.Time       `json:"created"`
+	Tables  []ManifestTable `json:"tables,omitempty"`
+	Run     *ManifestRun    `json:"run,omitempty"`
+}
+
+func manifestPath(p *properties.Properties) string {
+	return filepath.Join(p.GetString(prop.OutputDir, prop.OutputDirDefault), manifestFile)
+}
+
+// ReadManifest reads the manifest file.
+func ReadManifest(path string) (*Manifest, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, fmt.Errorf("parse %s failed %v", path, err)
+	}
+	return &m, nil
+}
+
+func writeManifest(path string, m *Manifest) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+func currentEnvironment() ManifestEnvironment {
+	env := ManifestEnvironment{
+		OS:         runtime.GOOS,
+		Arch:       runtime.GOARCH,
+		CPUs:       runtime.NumCPU(),
+		GoMaxProcs: runtime.GOMAXPROCS(0),
+	}
+	env.Hostname, _ = os.Hostname()
+	if release, err := os.ReadFile("/proc/sys/kernel/osrelease"); err == nil {
+		env.Kernel = strings.TrimSpace(string(release))
+	}
+	return env
+}
+
+// WriteRunManifest records the load or run about to start in the manifest of
+// the output directory, the fingerprints of the data set are kept.
+func WriteRunManifest(p *properties.Properties, db string) error {
+	path := manifestPath(p)
+	m, err := ReadManifest(path)
+	if os.IsNotExist(err) {
+		m, err = &Manifest{Created: time.Now()}, nil
+	}
+	if err != nil {
+		return err
+	}
+
+	run := &ManifestRun{
+		Started:       time.Now(),
+		Command:       p.GetString(prop.Command, ""),
+		DB:            db,
+		BuildInfo:     CurrentBuild(),
+		Seed:          p.GetInt64(prop.RandomSeed, 0),
+		WriteOrdering: p.GetString(prop.WriteOrdering, prop.WriteOrderingDefault),
+		Properties:    p.Map(),
+		Environment:   currentEnvironment(),
+	}
+	m.Run = run
+	if err := writeManifest(path, m); err != nil {
+		return err
+	}
+	fmt.Printf("[MANIFEST] the %s is recorded in %s\n", run.Command, path)
+	return nil
+}
+
+// ComputeFingerprint iterates over all the rows of the table.
+func ComputeFingerprint(ctx context.Context, db ycsb.DB, table string) (util.Fingerprint, error) {
+	var f util.Fingerprint
+	iterateDB, ok := db.(ycsb.IterateDB)
+	if !ok {
+		return f, fmt.Errorf("the %T does't implement the IterateDB interface", db)
+	}
+
+	err := iterateDB.Iterate(ctx, table, func(key string, values map[string][]byte) error {
+		f.Add(key, values)
+		return nil
+	})
+	return f, err
+}
+
+func computeManifest(ctx context.Context, p *properties.Properties, db ycsb.DB) (*Manifest, error) {
+	m := &Manifest{Created: time.Now()}
+	for _, table := range util.TableNames(p) {
+		start := time.Now()
+		f, err := ComputeFingerprint(ctx, db, table)
+		if err != nil {
+			return nil, fmt.Errorf("fingerprint table %s failed %v", table, err)
+		}
+		fmt.Printf("[FINGERPRINT] table %s: %d records, fingerprint %s, takes %s\n",
+			table, f.Records, f, time.Now().Sub(start))
+		m.Tables = append(m.Tables, ManifestTable{Name: table, Records: f.Records, Fingerprint: f.String()})
+	}
+	return m, nil
+}
+
+// WriteManifest fingerprints the loaded tables and writes the manifest to the
+// output directory, the recorded run is kept.
+func WriteManifest(ctx context.Context, p *properties.Properties, db ycsb.DB) error {
+	m, err := computeManifest(ctx, p, db)
+	if err != nil {
+		return err
+	}
+
+	path := manifestPath(p)
+	if old, err := ReadManifest(path); err == nil {
+		m.Run = old.Run
+	}
+	if err := writeManifest(path, m); err != nil {
+		return err
+	}
+	fmt.Printf("[FINGERPRINT] manifest is written to %s\n", path)
+	return nil
+}
+
+// VerifyManifest fingerprints the tables again and compares them with the
+// manifest in the output directory.
+func VerifyManifest(ctx context.Context, p *properties.Properties, db ycsb.DB) error {
+	path := manifestPath(p)
+	expected, err := ReadManifest(path)
+	if err != nil {
+		return err
+	}
+
+	m, err := computeManifest(ctx, p, db)
+	if err != nil {
+		return err
+	}
+
+	want := make(map[string]ManifestTable, len(expected.Tables))
+	for _, t := range expected.Tables {
+		want[t.Name] = t
+	}
+	for _, got := range m.Tables {
+		t, ok := want[got.Name]
+		if !ok {
+			return fmt.Errorf("table %s is not in %s", got.Name, path)
+		}
+		if t.Fingerprint != got.Fingerprint {
+			return fmt.Errorf("table %s fingerprint %s doesn't match %s loaded at %s",
+				got.Name, got.Fingerprint, t.Fingerprint, expected.Created.Format(time.RFC3339))
+		}
+	}
+	fmt.Printf("[FINGERPRINT] the data set matches %s\n", path)
+	return nil
+}
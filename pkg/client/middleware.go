@@ -0,0 +1,260 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// MiddlewareDB runs the operations through a middleware before the DB.
+type MiddlewareDB struct {
+	DB ycsb.DB
+
+	m ycsb.Middleware
+}
+
+// NewMiddlewareDB wraps the db with the middlewares listed in db.middleware,
+// the first one sees the operations first, and the trace one with trace.rate.
+// It returns the db unchanged if the list is empty.
+func NewMiddlewareDB(p *properties.Properties, db ycsb.DB) (ycsb.DB, error) {
+	names := strings.Split(p.GetString(prop.DBMiddleware, prop.DBMiddlewareDefault), ",")
+	if p.GetInt64(prop.TraceRate, prop.TraceRateDefault) > 0 && !hasMiddleware(names, "trace") {
+		names = append(names, "trace")
+	}
+	for i := len(names) - 1; i >= 0; i-- {
+		name := strings.TrimSpace(names[i])
+		if name == "" {
+			continue
+		}
+
+		creator := ycsb.GetMiddlewareCreator(name)
+		if creator == nil {
+			return nil, fmt.Errorf("middleware %s is not registered", name)
+		}
+		m, err := creator.Create(p)
+		if err != nil {
+			return nil, fmt.Errorf("create middleware %s failed %v", name, err)
+		}
+		db = &MiddlewareDB{DB: db, m: m}
+	}
+	return db, nil
+}
+
+func hasMiddleware(names []string, name string) bool {
+	for _, n := range names {
+		if strings.TrimSpace(n) == name {
+			return true
+		}
+	}
+	return false
+}
+
+// canIngest reports whether the db writes encoded rows itself, looking
+// through the middlewares which pass IngestRows on.
+func canIngest(db ycsb.DB) bool {
+	for {
+		m, ok := db.(*MiddlewareDB)
+		if !ok {
+			break
+		}
+		db = m.DB
+	}
+	_, ok := db.(ycsb.BulkIngestDB)
+	return ok
+}
+
+func firstResult(res []map[string][]byte) map[string][]byte {
+	if len(res) == 0 {
+		return nil
+	}
+	return res[0]
+}
+
+func (db *MiddlewareDB) Close() error {
+	return db.DB.Close()
+}
+
+func (db *MiddlewareDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	return db.DB.InitThread(ctx, threadID, threadCount)
+}
+
+func (db *MiddlewareDB) CleanupThread(ctx context.Context) {
+	db.DB.CleanupThread(ctx)
+}
+
+func (db *MiddlewareDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	call := &ycsb.Call{Op: "READ", Table: table, Keys: []string{key}, Fields: fields}
+	err := db.m.Handle(ctx, call, func(ctx context.Context) error {
+		m, err := db.DB.Read(ctx, table, key, fields)
+		call.Result = []map[string][]byte{m}
+		return err
+	})
+	return firstResult(call.Result), err
+}
+
+func (db *MiddlewareDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	call := &ycsb.Call{Op: "SCAN", Table: table, Keys: []string{startKey}, Fields: fields, Count: count}
+	err := db.m.Handle(ctx, call, func(ctx context.Context) error {
+		var err error
+		call.Result, err = db.DB.Scan(ctx, table, startKey, count, fields)
+		return err
+	})
+	return call.Result, err
+}
+
+func (db *MiddlewareDB) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db.DB)
+	}
+	var keys []string
+	call := &ycsb.Call{Op: "SCAN", Table: table, Keys: []string{startKey}, Fields: fields, Count: count}
+	err := db.m.Handle(ctx, call, func(ctx context.Context) error {
+		var err error
+		keys, call.Result, err = scanDB.ScanKeys(ctx, table, startKey, count, fields)
+		return err
+	})
+	return keys, call.Result, err
+}
+
+func (db *MiddlewareDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	call := &ycsb.Call{Op: "UPDATE", Table: table, Keys: []string{key}, Values: []map[string][]byte{values}}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		return db.DB.Update(ctx, table, key, values)
+	})
+}
+
+func (db *MiddlewareDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	call := &ycsb.Call{Op: "INSERT", Table: table, Keys: []string{key}, Values: []map[string][]byte{values}}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		return db.DB.Insert(ctx, table, key, values)
+	})
+}
+
+func (db *MiddlewareDB) Delete(ctx context.Context, table string, key string) error {
+	call := &ycsb.Call{Op: "DELETE", Table: table, Keys: []string{key}}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		return db.DB.Delete(ctx, table, key)
+	})
+}
+
+func (db *MiddlewareDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	call := &ycsb.Call{Op: "BATCH_READ", Table: table, Keys: keys, Fields: fields}
+	err := db.m.Handle(ctx, call, func(ctx context.Context) error {
+		if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+			var err error
+			call.Result, err = batchDB.BatchRead(ctx, table, keys, fields)
+			return err
+		}
+		call.Result = make([]map[string][]byte, 0, len(keys))
+		for _, key := range keys {
+			m, err := db.DB.Read(ctx, table, key, fields)
+			if err != nil {
+				return err
+			}
+			call.Result = append(call.Result, m)
+		}
+		return nil
+	})
+	return call.Result, err
+}
+
+func (db *MiddlewareDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	call := &ycsb.Call{Op: "BATCH_UPDATE", Table: table, Keys: keys, Values: values}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+			return batchDB.BatchUpdate(ctx, table, keys, values)
+		}
+		for i := range keys {
+			if err := db.DB.Update(ctx, table, keys[i], values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *MiddlewareDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	call := &ycsb.Call{Op: "BATCH_INSERT", Table: table, Keys: keys, Values: values}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+			return batchDB.BatchInsert(ctx, table, keys, values)
+		}
+		for i := range keys {
+			if err := db.DB.Insert(ctx, table, keys[i], values[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *MiddlewareDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	call := &ycsb.Call{Op: "BATCH_DELETE", Table: table, Keys: keys}
+	return db.m.Handle(ctx, call, func(ctx context.Context) error {
+		if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+			return batchDB.BatchDelete(ctx, table, keys)
+		}
+		for _, key := range keys {
+			if err := db.DB.Delete(ctx, table, key); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (db *MiddlewareDB) IngestRows(ctx context.Context, table string, keys []string, rows [][]byte) error {
+	ingestDB, ok := db.DB.(ycsb.BulkIngestDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the BulkIngestDB interface", db.DB)
+	}
+	return ingestDB.IngestRows(ctx, table, keys, rows)
+}
+
+func (db *MiddlewareDB) Analyze(ctx context.Context, table string) error {
+	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
+		return analyzeDB.Analyze(ctx, table)
+	}
+	return nil
+}
+
+func (db *MiddlewareDB) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	iterateDB, ok := db.DB.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db.DB)
+	}
+	return iterateDB.Iterate(ctx, table, fn)
+}
+
+func (db *MiddlewareDB) Stats(ctx context.Context) (map[string]interface{}, error) {
+	if statsDB, ok := db.DB.(ycsb.StatsDB); ok {
+		return statsDB.Stats(ctx)
+	}
+	return nil, nil
+}
+
+func (db *MiddlewareDB) DiskSize(ctx context.Context) (int64, error) {
+	sizeDB, ok := db.DB.(ycsb.SizeDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the SizeDB interface", db.DB)
+	}
+	return sizeDB.DiskSize(ctx)
+}
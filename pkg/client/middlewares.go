@@ -0,0 +1,237 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+var errInjectedFault = errors.New("injected fault")
+
+// traceMiddleware samples the operations for the trace file.
+type traceMiddleware struct{}
+
+func (traceMiddleware) Handle(ctx context.Context, call *ycsb.Call, next func(ctx context.Context) error) error {
+	ctx, tr := measurement.StartTrace(ctx, call.Op, call.Table, call.Keys...)
+	fields := len(call.Fields)
+	if len(call.Keys) == 1 && len(call.Values) == 1 {
+		fields = len(call.Values[0])
+	}
+	tr.AddFields(fields)
+	tr.AddWrite(call.Values...)
+
+	err := next(ctx)
+	tr.AddRead(call.Result...)
+	tr.Finish(err)
+	return err
+}
+
+type traceMiddlewareCreator struct{}
+
+func (traceMiddlewareCreator) Create(_ *properties.Properties) (ycsb.Middleware, error) {
+	return traceMiddleware{}, nil
+}
+
+// timeoutMiddleware gives every operation a deadline. Bindings that don't
+// check the context still finish the operation, it fails once it returns
+// like a client side timeout, a write may have been applied nevertheless.
+type timeoutMiddleware struct {
+	timeout time.Duration
+}
+
+func (m timeoutMiddleware) Handle(ctx context.Context, call *ycsb.Call, next func(ctx context.Context) error) error {
+	ctx, cancel := context.WithTimeout(ctx, m.timeout)
+	defer cancel()
+
+	err := next(ctx)
+	if err == nil && ctx.Err() == context.DeadlineExceeded {
+		return fmt.Errorf("%s took longer than %s: %w", call.Op, m.timeout, ctx.Err())
+	}
+	return err
+}
+
+type timeoutMiddlewareCreator struct{}
+
+func (timeoutMiddlewareCreator) Create(p *properties.Properties) (ycsb.Middleware, error) {
+	timeout := p.GetParsedDuration(prop.MiddlewareTimeout, prop.MiddlewareTimeoutDefault)
+	if timeout <= 0 {
+		return nil, fmt.Errorf("%s must be positive", prop.MiddlewareTimeout)
+	}
+	return timeoutMiddleware{timeout: timeout}, nil
+}
+
+// retryMiddleware retries failed operations with a linear backoff.
+type retryMiddleware struct {
+	limit   int64
+	backoff time.Duration
+}
+
+func (m retryMiddleware) Handle(ctx context.Context, call *ycsb.Call, next func(ctx context.Context) error) error {
+	for i := int64(1); ; i++ {
+		err := next(ctx)
+		if err == nil || i > m.limit {
+			return err
+		}
+
+		measurement.TraceFrom(ctx).Retry()
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(time.Duration(i) * m.backoff):
+		}
+	}
+}
+
+type retryMiddlewareCreator struct{}
+
+func (retryMiddlewareCreator) Create(p *properties.Properties) (ycsb.Middleware, error) {
+	return retryMiddleware{
+		limit:   p.GetInt64(prop.MiddlewareRetryLimit, prop.MiddlewareRetryLimitDefault),
+		backoff: p.GetParsedDuration(prop.MiddlewareRetryBackoff, prop.MiddlewareRetryBackoffDefault),
+	}, nil
+}
+
+// faultMiddleware delays the operations and fails some of them before they
+// reach the database.
+type faultMiddleware struct {
+	rate  float64
+	delay time.Duration
+}
+
+func (m faultMiddleware) Handle(ctx context.Context, call *ycsb.Call, next func(ctx context.Context) error) error {
+	if m.delay > 0 {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(m.delay):
+		}
+	}
+	if rand.Float64() < m.rate {
+		return errInjectedFault
+	}
+	return next(ctx)
+}
+
+type faultMiddlewareCreator struct{}
+
+func (faultMiddlewareCreator) Create(p *properties.Properties) (ycsb.Middleware, error) {
+	rate := p.GetFloat64(prop.MiddlewareFaultRate, prop.MiddlewareFaultRateDefault)
+	if rate < 0 || rate > 1 {
+		return nil, fmt.Errorf("%s must be between 0 and 1", prop.MiddlewareFaultRate)
+	}
+	return faultMiddleware{
+		rate:  rate,
+		delay: p.GetParsedDuration(prop.MiddlewareFaultDelay, prop.MiddlewareFaultDelayDefault),
+	}, nil
+}
+
+// cacheMiddleware answers reads from the rows it read before, every write
+// evicts the keys it writes. A read only fills the cache if no write
+// happened meanwhile, so it never caches a row older than a finished write.
+type cacheMiddleware struct {
+	size int
+
+	mu sync.Mutex
+	// rows are the read values by table and key, then by the read fields.
+	rows   map[string]map[string]map[string][]byte
+	writes int64
+}
+
+func (m *cacheMiddleware) Handle(ctx context.Context, call *ycsb.Call, next func(ctx context.Context) error) error {
+	switch call.Op {
+	case "READ":
+	case "SCAN", "BATCH_READ":
+		return next(ctx)
+	default:
+		err := next(ctx)
+		m.evict(call.Table, call.Keys)
+		return err
+	}
+
+	key := call.Table + "/" + call.Keys[0]
+	fields := strings.Join(call.Fields, ",")
+
+	m.mu.Lock()
+	if values, ok := m.rows[key][fields]; ok {
+		m.mu.Unlock()
+		call.Result = []map[string][]byte{copyValues(values)}
+		return nil
+	}
+	writes := m.writes
+	m.mu.Unlock()
+
+	err := next(ctx)
+	if err != nil || len(call.Result) == 0 || call.Result[0] == nil {
+		return err
+	}
+
+	values := copyValues(call.Result[0])
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.writes != writes {
+		return nil
+	}
+	if _, ok := m.rows[key]; !ok {
+		if len(m.rows) >= m.size {
+			for k := range m.rows {
+				delete(m.rows, k)
+				break
+			}
+		}
+		m.rows[key] = make(map[string]map[string][]byte)
+	}
+	m.rows[key][fields] = values
+	return nil
+}
+
+func (m *cacheMiddleware) evict(table string, keys []string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.writes++
+	for _, key := range keys {
+		delete(m.rows, table+"/"+key)
+	}
+}
+
+type cacheMiddlewareCreator struct{}
+
+func (cacheMiddlewareCreator) Create(p *properties.Properties) (ycsb.Middleware, error) {
+	size := p.GetInt(prop.MiddlewareCacheSize, prop.MiddlewareCacheSizeDefault)
+	if size <= 0 {
+		return nil, fmt.Errorf("%s must be positive", prop.MiddlewareCacheSize)
+	}
+	return &cacheMiddleware{
+		size: size,
+		rows: make(map[string]map[string]map[string][]byte),
+	}, nil
+}
+
+func init() {
+	ycsb.RegisterMiddlewareCreator("trace", traceMiddlewareCreator{})
+	ycsb.RegisterMiddlewareCreator("timeout", timeoutMiddlewareCreator{})
+	ycsb.RegisterMiddlewareCreator("retry", retryMiddlewareCreator{})
+	ycsb.RegisterMiddlewareCreator("fault", faultMiddlewareCreator{})
+	ycsb.RegisterMiddlewareCreator("cache", cacheMiddlewareCreator{})
+}
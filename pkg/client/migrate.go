@@ -0,0 +1,74 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// migrateBatch is the number of rows scanned and rewritten at once.
+const migrateBatch = 1000
+
+// Migrate inserts every row of the tables again, so the binding encodes them
+// in the current row format. It needs a binding returning the scanned keys,
+// with scans seeking from a missing start key, and returns the number of
+// rows rewritten.
+func Migrate(ctx context.Context, p *properties.Properties, db ycsb.DB) (int64, error) {
+	scanDB, ok := db.(ycsb.ScanKeysDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db)
+	}
+
+	ctx = db.InitThread(ctx, 0, 1)
+	defer db.CleanupThread(ctx)
+
+	var total int64
+	for _, table := range util.TableNames(p) {
+		var rows int64
+		// every batch starts at the last row of the previous one.
+		start, skip := "", false
+		for {
+			if err := ctx.Err(); err != nil {
+				return total, err
+			}
+
+			keys, values, err := scanDB.ScanKeys(ctx, table, start, migrateBatch, nil)
+			if err != nil {
+				return total, err
+			}
+			if skip && len(keys) > 0 && keys[0] == start {
+				keys, values = keys[1:], values[1:]
+			}
+			if len(keys) == 0 {
+				break
+			}
+
+			for i, key := range keys {
+				if err := db.Insert(ctx, table, key, values[i]); err != nil {
+					return total, err
+				}
+			}
+			rows += int64(len(keys))
+			total += int64(len(keys))
+			start, skip = keys[len(keys)-1], true
+		}
+		fmt.Printf("[MIGRATE] %d rows of table %s are in row format version %d\n", rows, table, util.RowFormatVersion)
+	}
+	return total, nil
+}
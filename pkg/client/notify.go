@@ -0,0 +1,200 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// notifyTimeout bounds the webhook request, so a dead endpoint doesn't hold
+// the end of the run.
+const notifyTimeout = 10 * time.Second
+
+// notifyErrorClasses is the number of error classes in the notification,
+// the most recent first.
+const notifyErrorClasses = 5
+
+// errorClassMax bounds the error classes kept, the errors of other classes
+// are counted together.
+const errorClassMax = 64
+
+var errorNumbers = regexp.MustCompile(`[0-9]+`)
+
+// errorClass counts the errors with the same message but their numbers, like
+// the keys or the addresses.
+type errorClass struct {
+	Class string    `json:"class"`
+	Count int64     `json:"count"`
+	Last  time.Time `json:"last"`
+}
+
+type errorClasses struct {
+	sync.Mutex
+	classes map[string]*errorClass
+}
+
+func classifyError(err error) string {
+	class := errorNumbers.ReplaceAllString(err.Error(), "N")
+	if len(class) > 200 {
+		class = class[:200]
+	}
+	return class
+}
+
+func (e *errorClasses) record(err error) {
+	class := classifyError(err)
+
+	e.Lock()
+	defer e.Unlock()
+
+	c, ok := e.classes[class]
+	if !ok {
+		if len(e.classes) >= errorClassMax {
+			class = "other errors"
+			c = e.classes[class]
+		}
+		if c == nil {
+			c = &errorClass{Class: class}
+			e.classes[class] = c
+		}
+	}
+	c.Count++
+	c.Last = time.Now()
+}
+
+// last returns the n error classes seen most recently.
+func (e *errorClasses) last(n int) []errorClass {
+	e.Lock()
+	defer e.Unlock()
+
+	classes := make([]errorClass, 0, len(e.classes))
+	for _, c := range e.classes {
+		classes = append(classes, *c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Last.After(classes[j].Last) })
+	if len(classes) > n {
+		classes = classes[:n]
+	}
+	return classes
+}
+
+// notification is posted as JSON to the webhook, text is what chat services
+// like Slack display.
+type notification struct {
+	Text     string       `json:"text"`
+	Status   string       `json:"status"`
+	Reason   string       `json:"reason,omitempty"`
+	Command  string       `json:"command"`
+	Workload string       `json:"workload"`
+	Host     string       `json:"host"`
+	Started  time.Time    `json:"started"`
+	Elapsed  float64      `json:"elapsed"`
+	Count    int64        `json:"count"`
+	OPS      float64      `json:"ops"`
+	Failed   int64        `json:"failed"`
+	Errors   []errorClass `json:"errors,omitempty"`
+}
+
+// notifier posts the outcome of the run to a webhook.
+type notifier struct {
+	p      *properties.Properties
+	url    string
+	errors *errorClasses
+}
+
+func newNotifier(p *properties.Properties) *notifier {
+	url := p.GetString(prop.NotifyWebhook, "")
+	if url == "" {
+		return nil
+	}
+	return &notifier{p: p, url: url, errors: &errorClasses{classes: make(map[string]*errorClass)}}
+}
+
+func (n *notifier) build(started time.Time, cause error) *notification {
+	msg := &notification{
+		Status:   "finished",
+		Command:  n.p.GetString(prop.Command, ""),
+		Workload: n.p.GetString(prop.Workload, "core"),
+		Started:  started,
+		Elapsed:  time.Since(started).Seconds(),
+		Errors:   n.errors.last(notifyErrorClasses),
+	}
+	msg.Host, _ = os.Hostname()
+	if cause != nil {
+		msg.Status = "failed"
+		msg.Reason = cause.Error()
+		if cause == context.Canceled {
+			msg.Status, msg.Reason = "interrupted", "the run was interrupted"
+		} else if cause == errMaxExecutionTime {
+			msg.Status = "finished"
+		}
+	}
+	results := measurement.Results()
+	for op, r := range results {
+		if measurement.IsFailure(op) {
+			msg.Failed += r.Count
+		}
+	}
+	if total, ok := results["TOTAL"]; ok {
+		msg.Count, msg.OPS = total.Count, total.OPS
+	}
+
+	elapsed := time.Duration(msg.Elapsed * float64(time.Second)).Round(time.Second)
+	var b strings.Builder
+	fmt.Fprintf(&b, "go-ycsb %s %s on %s %s after %s: %d operations, %.1f ops/sec, %d failed",
+		msg.Command, msg.Workload, msg.Host, msg.Status, elapsed, msg.Count, msg.OPS, msg.Failed)
+	if msg.Reason != "" {
+		fmt.Fprintf(&b, "\nreason: %s", msg.Reason)
+	}
+	for _, c := range msg.Errors {
+		fmt.Fprintf(&b, "\n%d x %s (last at %s)", c.Count, c.Class, c.Last.Format(time.TimeOnly))
+	}
+	msg.Text = b.String()
+	return msg
+}
+
+// send posts the outcome of the run, the cause is why the run stopped early.
+func (n *notifier) send(started time.Time, cause error) {
+	body, err := json.Marshal(n.build(started, cause))
+	if err == nil {
+		var resp *http.Response
+		client := &http.Client{Timeout: notifyTimeout}
+		resp, err = client.Post(n.url, "application/json", bytes.NewReader(body))
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode/100 != 2 {
+				err = fmt.Errorf("webhook returned %s", resp.Status)
+			}
+		}
+	}
+	if err != nil {
+		fmt.Printf("[NOTIFY] %v\n", err)
+		return
+	}
+	fmt.Println("[NOTIFY] the run summary is posted")
+}
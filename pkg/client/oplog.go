@@ -0,0 +1,381 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+type opLogContextKey string
+
+const opLogThreadKey = opLogContextKey("oplogThread")
+
+// OpRecord is one operation in the operation log.
+type OpRecord struct {
+	Thread   int                 `json:"thread"`
+	Op       string              `json:"op"`
+	Table    string              `json:"table"`
+	Keys     []string            `json:"keys"`
+	Fields   []string            `json:"fields,omitempty"`
+	Values   []map[string][]byte `json:"values,omitempty"`
+	Count    int                 `json:"count,omitempty"`
+	Error    string              `json:"error,omitempty"`
+	Finished time.Time           `json:"finished"`
+}
+
+// OpLogDB keeps the last operations in a ring buffer and writes them to a
+// repro file when an operation fails, the file can be replayed with Replay.
+type OpLogDB struct {
+	DB ycsb.DB
+
+	mu     sync.Mutex
+	ring   []OpRecord
+	next   int
+	full   bool
+	dumped bool
+	dir    string
+}
+
+// NewOpLogDB wraps the db if the operation log is enabled, otherwise it
+// returns the db unchanged.
+func NewOpLogDB(p *properties.Properties, db ycsb.DB) ycsb.DB {
+	size := p.GetInt(prop.OpLogSize, prop.OpLogSizeDefault)
+	if size <= 0 {
+		return db
+	}
+
+	return &OpLogDB{
+		DB:   db,
+		ring: make([]OpRecord, size),
+		dir:  p.GetString(prop.OutputDir, prop.OutputDirDefault),
+	}
+}
+
+func (db *OpLogDB) record(ctx context.Context, rec OpRecord, err error) {
+	if id, ok := ctx.Value(opLogThreadKey).(int); ok {
+		rec.Thread = id
+	}
+	rec.Finished = time.Now()
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.ring[db.next] = rec
+	db.next++
+	if db.next == len(db.ring) {
+		db.next = 0
+		db.full = true
+	}
+
+	if err == nil || db.dumped {
+		return
+	}
+	db.dumped = true
+
+	path, derr := db.dump()
+	if derr != nil {
+		fmt.Printf("[OPLOG] write repro file failed %v\n", derr)
+		return
+	}
+	fmt.Printf("[OPLOG] %s failed with %v, last operations are written to %s\n", rec.Op, err, path)
+}
+
+// dump writes the ring buffer in operation order, it must be called with mu held.
+func (db *OpLogDB) dump() (string, error) {
+	if err := os.MkdirAll(db.dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(db.dir, fmt.Sprintf("repro-%d.jsonl", time.Now().Unix()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	records := db.ring[:db.next]
+	if db.full {
+		records = append(append([]OpRecord(nil), db.ring[db.next:]...), db.ring[:db.next]...)
+	}
+
+	w := bufio.NewWriter(f)
+	enc := json.NewEncoder(w)
+	for _, rec := range records {
+		if err := enc.Encode(rec); err != nil {
+			return "", err
+		}
+	}
+	return path, w.Flush()
+}
+
+func copyValues(values map[string][]byte) map[string][]byte {
+	m := make(map[string][]byte, len(values))
+	for k, v := range values {
+		m[k] = append([]byte(nil), v...)
+	}
+	return m
+}
+
+func copyValuesList(values []map[string][]byte) []map[string][]byte {
+	res := make([]map[string][]byte, len(values))
+	for i, v := range values {
+		res[i] = copyValues(v)
+	}
+	return res
+}
+
+func (db *OpLogDB) Close() error {
+	return db.DB.Close()
+}
+
+func (db *OpLogDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	ctx = context.WithValue(ctx, opLogThreadKey, threadID)
+	return db.DB.InitThread(ctx, threadID, threadCount)
+}
+
+func (db *OpLogDB) CleanupThread(ctx context.Context) {
+	db.DB.CleanupThread(ctx)
+}
+
+func (db *OpLogDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	m, err := db.DB.Read(ctx, table, key, fields)
+	db.record(ctx, OpRecord{Op: "READ", Table: table, Keys: []string{key}, Fields: fields}, err)
+	return m, err
+}
+
+func (db *OpLogDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	res, err := db.DB.Scan(ctx, table, startKey, count, fields)
+	db.record(ctx, OpRecord{Op: "SCAN", Table: table, Keys: []string{startKey}, Fields: fields, Count: count}, err)
+	return res, err
+}
+
+func (db *OpLogDB) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db.DB)
+	}
+	keys, res, err := scanDB.ScanKeys(ctx, table, startKey, count, fields)
+	db.record(ctx, OpRecord{Op: "SCAN", Table: table, Keys: []string{startKey}, Fields: fields, Count: count}, err)
+	return keys, res, err
+}
+
+func (db *OpLogDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	rec := OpRecord{Op: "UPDATE", Table: table, Keys: []string{key}, Values: []map[string][]byte{copyValues(values)}}
+	err := db.DB.Update(ctx, table, key, values)
+	db.record(ctx, rec, err)
+	return err
+}
+
+func (db *OpLogDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	rec := OpRecord{Op: "INSERT", Table: table, Keys: []string{key}, Values: []map[string][]byte{copyValues(values)}}
+	err := db.DB.Insert(ctx, table, key, values)
+	db.record(ctx, rec, err)
+	return err
+}
+
+func (db *OpLogDB) Delete(ctx context.Context, table string, key string) error {
+	err := db.DB.Delete(ctx, table, key)
+	db.record(ctx, OpRecord{Op: "DELETE", Table: table, Keys: []string{key}}, err)
+	return err
+}
+
+func (db *OpLogDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	rec := OpRecord{Op: "BATCH_INSERT", Table: table, Keys: keys, Values: copyValuesList(values)}
+	var err error
+	if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+		err = batchDB.BatchInsert(ctx, table, keys, values)
+	} else {
+		for i := range keys {
+			if err = db.DB.Insert(ctx, table, keys[i], values[i]); err != nil {
+				break
+			}
+		}
+	}
+	db.record(ctx, rec, err)
+	return err
+}
+
+func (db *OpLogDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	var res []map[string][]byte
+	var err error
+	if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+		res, err = batchDB.BatchRead(ctx, table, keys, fields)
+	} else {
+		for _, key := range keys {
+			if _, err = db.DB.Read(ctx, table, key, fields); err != nil {
+				break
+			}
+		}
+	}
+	db.record(ctx, OpRecord{Op: "BATCH_READ", Table: table, Keys: keys, Fields: fields}, err)
+	return res, err
+}
+
+func (db *OpLogDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	rec := OpRecord{Op: "BATCH_UPDATE", Table: table, Keys: keys, Values: copyValuesList(values)}
+	var err error
+	if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+		err = batchDB.BatchUpdate(ctx, table, keys, values)
+	} else {
+		for i := range keys {
+			if err = db.DB.Update(ctx, table, keys[i], values[i]); err != nil {
+				break
+			}
+		}
+	}
+	db.record(ctx, rec, err)
+	return err
+}
+
+func (db *OpLogDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	var err error
+	if batchDB, ok := db.DB.(ycsb.BatchDB); ok {
+		err = batchDB.BatchDelete(ctx, table, keys)
+	} else {
+		for _, key := range keys {
+			if err = db.DB.Delete(ctx, table, key); err != nil {
+				break
+			}
+		}
+	}
+	db.record(ctx, OpRecord{Op: "BATCH_DELETE", Table: table, Keys: keys}, err)
+	return err
+}
+
+func (db *OpLogDB) Analyze(ctx context.Context, table string) error {
+	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
+		return analyzeDB.Analyze(ctx, table)
+	}
+	return nil
+}
+
+func (db *OpLogDB) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	iterateDB, ok := db.DB.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db.DB)
+	}
+	return iterateDB.Iterate(ctx, table, fn)
+}
+
+func (db *OpLogDB) Stats(ctx context.Context) (map[string]interface{}, error) {
+	if statsDB, ok := db.DB.(ycsb.StatsDB); ok {
+		return statsDB.Stats(ctx)
+	}
+	return nil, nil
+}
+
+func (db *OpLogDB) DiskSize(ctx context.Context) (int64, error) {
+	sizeDB, ok := db.DB.(ycsb.SizeDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the SizeDB interface", db.DB)
+	}
+	return sizeDB.DiskSize(ctx)
+}
+
+// ReadOpLog reads the operations from a repro file.
+func ReadOpLog(path string) ([]OpRecord, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var records []OpRecord
+	dec := json.NewDecoder(bufio.NewReader(f))
+	for dec.More() {
+		var rec OpRecord
+		if err := dec.Decode(&rec); err != nil {
+			return nil, err
+		}
+		records = append(records, rec)
+	}
+	return records, nil
+}
+
+func replayOne(ctx context.Context, db ycsb.DB, rec OpRecord) error {
+	batchDB, _ := db.(ycsb.BatchDB)
+	switch rec.Op {
+	case "READ":
+		_, err := db.Read(ctx, rec.Table, rec.Keys[0], rec.Fields)
+		return err
+	case "SCAN":
+		_, err := db.Scan(ctx, rec.Table, rec.Keys[0], rec.Count, rec.Fields)
+		return err
+	case "UPDATE":
+		return db.Update(ctx, rec.Table, rec.Keys[0], rec.Values[0])
+	case "INSERT":
+		return db.Insert(ctx, rec.Table, rec.Keys[0], rec.Values[0])
+	case "DELETE":
+		return db.Delete(ctx, rec.Table, rec.Keys[0])
+	}
+
+	if batchDB == nil {
+		return fmt.Errorf("the %T does't implement the batchDB interface", db)
+	}
+	switch rec.Op {
+	case "BATCH_INSERT":
+		return batchDB.BatchInsert(ctx, rec.Table, rec.Keys, rec.Values)
+	case "BATCH_READ":
+		_, err := batchDB.BatchRead(ctx, rec.Table, rec.Keys, rec.Fields)
+		return err
+	case "BATCH_UPDATE":
+		return batchDB.BatchUpdate(ctx, rec.Table, rec.Keys, rec.Values)
+	case "BATCH_DELETE":
+		return batchDB.BatchDelete(ctx, rec.Table, rec.Keys)
+	default:
+		return fmt.Errorf("unknown operation %s", rec.Op)
+	}
+}
+
+// Replay runs the recorded operations against the db one by one in the
+// recorded order, and reports every operation whose outcome differs from the
+// recorded one. It returns the number of diverging operations.
+func Replay(ctx context.Context, db ycsb.DB, records []OpRecord) int {
+	diverged := 0
+	for i, rec := range records {
+		select {
+		case <-ctx.Done():
+			return diverged
+		default:
+		}
+
+		err := replayOne(ctx, db, rec)
+		errStr := ""
+		if err != nil {
+			errStr = err.Error()
+		}
+		if (errStr == "") == (rec.Error == "") {
+			continue
+		}
+
+		diverged++
+		fmt.Printf("#%d thread %d %s %s %v: recorded error %q, replayed error %q\n",
+			i, rec.Thread, rec.Op, rec.Table, rec.Keys, rec.Error, errStr)
+	}
+	return diverged
+}
@@ -0,0 +1,397 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const (
+	oracleLockStripes = 256
+	// oracleMaxReported is the number of violations printed in the report.
+	oracleMaxReported = 20
+)
+
+// OracleDB mirrors every successful write into an in-memory map and checks
+// every read against it. Operations on the same key are serialized so the
+// check is strict, which makes it only suitable for small record counts.
+type OracleDB struct {
+	DB ycsb.DB
+
+	locks [oracleLockStripes]sync.Mutex
+
+	mu sync.RWMutex
+	// rows maps table/key to the row, a nil row means the key is deleted.
+	rows map[string]map[string][]byte
+
+	checked    int64
+	scans      int64
+	unverified int64
+	violations int64
+
+	reportMu sync.Mutex
+	reported []string
+}
+
+// NewOracleDB wraps the db with an in-memory oracle.
+func NewOracleDB(db ycsb.DB) *OracleDB {
+	return &OracleDB{
+		DB:   db,
+		rows: make(map[string]map[string][]byte),
+	}
+}
+
+func oracleKey(table string, key string) string {
+	return table + "/" + key
+}
+
+func (db *OracleDB) lock(table string, key string) *sync.Mutex {
+	l := &db.locks[uint64(util.StringHash64(oracleKey(table, key)))%oracleLockStripes]
+	l.Lock()
+	return l
+}
+
+func (db *OracleDB) get(table string, key string) (map[string][]byte, bool) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	row, ok := db.rows[oracleKey(table, key)]
+	return row, ok
+}
+
+func (db *OracleDB) put(table string, key string, values map[string][]byte, merge bool) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	k := oracleKey(table, key)
+	row := db.rows[k]
+	if row == nil || !merge {
+		row = make(map[string][]byte, len(values))
+		db.rows[k] = row
+	}
+	for field, value := range values {
+		row[field] = append([]byte(nil), value...)
+	}
+}
+
+func (db *OracleDB) remove(table string, key string) {
+	db.mu.Lock()
+	db.rows[oracleKey(table, key)] = nil
+	db.mu.Unlock()
+}
+
+func (db *OracleDB) violate(format string, args ...interface{}) {
+	atomic.AddInt64(&db.violations, 1)
+
+	db.reportMu.Lock()
+	if len(db.reported) < oracleMaxReported {
+		db.reported = append(db.reported, fmt.Sprintf(format, args...))
+	}
+	db.reportMu.Unlock()
+}
+
+// check validates the outcome of a read of the key against the oracle.
+func (db *OracleDB) check(table string, key string, fields []string, values map[string][]byte, err error) {
+	expected, ok := db.get(table, key)
+	if !ok {
+		atomic.AddInt64(&db.unverified, 1)
+		return
+	}
+	atomic.AddInt64(&db.checked, 1)
+
+	if expected == nil {
+		if err == nil && len(values) > 0 {
+			db.violate("read %s.%s returned a deleted row", table, key)
+		}
+		return
+	}
+	if err != nil {
+		db.violate("read %s.%s failed %v, but the row exists", table, key, err)
+		return
+	}
+
+	if len(fields) == 0 {
+		for field := range expected {
+			fields = append(fields, field)
+		}
+	}
+	for _, field := range fields {
+		want, wantOK := expected[field]
+		got, gotOK := values[field]
+		if wantOK != gotOK || !bytes.Equal(want, got) {
+			db.violate("read %s.%s field %s: expect %q, but got %q", table, key, field, want, got)
+			return
+		}
+	}
+}
+
+// liveKeys returns the sorted keys of the table the oracle knows to exist
+// from the start key, up to the end key if bounded.
+func (db *OracleDB) liveKeys(table string, startKey string, endKey string, bounded bool) []string {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	prefix := oracleKey(table, "")
+	var keys []string
+	for k, row := range db.rows {
+		if row == nil || !strings.HasPrefix(k, prefix) {
+			continue
+		}
+		key := k[len(prefix):]
+		if key >= startKey && (!bounded || key <= endKey) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// checkScan validates the outcome of a scan against the oracle.
+func (db *OracleDB) checkScan(table string, startKey string, count int, fields []string, keys []string, values []map[string][]byte) {
+	atomic.AddInt64(&db.scans, 1)
+
+	returned := make(map[string]struct{}, len(keys))
+	for i, key := range keys {
+		if key < startKey {
+			db.violate("scan %s from %s returned %s before the start key", table, startKey, key)
+			return
+		}
+		if i > 0 && key <= keys[i-1] {
+			db.violate("scan %s from %s returned %s after %s", table, startKey, key, keys[i-1])
+			return
+		}
+		returned[key] = struct{}{}
+		db.check(table, key, fields, values[i], nil)
+	}
+
+	if len(keys) == 0 {
+		if row, _ := db.get(table, startKey); row == nil {
+			// engines may return nothing when the start key doesn't exist,
+			// see prop.ScanMissingStartKey.
+			return
+		}
+	}
+
+	// a full scan only covers the keys up to the last returned one.
+	endKey, bounded := "", len(keys) >= count
+	if bounded && len(keys) > 0 {
+		endKey = keys[len(keys)-1]
+	}
+	for _, key := range db.liveKeys(table, startKey, endKey, bounded) {
+		if _, ok := returned[key]; !ok {
+			db.violate("scan %s from %s skipped the live key %s", table, startKey, key)
+			return
+		}
+	}
+}
+
+func (db *OracleDB) Close() error {
+	return db.DB.Close()
+}
+
+func (db *OracleDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	return db.DB.InitThread(ctx, threadID, threadCount)
+}
+
+func (db *OracleDB) CleanupThread(ctx context.Context) {
+	db.DB.CleanupThread(ctx)
+}
+
+func (db *OracleDB) Read(ctx context.Context, table string, key string, fields []string) (map[string][]byte, error) {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	values, err := db.DB.Read(ctx, table, key, fields)
+	db.check(table, key, fields, values, err)
+	return values, err
+}
+
+// Scan checks the scanned rows if the db can return their keys. The scan is
+// serialized with all the other operations, the keys must be in ascending
+// order from the start key without skipping any live key.
+func (db *OracleDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) ([]map[string][]byte, error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		// scanned rows don't carry their keys, so they can't be checked.
+		return db.DB.Scan(ctx, table, startKey, count, fields)
+	}
+
+	_, values, err := db.scanKeys(ctx, scanDB, table, startKey, count, fields)
+	return values, err
+}
+
+// ScanKeys checks the scanned rows like Scan.
+func (db *OracleDB) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db.DB)
+	}
+	return db.scanKeys(ctx, scanDB, table, startKey, count, fields)
+}
+
+func (db *OracleDB) scanKeys(ctx context.Context, scanDB ycsb.ScanKeysDB, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error) {
+	for i := range db.locks {
+		db.locks[i].Lock()
+	}
+	defer func() {
+		for i := range db.locks {
+			db.locks[i].Unlock()
+		}
+	}()
+
+	keys, values, err := scanDB.ScanKeys(ctx, table, startKey, count, fields)
+	if err == nil {
+		db.checkScan(table, startKey, count, fields, keys, values)
+	}
+	return keys, values, err
+}
+
+func (db *OracleDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	err := db.DB.Update(ctx, table, key, values)
+	if err == nil {
+		db.put(table, key, values, true)
+	}
+	return err
+}
+
+func (db *OracleDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	err := db.DB.Insert(ctx, table, key, values)
+	if err == nil {
+		db.put(table, key, values, false)
+	}
+	return err
+}
+
+func (db *OracleDB) Delete(ctx context.Context, table string, key string) error {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	err := db.DB.Delete(ctx, table, key)
+	if err == nil {
+		db.remove(table, key)
+	}
+	return err
+}
+
+// The batch operations are split into single operations so every key is
+// checked and serialized on its own.
+
+func (db *OracleDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	for i := range keys {
+		if err := db.Insert(ctx, table, keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *OracleDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	res := make([]map[string][]byte, 0, len(keys))
+	for _, key := range keys {
+		values, err := db.Read(ctx, table, key, fields)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, values)
+	}
+	return res, nil
+}
+
+func (db *OracleDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	for i := range keys {
+		if err := db.Update(ctx, table, keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *OracleDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	for _, key := range keys {
+		if err := db.Delete(ctx, table, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *OracleDB) Analyze(ctx context.Context, table string) error {
+	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
+		return analyzeDB.Analyze(ctx, table)
+	}
+	return nil
+}
+
+func (db *OracleDB) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	iterateDB, ok := db.DB.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db.DB)
+	}
+	return iterateDB.Iterate(ctx, table, fn)
+}
+
+func (db *OracleDB) Stats(ctx context.Context) (map[string]interface{}, error) {
+	if statsDB, ok := db.DB.(ycsb.StatsDB); ok {
+		return statsDB.Stats(ctx)
+	}
+	return nil, nil
+}
+
+func (db *OracleDB) DiskSize(ctx context.Context) (int64, error) {
+	sizeDB, ok := db.DB.(ycsb.SizeDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the SizeDB interface", db.DB)
+	}
+	return sizeDB.DiskSize(ctx)
+}
+
+// Report prints the correctness report and returns the number of violations.
+func (db *OracleDB) Report() int64 {
+	violations := atomic.LoadInt64(&db.violations)
+
+	fmt.Println("***************** oracle *****************")
+	fmt.Printf("checked reads: %d, checked scans: %d, unverified reads: %d, violations: %d\n",
+		atomic.LoadInt64(&db.checked), atomic.LoadInt64(&db.scans), atomic.LoadInt64(&db.unverified), violations)
+
+	db.reportMu.Lock()
+	for _, r := range db.reported {
+		fmt.Println(r)
+	}
+	db.reportMu.Unlock()
+	if violations > int64(oracleMaxReported) {
+		fmt.Printf("... %d more violations\n", violations-int64(oracleMaxReported))
+	}
+
+	if violations == 0 {
+		fmt.Println("PASS")
+	} else {
+		fmt.Println("FAIL")
+	}
+	return violations
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"testing"
+)
+
+func TestOracleCheckScan(t *testing.T) {
+	row := func(key string) map[string][]byte {
+		return map[string][]byte{"field0": []byte(key)}
+	}
+
+	cases := []struct {
+		name       string
+		startKey   string
+		count      int
+		keys       []string
+		values     []map[string][]byte
+		violations int64
+	}{
+		{"in order", "b", 10, []string{"b", "c", "e"}, nil, 0},
+		{"bounded by the count", "a", 2, []string{"a", "b"}, nil, 0},
+		{"missing start key", "bb", 10, []string{"c", "e"}, nil, 0},
+		{"nothing from a missing start key", "bb", 10, nil, nil, 0},
+		{"out of order", "a", 10, []string{"a", "c", "b", "e"}, nil, 1},
+		{"repeated key", "a", 10, []string{"a", "a", "b"}, nil, 1},
+		{"before the start key", "b", 10, []string{"a", "b", "c", "e"}, nil, 1},
+		{"skipped live key", "a", 10, []string{"a", "c", "e"}, nil, 1},
+		{"skipped the last live key", "a", 10, []string{"a", "b", "c"}, nil, 1},
+		{"nothing from a live start key", "a", 10, nil, nil, 1},
+		{"wrong value", "a", 2, []string{"a", "b"}, []map[string][]byte{row("a"), row("c")}, 1},
+		{"deleted row", "c", 3, []string{"c", "d", "e"}, []map[string][]byte{row("c"), row("d"), row("e")}, 1},
+	}
+	for _, c := range cases {
+		db := NewOracleDB(nil)
+		for _, key := range []string{"a", "b", "c", "d", "e"} {
+			db.put("t", key, row(key), false)
+		}
+		db.remove("t", "d")
+
+		values := c.values
+		if values == nil {
+			for _, key := range c.keys {
+				values = append(values, row(key))
+			}
+		}
+		db.checkScan("t", c.startKey, c.count, nil, c.keys, values)
+		if db.violations != c.violations || db.scans != 1 {
+			t.Fatalf("expect %s to have %d violations, but got %d %v", c.name, c.violations, db.violations, db.reported)
+		}
+	}
+}
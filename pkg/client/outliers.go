@@ -0,0 +1,178 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// outliers is the recorder of the running client, nil if outliers are not captured.
+var outliers *outlierRecorder
+
+// outlierBacklog is the number of outliers waiting to be captured, outliers
+// coming faster are only counted.
+const outlierBacklog = 16
+
+type outlierGC struct {
+	NumGC      int64     `json:"num_gc"`
+	LastGC     time.Time `json:"last_gc"`
+	LastPause  float64   `json:"last_pause"`
+	PauseTotal float64   `json:"pause_total"`
+	HeapAlloc  uint64    `json:"heap_alloc"`
+	HeapSys    uint64    `json:"heap_sys"`
+}
+
+// outlier is the state of the process captured right after an operation
+// exceeded the outlier threshold, the durations are in microseconds.
+type outlier struct {
+	Op         string                 `json:"op"`
+	Start      time.Time              `json:"start"`
+	Latency    float64                `json:"latency"`
+	Captured   time.Time              `json:"captured"`
+	Goroutines int                    `json:"goroutines"`
+	QueueDepth *int                   `json:"queue_depth,omitempty"`
+	GC         outlierGC              `json:"gc"`
+	Engine     map[string]interface{} `json:"engine,omitempty"`
+	EngineErr  string                 `json:"engine_error,omitempty"`
+}
+
+// outlierRecorder captures the engine stats, GC stats and the committer
+// queue depth when an operation is slower than the threshold, so slow
+// operations can be explained.
+type outlierRecorder struct {
+	threshold time.Duration
+	max       int64
+	path      string
+	db        ycsb.DB
+	queue     chan committerItem
+
+	ch       chan outlier
+	done     chan struct{}
+	count    int64
+	captured int64
+}
+
+func newOutlierRecorder(p *properties.Properties, db ycsb.DB) *outlierRecorder {
+	threshold := p.GetParsedDuration(prop.OutlierThreshold, prop.OutlierThresholdDefault)
+	if threshold <= 0 {
+		return nil
+	}
+
+	return &outlierRecorder{
+		threshold: threshold,
+		max:       p.GetInt64(prop.OutlierMax, prop.OutlierMaxDefault),
+		path:      filepath.Join(p.GetString(prop.OutputDir, prop.OutputDirDefault), "outliers.jsonl"),
+		db:        db,
+		ch:        make(chan outlier, outlierBacklog),
+		done:      make(chan struct{}),
+	}
+}
+
+// check hands the operation over to the capture goroutine if it's an outlier.
+func (r *outlierRecorder) check(op string, start time.Time, lan time.Duration) {
+	if r == nil || lan < r.threshold {
+		return
+	}
+
+	if atomic.AddInt64(&r.count, 1) > r.max {
+		return
+	}
+	select {
+	case r.ch <- outlier{Op: op, Start: start, Latency: float64(lan.Nanoseconds()) / 1e3}:
+	default:
+	}
+}
+
+func (r *outlierRecorder) run(ctx context.Context) {
+	defer close(r.done)
+
+	var enc *json.Encoder
+	for o := range r.ch {
+		r.capture(ctx, &o)
+
+		if enc == nil {
+			if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+				fmt.Printf("[OUTLIER] create %s failed %v\n", r.path, err)
+				continue
+			}
+			f, err := os.Create(r.path)
+			if err != nil {
+				fmt.Printf("[OUTLIER] create %s failed %v\n", r.path, err)
+				continue
+			}
+			defer f.Close()
+			enc = json.NewEncoder(f)
+		}
+		if err := enc.Encode(o); err == nil {
+			r.captured++
+		}
+	}
+}
+
+func (r *outlierRecorder) capture(ctx context.Context, o *outlier) {
+	o.Captured = time.Now()
+	o.Goroutines = runtime.NumGoroutine()
+	if r.queue != nil {
+		depth := len(r.queue)
+		o.QueueDepth = &depth
+	}
+
+	var gc debug.GCStats
+	debug.ReadGCStats(&gc)
+	o.GC.NumGC = gc.NumGC
+	o.GC.LastGC = gc.LastGC
+	o.GC.PauseTotal = float64(gc.PauseTotal.Nanoseconds()) / 1e3
+	if len(gc.Pause) > 0 {
+		o.GC.LastPause = float64(gc.Pause[0].Nanoseconds()) / 1e3
+	}
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	o.GC.HeapAlloc = ms.HeapAlloc
+	o.GC.HeapSys = ms.HeapSys
+
+	if statsDB, ok := r.db.(ycsb.StatsDB); ok {
+		stats, err := statsDB.Stats(ctx)
+		o.Engine = stats
+		if err != nil {
+			o.EngineErr = err.Error()
+		}
+	}
+}
+
+// close waits for the pending outliers to be captured.
+func (r *outlierRecorder) close() {
+	close(r.ch)
+	<-r.done
+}
+
+func (r *outlierRecorder) report() {
+	count := atomic.LoadInt64(&r.count)
+	if count == 0 {
+		return
+	}
+	fmt.Printf("[OUTLIER] %d operations took longer than %s, %d are captured in %s\n",
+		count, r.threshold, r.captured, r.path)
+}
@@ -0,0 +1,82 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// panicRecorder collects panics recovered from the workers so that a single
+// bad operation doesn't bring down the whole benchmark.
+type panicRecorder struct {
+	sync.Mutex
+
+	path  string
+	count int64
+}
+
+func newPanicRecorder(p *properties.Properties) *panicRecorder {
+	if !p.GetBool(prop.RecoverPanics, prop.RecoverPanicsDefault) {
+		return nil
+	}
+
+	return &panicRecorder{
+		path: filepath.Join(p.GetString(prop.OutputDir, prop.OutputDirDefault), "panics.log"),
+	}
+}
+
+// record measures the panic as a PANIC operation and appends its stack trace
+// to the panic log.
+func (r *panicRecorder) record(threadID int, start time.Time, v interface{}, stack []byte) {
+	measurement.Measure("PANIC", start, time.Now().Sub(start))
+
+	r.Lock()
+	defer r.Unlock()
+
+	r.count++
+	if r.count == 1 {
+		fmt.Printf("[PANIC] thread %d recovered from panic: %v, stack traces are written to %s\n", threadID, v, r.path)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(r.path), 0755); err != nil {
+		fmt.Printf("[PANIC] create panic log failed %v\n", err)
+		return
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		fmt.Printf("[PANIC] open panic log failed %v\n", err)
+		return
+	}
+	defer f.Close()
+
+	fmt.Fprintf(f, "%s thread %d panic: %v\n%s\n", time.Now().Format(time.RFC3339Nano), threadID, v, stack)
+}
+
+// report prints the number of recovered panics at the end of the run.
+func (r *panicRecorder) report() {
+	r.Lock()
+	defer r.Unlock()
+
+	if r.count > 0 {
+		fmt.Printf("[PANIC] %d panics recovered, see %s\n", r.count, r.path)
+	}
+}
@@ -0,0 +1,124 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// loadPattern changes the target over time, rate returns the target at the
+// time since the start of the run.
+type loadPattern struct {
+	step time.Duration
+	rate func(elapsed time.Duration) int64
+}
+
+func newLoadPattern(p *properties.Properties) *loadPattern {
+	name := p.GetString(prop.Pattern, prop.PatternDefault)
+	if name == "" {
+		return nil
+	}
+
+	l := &loadPattern{step: p.GetParsedDuration(prop.PatternStep, prop.PatternStepDefault)}
+	switch name {
+	case "burst":
+		l.rate = burstRate(p)
+	case "diurnal":
+		l.rate = diurnalRate(p)
+	default:
+		util.Fatalf("unknown load pattern %s", name)
+	}
+	if l.step <= 0 {
+		util.Fatalf("%s must be positive", prop.PatternStep)
+	}
+	return l
+}
+
+// burstRate is a square wave: the peak target during the first duty
+// fraction of every period and the idle target during the rest of it.
+func burstRate(p *properties.Properties) func(time.Duration) int64 {
+	period := p.GetParsedDuration(prop.PatternBurstPeriod, prop.PatternBurstPeriodDefault)
+	duty := p.GetFloat64(prop.PatternBurstDuty, prop.PatternBurstDutyDefault)
+	peak := p.GetInt64(prop.PatternBurstPeak, prop.PatternBurstPeakDefault)
+	idle := p.GetInt64(prop.PatternBurstIdle, prop.PatternBurstIdleDefault)
+	if period <= 0 {
+		util.Fatalf("%s must be positive", prop.PatternBurstPeriod)
+	}
+	if duty < 0 || duty > 1 {
+		util.Fatalf("%s must be between 0 and 1", prop.PatternBurstDuty)
+	}
+	if idle == 0 {
+		idle = targetPaused
+	}
+
+	on := time.Duration(duty * float64(period))
+	return func(elapsed time.Duration) int64 {
+		if elapsed%period < on {
+			return peak
+		}
+		return idle
+	}
+}
+
+// diurnalRate is a sine around the mean target, starting at the mean and
+// rising first, like the traffic of a day in an accelerated time.
+func diurnalRate(p *properties.Properties) func(time.Duration) int64 {
+	period := p.GetParsedDuration(prop.PatternDiurnalPeriod, prop.PatternDiurnalPeriodDefault)
+	mean := float64(p.GetInt64(prop.PatternDiurnalMean, 0))
+	amplitude := p.GetFloat64(prop.PatternDiurnalAmplitude, prop.PatternDiurnalAmplitudeDefault)
+	if period <= 0 {
+		util.Fatalf("%s must be positive", prop.PatternDiurnalPeriod)
+	}
+	if mean <= 0 {
+		util.Fatalf("%s must be positive", prop.PatternDiurnalMean)
+	}
+	if amplitude < 0 || amplitude > 1 {
+		util.Fatalf("%s must be between 0 and 1", prop.PatternDiurnalAmplitude)
+	}
+
+	return func(elapsed time.Duration) int64 {
+		phase := 2 * math.Pi * float64(elapsed%period) / float64(period)
+		rate := int64(math.Round(mean * (1 + amplitude*math.Sin(phase))))
+		if rate < 1 {
+			// a target of 0 would be unlimited.
+			return 1
+		}
+		return rate
+	}
+}
+
+func (l *loadPattern) run(ctx context.Context) {
+	start := time.Now()
+	ops, _ := target.get()
+	t := time.NewTicker(l.step)
+	defer t.Stop()
+	for {
+		if rate := l.rate(time.Since(start)); rate != ops {
+			ops = rate
+			target.set(ops)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+	}
+}
@@ -0,0 +1,194 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// poolDatasetFile describes a data set in its directory of the pool, next to
+// the data directory holding the files of the database.
+const (
+	poolDatasetFile = "dataset.json"
+	poolDataDir     = "data"
+)
+
+// poolProperties are the properties the loaded data set depends on.
+var poolProperties = []string{
+	prop.Workload,
+	prop.Tables,
+	prop.TableName,
+	prop.RecordCount,
+	prop.InsertStart,
+	prop.InsertCount,
+	prop.FieldCount,
+	prop.FieldLength,
+	prop.FieldLengthDistribution,
+	prop.InsertOrder,
+	prop.KeyPrefix,
+	prop.ZeroPadding,
+	prop.DataIntegrity,
+}
+
+// PoolDataset is a pre-built data set of the pool.
+type PoolDataset struct {
+	Name    string    `json:"name"`
+	DB      string    `json:"db"`
+	Created time.Time `json:"created"`
+	// Signature are the properties the data set is loaded with.
+	Signature map[string]string `json:"signature"`
+}
+
+// DatasetSignature returns the properties the data set loaded by the
+// properties depends on: the ones of the workload, their per-table overrides
+// and the ones of the binding, like its codec options, but its data path.
+func DatasetSignature(p *properties.Properties, dbName string) map[string]string {
+	sig := make(map[string]string)
+	for _, key := range poolProperties {
+		if v, ok := p.Get(key); ok {
+			sig[key] = v
+		}
+	}
+	for _, key := range p.FilterPrefix(prop.TableName + ".").Keys() {
+		sig[key] = p.GetString(key, "")
+	}
+	if dataPath, ok := ycsb.GetDBDataPath(dbName); ok {
+		prefix := dataPath.Property[:strings.IndexByte(dataPath.Property, '.')+1]
+		for _, key := range p.FilterPrefix(prefix).Keys() {
+			if key != dataPath.Property {
+				sig[key] = p.GetString(key, "")
+			}
+		}
+	}
+	return sig
+}
+
+// DatasetName names the data set of the database with the signature.
+func DatasetName(dbName string, sig map[string]string) string {
+	keys := make([]string, 0, len(sig))
+	for key := range sig {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	h := sha256.New()
+	for _, key := range keys {
+		fmt.Fprintf(h, "%s=%s\n", key, sig[key])
+	}
+	return dbName + "-" + hex.EncodeToString(h.Sum(nil))[:12]
+}
+
+// Pool is a directory of pre-built data sets, every data set is a copy of the
+// local files of a database loaded with the properties of its signature.
+type Pool struct {
+	dir string
+}
+
+// OpenPool returns the pool of the properties, nil if there is none.
+func OpenPool(p *properties.Properties) *Pool {
+	dir := p.GetString(prop.PoolDir, "")
+	if dir == "" {
+		return nil
+	}
+	return &Pool{dir: dir}
+}
+
+// DataPath returns where the files of the database of the data set are.
+func (pl *Pool) DataPath(name string) string {
+	return filepath.Join(pl.dir, name, poolDataDir)
+}
+
+// Find returns the data set, false if it's not in the pool.
+func (pl *Pool) Find(name string) (*PoolDataset, bool) {
+	data, err := os.ReadFile(filepath.Join(pl.dir, name, poolDatasetFile))
+	if err != nil {
+		return nil, false
+	}
+	var ds PoolDataset
+	if err := json.Unmarshal(data, &ds); err != nil {
+		return nil, false
+	}
+	return &ds, true
+}
+
+// List returns the data sets of the pool.
+func (pl *Pool) List() ([]*PoolDataset, error) {
+	entries, err := os.ReadDir(pl.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var datasets []*PoolDataset
+	for _, e := range entries {
+		if ds, ok := pl.Find(e.Name()); ok && e.IsDir() {
+			datasets = append(datasets, ds)
+		}
+	}
+	return datasets, nil
+}
+
+// Create empties the directory of the data set and returns the data path to
+// load it into.
+func (pl *Pool) Create(name string) (string, error) {
+	dir := filepath.Join(pl.dir, name)
+	if err := os.RemoveAll(dir); err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	return pl.DataPath(name), nil
+}
+
+// Add records the data set, its files must be in its data path already.
+func (pl *Pool) Add(ds *PoolDataset) error {
+	data, err := json.MarshalIndent(ds, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(pl.dir, ds.Name, poolDatasetFile), data, 0644)
+}
+
+// Remove deletes the data set from the pool.
+func (pl *Pool) Remove(name string) error {
+	if _, ok := pl.Find(name); !ok {
+		return fmt.Errorf("data set %s is not in the pool %s", name, pl.dir)
+	}
+	return os.RemoveAll(filepath.Join(pl.dir, name))
+}
+
+// Restore replaces the files at the path by a copy of the data set, the data
+// set in the pool stays untouched.
+func (pl *Pool) Restore(name string, path string) error {
+	if err := os.RemoveAll(path); err != nil {
+		return err
+	}
+	return util.CopyPath(pl.DataPath(name), path)
+}
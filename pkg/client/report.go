@@ -0,0 +1,158 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	_ "embed"
+	"fmt"
+	"html/template"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+//go:embed report.html
+var reportPage string
+
+var reportTemplate = template.Must(template.New("report").Parse(reportPage))
+
+// reportFile is the file in the output directory the report is written to.
+const reportFile = "report.html"
+
+// reportPoll is how often the throughput of the report chart is sampled.
+const reportPoll = time.Second
+
+type reportResult struct {
+	measurement.Result
+	Op     string
+	Failed bool
+}
+
+type reportProperty struct {
+	Key   string
+	Value string
+}
+
+// reportData is the content of the report page.
+type reportData struct {
+	Title       string
+	Started     time.Time
+	Duration    time.Duration
+	Build       BuildInfo
+	Environment ManifestEnvironment
+	Results     []reportResult
+	Failures    []reportResult
+	Throughput  template.HTML
+	Annotations []measurement.Annotation
+	Properties  []reportProperty
+}
+
+// runReport samples the throughput of the run and writes the HTML report
+// once the run ends.
+type runReport struct {
+	p       *properties.Properties
+	started time.Time
+
+	elapsed []float64
+	ops     []float64
+	lastOps int64
+	lastAt  time.Time
+}
+
+func newRunReport(p *properties.Properties) *runReport {
+	if !p.GetBool(prop.Report, prop.ReportDefault) {
+		return nil
+	}
+	return &runReport{p: p, started: time.Now()}
+}
+
+// begin starts the throughput samples once the warm up is over.
+func (r *runReport) begin() {
+	r.lastAt = time.Now()
+	r.lastOps = atomic.LoadInt64(&completedOps)
+}
+
+func (r *runReport) sample() {
+	now := time.Now()
+	ops := atomic.LoadInt64(&completedOps)
+	r.elapsed = append(r.elapsed, now.Sub(r.started).Seconds())
+	r.ops = append(r.ops, float64(ops-r.lastOps)/now.Sub(r.lastAt).Seconds())
+	r.lastAt, r.lastOps = now, ops
+}
+
+func (r *runReport) data() *reportData {
+	d := &reportData{
+		Title:       fmt.Sprintf("go-ycsb %s %s", r.p.GetString(prop.Command, ""), r.p.GetString(prop.Workload, "core")),
+		Started:     r.started,
+		Duration:    time.Since(r.started).Round(time.Millisecond),
+		Build:       CurrentBuild(),
+		Environment: currentEnvironment(),
+	}
+
+	results := measurement.Results()
+	ops := make([]string, 0, len(results))
+	for op := range results {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	for _, op := range ops {
+		res := reportResult{Result: results[op], Op: op, Failed: measurement.IsFailure(op)}
+		d.Results = append(d.Results, res)
+		if res.Failed {
+			d.Failures = append(d.Failures, res)
+		}
+	}
+
+	var svg bytes.Buffer
+	chart := &util.Chart{
+		XLabel: "time (s)",
+		YLabel: "ops/sec",
+		Series: []util.ChartSeries{{Name: "TOTAL", X: r.elapsed, Y: r.ops}},
+	}
+	chart.WriteSVG(&svg)
+	d.Throughput = template.HTML(svg.String())
+
+	d.Annotations, _ = measurement.Annotations(0)
+	for _, key := range r.p.Keys() {
+		d.Properties = append(d.Properties, reportProperty{Key: key, Value: r.p.GetString(key, "")})
+	}
+	sort.Slice(d.Properties, func(i, j int) bool { return d.Properties[i].Key < d.Properties[j].Key })
+	return d
+}
+
+// write writes the report to the output directory.
+func (r *runReport) write() {
+	dir := r.p.GetString(prop.OutputDir, prop.OutputDirDefault)
+	path := filepath.Join(dir, reportFile)
+	err := os.MkdirAll(dir, 0755)
+	if err == nil {
+		var b bytes.Buffer
+		if err = reportTemplate.Execute(&b, r.data()); err == nil {
+			err = os.WriteFile(path, b.Bytes(), 0644)
+		}
+	}
+	if err != nil {
+		fmt.Printf("[REPORT] write %s failed %v\n", path, err)
+		return
+	}
+	fmt.Printf("[REPORT] written to %s\n", path)
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// ResultsRecord is a line of the results file, the results of one load or
+// run and its repetitions.
+type ResultsRecord struct {
+	Time     time.Time `json:"time"`
+	Command  string    `json:"command"`
+	DB       string    `json:"db"`
+	Workload string    `json:"workload"`
+	BuildInfo
+	Host string                          `json:"host"`
+	Runs []map[string]measurement.Result `json:"runs"`
+}
+
+// AppendResults appends the results of the repetitions of a load or run to
+// the results file.
+func AppendResults(path string, p *properties.Properties, db string, runs []map[string]measurement.Result) error {
+	rec := ResultsRecord{
+		Time:      time.Now(),
+		Command:   p.GetString(prop.Command, ""),
+		DB:        db,
+		Workload:  p.GetString(prop.Workload, "core"),
+		Runs:      runs,
+		BuildInfo: CurrentBuild(),
+	}
+	rec.Host, _ = os.Hostname()
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if err := json.NewEncoder(f).Encode(rec); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
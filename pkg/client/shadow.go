@@ -0,0 +1,472 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// shadowMaxReported is the number of divergences printed in the report.
+const shadowMaxReported = 20
+
+// shadowMaxValue is the length of the values printed in the report, the
+// divergences file holds them in full.
+const shadowMaxValue = 32
+
+type shadowContextKey string
+
+const shadowKey = shadowContextKey("shadow")
+
+// ShadowDB runs every operation on the primary and the shadow database at the
+// same time and compares their outcomes, to find where two engines diverge
+// under a real workload. The primary's result is returned. Operations on the
+// same key are serialized so both databases apply the writes in the same
+// order.
+type ShadowDB struct {
+	DB     ycsb.DB
+	Shadow ycsb.DB
+
+	locks [oracleLockStripes]sync.Mutex
+
+	compared    int64
+	divergences int64
+
+	// compare compares the read values field by field, not only their count.
+	compare bool
+	path    string
+
+	reportMu sync.Mutex
+	reported []string
+	enc      *json.Encoder
+	f        *os.File
+}
+
+// shadowFieldDiff is a field whose value differs between the databases, a
+// nil value is a missing field.
+type shadowFieldDiff struct {
+	Field   string  `json:"field"`
+	Primary *string `json:"primary"`
+	Shadow  *string `json:"shadow"`
+}
+
+// shadowDivergence is an operation whose outcome differs between the
+// primary and the shadow database.
+type shadowDivergence struct {
+	Op     string            `json:"op"`
+	Table  string            `json:"table"`
+	Key    string            `json:"key"`
+	Reason string            `json:"reason"`
+	Fields []shadowFieldDiff `json:"fields,omitempty"`
+}
+
+func shadowValue(v *string) string {
+	if v == nil {
+		return "<missing>"
+	}
+	if len(*v) > shadowMaxValue {
+		return fmt.Sprintf("%q...", (*v)[:shadowMaxValue])
+	}
+	return fmt.Sprintf("%q", *v)
+}
+
+func (d shadowDivergence) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s.%s %s", d.Op, d.Table, d.Key, d.Reason)
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "\n  %s: primary %s, shadow %s", f.Field, shadowValue(f.Primary), shadowValue(f.Shadow))
+	}
+	return b.String()
+}
+
+// NewShadowDB wraps the primary db with the shadow one.
+func NewShadowDB(p *properties.Properties, db ycsb.DB, shadow ycsb.DB) *ShadowDB {
+	return &ShadowDB{
+		DB:      db,
+		Shadow:  shadow,
+		compare: p.GetBool(prop.ShadowCompare, prop.ShadowCompareDefault),
+		path:    filepath.Join(p.GetString(prop.OutputDir, prop.OutputDirDefault), "divergences.jsonl"),
+	}
+}
+
+func (db *ShadowDB) lock(table string, key string) *sync.Mutex {
+	l := &db.locks[uint64(util.StringHash64(oracleKey(table, key)))%oracleLockStripes]
+	l.Lock()
+	return l
+}
+
+func (db *ShadowDB) lockAll() func() {
+	for i := range db.locks {
+		db.locks[i].Lock()
+	}
+	return func() {
+		for i := range db.locks {
+			db.locks[i].Unlock()
+		}
+	}
+}
+
+// diverge records the divergence for the report and appends it to the
+// divergences file.
+func (db *ShadowDB) diverge(d shadowDivergence) {
+	atomic.AddInt64(&db.divergences, 1)
+
+	db.reportMu.Lock()
+	defer db.reportMu.Unlock()
+	if len(db.reported) < shadowMaxReported {
+		db.reported = append(db.reported, d.String())
+	}
+
+	if db.enc == nil && db.path != "" {
+		if err := os.MkdirAll(filepath.Dir(db.path), 0755); err != nil {
+			fmt.Printf("[SHADOW] create %s failed %v\n", db.path, err)
+			db.path = ""
+			return
+		}
+		f, err := os.Create(db.path)
+		if err != nil {
+			fmt.Printf("[SHADOW] create %s failed %v\n", db.path, err)
+			db.path = ""
+			return
+		}
+		db.f = f
+		db.enc = json.NewEncoder(f)
+	}
+	if db.enc != nil {
+		db.enc.Encode(d)
+	}
+}
+
+// both runs the operation on the shadow in the background while it runs on
+// the primary, and returns both errors.
+func (db *ShadowDB) both(ctx context.Context, primary func(ctx context.Context) error, shadow func(ctx context.Context) error) (error, error) {
+	shadowCtx := ctx.Value(shadowKey).(context.Context)
+	ch := make(chan error, 1)
+	go func() {
+		ch <- shadow(shadowCtx)
+	}()
+	err := primary(ctx)
+	return err, <-ch
+}
+
+// compareErrors checks both databases succeeded or failed, it returns
+// whether the outcomes can be compared further.
+func (db *ShadowDB) compareErrors(op string, table string, key string, err error, shadowErr error) bool {
+	atomic.AddInt64(&db.compared, 1)
+	switch {
+	case err == nil && shadowErr != nil:
+		db.diverge(shadowDivergence{Op: op, Table: table, Key: key,
+			Reason: fmt.Sprintf("succeeded on the primary, but failed on the shadow: %v", shadowErr)})
+	case err != nil && shadowErr == nil:
+		db.diverge(shadowDivergence{Op: op, Table: table, Key: key,
+			Reason: fmt.Sprintf("failed on the primary: %v, but succeeded on the shadow", err)})
+	default:
+		return err == nil
+	}
+	return false
+}
+
+// diffFields returns the fields whose values differ between the rows,
+// sorted by name.
+func diffFields(values map[string][]byte, shadowValues map[string][]byte) []shadowFieldDiff {
+	var diffs []shadowFieldDiff
+	for field, v := range values {
+		sv, ok := shadowValues[field]
+		if ok && bytes.Equal(v, sv) {
+			continue
+		}
+		primary := string(v)
+		diff := shadowFieldDiff{Field: field, Primary: &primary}
+		if ok {
+			shadow := string(sv)
+			diff.Shadow = &shadow
+		}
+		diffs = append(diffs, diff)
+	}
+	for field, sv := range shadowValues {
+		if _, ok := values[field]; !ok {
+			shadow := string(sv)
+			diffs = append(diffs, shadowFieldDiff{Field: field, Shadow: &shadow})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Field < diffs[j].Field })
+	return diffs
+}
+
+// compareRow checks both databases returned the same row, field by field
+// with shadow.compare, otherwise only the number of fields. The row is the
+// position of the row in a scan.
+func (db *ShadowDB) compareRow(op string, table string, key string, row string, values map[string][]byte, shadowValues map[string][]byte) {
+	if !db.compare {
+		if len(values) != len(shadowValues) {
+			db.diverge(shadowDivergence{Op: op, Table: table, Key: key,
+				Reason: fmt.Sprintf("returned %d fields%s on the primary, but %d on the shadow", len(values), row, len(shadowValues))})
+		}
+		return
+	}
+
+	if diffs := diffFields(values, shadowValues); len(diffs) > 0 {
+		db.diverge(shadowDivergence{Op: op, Table: table, Key: key, Reason: "returned different fields" + row, Fields: diffs})
+	}
+}
+
+func (db *ShadowDB) Close() error {
+	db.Report()
+	if db.f != nil {
+		db.f.Close()
+	}
+	shadowErr := db.Shadow.Close()
+	if err := db.DB.Close(); err != nil {
+		return err
+	}
+	return shadowErr
+}
+
+func (db *ShadowDB) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	// the shadow's state is kept apart, both may be the same binding.
+	shadowCtx := db.Shadow.InitThread(ctx, threadID, threadCount)
+	ctx = db.DB.InitThread(ctx, threadID, threadCount)
+	return context.WithValue(ctx, shadowKey, shadowCtx)
+}
+
+func (db *ShadowDB) CleanupThread(ctx context.Context) {
+	db.Shadow.CleanupThread(ctx.Value(shadowKey).(context.Context))
+	db.DB.CleanupThread(ctx)
+}
+
+func (db *ShadowDB) Read(ctx context.Context, table string, key string, fields []string) (values map[string][]byte, err error) {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	var shadowValues map[string][]byte
+	err, shadowErr := db.both(ctx, func(ctx context.Context) error {
+		values, err = db.DB.Read(ctx, table, key, fields)
+		return err
+	}, func(ctx context.Context) (err error) {
+		shadowValues, err = db.Shadow.Read(ctx, table, key, fields)
+		return err
+	})
+	if db.compareErrors("read", table, key, err, shadowErr) {
+		db.compareRow("read", table, key, "", values, shadowValues)
+	}
+	return values, err
+}
+
+// Scan is serialized with all the other operations, so both databases scan
+// the same rows.
+func (db *ShadowDB) Scan(ctx context.Context, table string, startKey string, count int, fields []string) (values []map[string][]byte, err error) {
+	defer db.lockAll()()
+
+	var shadowValues []map[string][]byte
+	err, shadowErr := db.both(ctx, func(ctx context.Context) error {
+		values, err = db.DB.Scan(ctx, table, startKey, count, fields)
+		return err
+	}, func(ctx context.Context) (err error) {
+		shadowValues, err = db.Shadow.Scan(ctx, table, startKey, count, fields)
+		return err
+	})
+	if db.compareErrors("scan", table, startKey, err, shadowErr) {
+		db.compareScan(table, startKey, nil, nil, values, shadowValues)
+	}
+	return values, err
+}
+
+// ScanKeys is serialized like Scan, the keys are compared along with the
+// rows.
+func (db *ShadowDB) ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) (keys []string, values []map[string][]byte, err error) {
+	scanDB, ok := db.DB.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the %T does't implement the ScanKeysDB interface", db.DB)
+	}
+	shadowScanDB, ok := db.Shadow.(ycsb.ScanKeysDB)
+	if !ok {
+		return nil, nil, fmt.Errorf("the shadow %T does't implement the ScanKeysDB interface", db.Shadow)
+	}
+
+	defer db.lockAll()()
+
+	var shadowKeys []string
+	var shadowValues []map[string][]byte
+	err, shadowErr := db.both(ctx, func(ctx context.Context) error {
+		keys, values, err = scanDB.ScanKeys(ctx, table, startKey, count, fields)
+		return err
+	}, func(ctx context.Context) (err error) {
+		shadowKeys, shadowValues, err = shadowScanDB.ScanKeys(ctx, table, startKey, count, fields)
+		return err
+	})
+	if db.compareErrors("scan", table, startKey, err, shadowErr) {
+		db.compareScan(table, startKey, keys, shadowKeys, values, shadowValues)
+	}
+	return keys, values, err
+}
+
+// compareScan checks both databases returned the same rows, and the same
+// keys if they are known.
+func (db *ShadowDB) compareScan(table string, startKey string, keys []string, shadowKeys []string, values []map[string][]byte, shadowValues []map[string][]byte) {
+	if len(values) != len(shadowValues) {
+		db.diverge(shadowDivergence{Op: "scan", Table: table, Key: startKey,
+			Reason: fmt.Sprintf("returned %d rows on the primary, but %d on the shadow", len(values), len(shadowValues))})
+		return
+	}
+	for i := range keys {
+		if keys[i] != shadowKeys[i] {
+			db.diverge(shadowDivergence{Op: "scan", Table: table, Key: startKey,
+				Reason: fmt.Sprintf("returned the key %s in row %d on the primary, but %s on the shadow", keys[i], i, shadowKeys[i])})
+			return
+		}
+	}
+	for i := range values {
+		db.compareRow("scan", table, startKey, fmt.Sprintf(" in row %d", i), values[i], shadowValues[i])
+	}
+}
+
+func (db *ShadowDB) write(ctx context.Context, op string, table string, key string, primary func(ctx context.Context) error, shadow func(ctx context.Context) error) error {
+	l := db.lock(table, key)
+	defer l.Unlock()
+
+	err, shadowErr := db.both(ctx, primary, shadow)
+	db.compareErrors(op, table, key, err, shadowErr)
+	return err
+}
+
+func (db *ShadowDB) Update(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return db.write(ctx, "update", table, key, func(ctx context.Context) error {
+		return db.DB.Update(ctx, table, key, values)
+	}, func(ctx context.Context) error {
+		return db.Shadow.Update(ctx, table, key, values)
+	})
+}
+
+func (db *ShadowDB) Insert(ctx context.Context, table string, key string, values map[string][]byte) error {
+	return db.write(ctx, "insert", table, key, func(ctx context.Context) error {
+		return db.DB.Insert(ctx, table, key, values)
+	}, func(ctx context.Context) error {
+		return db.Shadow.Insert(ctx, table, key, values)
+	})
+}
+
+func (db *ShadowDB) Delete(ctx context.Context, table string, key string) error {
+	return db.write(ctx, "delete", table, key, func(ctx context.Context) error {
+		return db.DB.Delete(ctx, table, key)
+	}, func(ctx context.Context) error {
+		return db.Shadow.Delete(ctx, table, key)
+	})
+}
+
+// The batch operations are split into single operations so every key is
+// compared and serialized on its own.
+
+func (db *ShadowDB) BatchInsert(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	for i := range keys {
+		if err := db.Insert(ctx, table, keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *ShadowDB) BatchRead(ctx context.Context, table string, keys []string, fields []string) ([]map[string][]byte, error) {
+	res := make([]map[string][]byte, 0, len(keys))
+	for _, key := range keys {
+		values, err := db.Read(ctx, table, key, fields)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, values)
+	}
+	return res, nil
+}
+
+func (db *ShadowDB) BatchUpdate(ctx context.Context, table string, keys []string, values []map[string][]byte) error {
+	for i := range keys {
+		if err := db.Update(ctx, table, keys[i], values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *ShadowDB) BatchDelete(ctx context.Context, table string, keys []string) error {
+	for _, key := range keys {
+		if err := db.Delete(ctx, table, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (db *ShadowDB) Analyze(ctx context.Context, table string) error {
+	if analyzeDB, ok := db.DB.(ycsb.AnalyzeDB); ok {
+		return analyzeDB.Analyze(ctx, table)
+	}
+	return nil
+}
+
+func (db *ShadowDB) Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error {
+	iterateDB, ok := db.DB.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db.DB)
+	}
+	return iterateDB.Iterate(ctx, table, fn)
+}
+
+func (db *ShadowDB) Stats(ctx context.Context) (map[string]interface{}, error) {
+	if statsDB, ok := db.DB.(ycsb.StatsDB); ok {
+		return statsDB.Stats(ctx)
+	}
+	return nil, nil
+}
+
+func (db *ShadowDB) DiskSize(ctx context.Context) (int64, error) {
+	sizeDB, ok := db.DB.(ycsb.SizeDB)
+	if !ok {
+		return 0, fmt.Errorf("the %T does't implement the SizeDB interface", db.DB)
+	}
+	return sizeDB.DiskSize(ctx)
+}
+
+// Report prints the divergences between the primary and the shadow and
+// returns their number.
+func (db *ShadowDB) Report() int64 {
+	divergences := atomic.LoadInt64(&db.divergences)
+
+	fmt.Println("***************** shadow *****************")
+	fmt.Printf("compared operations: %d, divergences: %d\n", atomic.LoadInt64(&db.compared), divergences)
+
+	db.reportMu.Lock()
+	for _, r := range db.reported {
+		fmt.Println(r)
+	}
+	db.reportMu.Unlock()
+	if divergences > int64(shadowMaxReported) {
+		fmt.Printf("... %d more divergences\n", divergences-int64(shadowMaxReported))
+	}
+	if db.enc != nil {
+		fmt.Printf("all the divergences are in %s\n", db.path)
+	}
+	return divergences
+}
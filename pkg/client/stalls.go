@@ -0,0 +1,166 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// stalls is the detector of the running client, nil if stalls are not detected.
+var stalls *stallDetector
+
+// stallMedianEvery is the number of writes after which the median write
+// latency is computed again, no stall is detected before the first median.
+const stallMedianEvery = 1000
+
+// stallWriteOps are the operations whose latencies are watched.
+var stallWriteOps = map[string]bool{
+	"INSERT":       true,
+	"UPDATE":       true,
+	"DELETE":       true,
+	"BATCH_INSERT": true,
+	"BATCH_UPDATE": true,
+	"BATCH_DELETE": true,
+}
+
+// stallDetector detects write stalls, the periods where the writes take
+// longer than a multiple of the median write latency for a number of
+// consecutive writes. Every stall is measured as WRITE_STALL and its window
+// is marked by annotations.
+type stallDetector struct {
+	multiple float64
+	ops      int
+
+	mu     sync.Mutex
+	hist   *hdrhistogram.Histogram
+	writes int64
+	median time.Duration
+
+	// slow is the number of consecutive slow writes, the first started at
+	// slowStart.
+	slow      int
+	slowStart time.Time
+	stalled   bool
+
+	count   int64
+	total   time.Duration
+	longest time.Duration
+}
+
+func newStallDetector(p *properties.Properties) *stallDetector {
+	multiple := p.GetFloat64(prop.StallMultiple, prop.StallMultipleDefault)
+	if multiple <= 0 {
+		return nil
+	}
+	ops := p.GetInt(prop.StallOps, prop.StallOpsDefault)
+	if ops <= 0 {
+		ops = 1
+	}
+
+	return &stallDetector{
+		multiple: multiple,
+		ops:      ops,
+		hist:     hdrhistogram.New(1, 24*60*60*1000*1000, 3),
+	}
+}
+
+// check counts the successful write in the consecutive slow writes.
+func (d *stallDetector) check(op string, start time.Time, lan time.Duration) {
+	if d == nil || !stallWriteOps[op] {
+		return
+	}
+
+	d.mu.Lock()
+	d.hist.RecordValue(lan.Microseconds())
+	d.writes++
+	if d.writes%stallMedianEvery == 0 {
+		d.median = time.Duration(d.hist.ValueAtPercentile(50)) * time.Microsecond
+	}
+	if d.median == 0 {
+		d.mu.Unlock()
+		return
+	}
+
+	if float64(lan) <= d.multiple*float64(d.median) {
+		if !d.stalled {
+			d.slow = 0
+			d.mu.Unlock()
+			return
+		}
+		stallStart, took := d.slowStart, d.end(start)
+		d.mu.Unlock()
+		measurement.Measure("WRITE_STALL", stallStart, took)
+		measurement.Annotate(fmt.Sprintf("write stall ended after %s", took.Round(time.Millisecond)))
+		return
+	}
+
+	if d.slow == 0 {
+		d.slowStart = start
+	}
+	d.slow++
+	started := !d.stalled && d.slow >= d.ops
+	if started {
+		d.stalled = true
+	}
+	median := d.median
+	d.mu.Unlock()
+	if started {
+		measurement.Annotate(fmt.Sprintf("write stall started, %d writes slower than %g times the median %s", d.ops, d.multiple, median))
+	}
+}
+
+// end ends the stall at the given time and returns how long it took.
+func (d *stallDetector) end(at time.Time) time.Duration {
+	took := at.Sub(d.slowStart)
+	d.stalled = false
+	d.slow = 0
+	d.count++
+	d.total += took
+	if took > d.longest {
+		d.longest = took
+	}
+	return took
+}
+
+// close ends the stall still going on when the run ends.
+func (d *stallDetector) close() {
+	d.mu.Lock()
+	if !d.stalled {
+		d.mu.Unlock()
+		return
+	}
+	stallStart, took := d.slowStart, d.end(time.Now())
+	d.mu.Unlock()
+	measurement.Measure("WRITE_STALL", stallStart, took)
+	measurement.Annotate(fmt.Sprintf("write stall ended with the run after %s", took.Round(time.Millisecond)))
+}
+
+func (d *stallDetector) report() {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.count == 0 {
+		fmt.Printf("[STALL] no write stalls, the median write latency is %s\n", d.median)
+		return
+	}
+	fmt.Printf("[STALL] %d write stalls took %s, the longest %s, the median write latency is %s\n",
+		d.count, d.total.Round(time.Millisecond), d.longest.Round(time.Millisecond), d.median)
+}
@@ -0,0 +1,130 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"syscall"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// targetControl is the target throughput of all the workers, it can be
+// changed while the workers run. changed is closed on every change.
+type targetControl struct {
+	mu      sync.Mutex
+	ops     int64
+	changed chan struct{}
+}
+
+// targetPaused is the target stopping the workers until it changes.
+const targetPaused = int64(-1)
+
+var target = &targetControl{changed: make(chan struct{})}
+
+// completedOps counts the operations done by all the workers after the warm
+// up, the failed ones are left out with measurement.successonly.
+var completedOps int64
+
+// hangupReload is set while SIGHUP reloads the target file instead of
+// stopping the program.
+var hangupReload int32
+
+// ReloadsOnHangup reports whether a SIGHUP reloads the target file.
+func ReloadsOnHangup() bool {
+	return atomic.LoadInt32(&hangupReload) == 1
+}
+
+// get returns the target in operations per second, 0 is unlimited and
+// targetPaused stops the workers, and the channel closed once it changes.
+func (t *targetControl) get() (int64, <-chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.ops, t.changed
+}
+
+func (t *targetControl) set(ops int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.ops = ops
+	close(t.changed)
+	t.changed = make(chan struct{})
+}
+
+// change sets the target and records the change as an annotation.
+func (t *targetControl) change(ops int64, source string) {
+	t.set(ops)
+	measurement.Annotate(fmt.Sprintf("target set to %d ops/sec by %s", ops, source))
+}
+
+// serveTarget returns the target, or sets it to the ops parameter.
+func serveTarget(w http.ResponseWriter, r *http.Request) {
+	if v := r.FormValue("ops"); v != "" {
+		ops, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || ops < 0 {
+			http.Error(w, fmt.Sprintf("invalid target %q", v), http.StatusBadRequest)
+			return
+		}
+		target.change(ops, "the control endpoint")
+	}
+
+	ops, _ := target.get()
+	fmt.Fprintf(w, "%d\n", ops)
+}
+
+// reloadTargetOnHangup sets the target to the target property of the target
+// file every time a SIGHUP is received.
+func reloadTargetOnHangup(ctx context.Context, path string) {
+	ch := make(chan os.Signal, 1)
+	signal.Notify(ch, syscall.SIGHUP)
+	atomic.StoreInt32(&hangupReload, 1)
+	defer func() {
+		atomic.StoreInt32(&hangupReload, 0)
+		signal.Stop(ch)
+	}()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ch:
+		}
+
+		p, err := properties.LoadFile(path, properties.UTF8)
+		if err != nil {
+			fmt.Printf("[TARGET] reload %s failed %v\n", path, err)
+			continue
+		}
+		ops := p.GetInt64(prop.Target, -1)
+		if ops < 0 {
+			fmt.Printf("[TARGET] %s has no valid %s\n", path, prop.Target)
+			continue
+		}
+		target.change(ops, path)
+	}
+}
+
+func init() {
+	// served on the debug.pprof address.
+	http.HandleFunc("/target", serveTarget)
+}
@@ -0,0 +1,102 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// verifierPrinted is the number of wrong rows printed, the later ones are
+// only counted.
+const verifierPrinted = 10
+
+// verifier reads random rows during the run and checks them, so a long run
+// gives a correctness signal without a verification phase. Its reads go to
+// the database under the measurements, so they aren't in the results.
+type verifier struct {
+	interval time.Duration
+	workload ycsb.Workload
+	db       ycsb.DB
+
+	done    chan struct{}
+	checked int64
+	failed  int64
+}
+
+func newVerifier(p *properties.Properties, workload ycsb.Workload, db ycsb.DB) *verifier {
+	rate := p.GetFloat64(prop.VerifyRate, prop.VerifyRateDefault)
+	if rate <= 0 || !p.GetBool(prop.DoTransactions, true) {
+		return nil
+	}
+	if _, ok := workload.(ycsb.VerifyWorkload); !ok {
+		fmt.Printf("[VERIFY] the %T workload can't verify its rows\n", workload)
+		return nil
+	}
+	if w, ok := db.(DbWrapper); ok {
+		db = w.DB
+	}
+
+	return &verifier{
+		interval: time.Duration(float64(time.Second) / rate),
+		workload: workload,
+		db:       db,
+		done:     make(chan struct{}),
+	}
+}
+
+// run checks a row every interval until the context is done, on a thread of
+// its own after the threadCount workers.
+func (v *verifier) run(ctx context.Context, threadCount int) {
+	defer close(v.done)
+
+	ctx = v.workload.InitThread(ctx, threadCount, threadCount+1)
+	defer v.workload.CleanupThread(ctx)
+	ctx = v.db.InitThread(ctx, threadCount, threadCount+1)
+	defer v.db.CleanupThread(ctx)
+
+	t := time.NewTicker(v.interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		}
+
+		key, err := v.workload.(ycsb.VerifyWorkload).VerifyRow(ctx, v.db)
+		if key == "" {
+			continue
+		}
+		v.checked++
+		if err == nil || ctx.Err() != nil {
+			continue
+		}
+		v.failed++
+		if v.failed <= verifierPrinted {
+			fmt.Printf("[VERIFY] %s: %v\n", key, err)
+		}
+	}
+}
+
+// report waits for the run to stop and prints the number of rows checked.
+func (v *verifier) report() {
+	<-v.done
+	fmt.Printf("[VERIFY] %d rows checked, %d wrong\n", v.checked, v.failed)
+}
@@ -0,0 +1,161 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package client
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime/pprof"
+	"sort"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// watchdog detects runs where no operation completes for a configured time,
+// which usually means a wedged transaction inside the engine.
+type watchdog struct {
+	timeout time.Duration
+	abort   bool
+	dir     string
+	db      ycsb.DB
+
+	// completed is bumped by the workers after every finished operation.
+	completed int64
+}
+
+func newWatchdog(p *properties.Properties, db ycsb.DB) *watchdog {
+	timeout := p.GetParsedDuration(prop.WatchdogTimeout, prop.WatchdogTimeoutDefault)
+	if timeout <= 0 {
+		return nil
+	}
+
+	return &watchdog{
+		timeout: timeout,
+		abort:   p.GetBool(prop.WatchdogAbort, prop.WatchdogAbortDefault),
+		dir:     p.GetString(prop.OutputDir, prop.OutputDirDefault),
+		db:      db,
+	}
+}
+
+func (w *watchdog) done() {
+	if w != nil {
+		atomic.AddInt64(&w.completed, 1)
+	}
+}
+
+func (w *watchdog) run(ctx context.Context, cancel context.CancelCauseFunc) {
+	interval := w.timeout / 4
+	if interval <= 0 {
+		interval = w.timeout
+	}
+	t := time.NewTicker(interval)
+	defer t.Stop()
+
+	last := atomic.LoadInt64(&w.completed)
+	lastProgress := time.Now()
+	dumped := false
+	ops, changed := target.get()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-t.C:
+		case <-changed:
+			// the operations stop on purpose while the target is paused,
+			// and the wait starts over once it changes.
+			ops, changed = target.get()
+			lastProgress = time.Now()
+			dumped = false
+			continue
+		}
+
+		if ops == targetPaused {
+			lastProgress = time.Now()
+			continue
+		}
+
+		completed := atomic.LoadInt64(&w.completed)
+		if completed != last {
+			last = completed
+			lastProgress = time.Now()
+			dumped = false
+			continue
+		}
+
+		stalled := time.Since(lastProgress)
+		if stalled < w.timeout || dumped {
+			continue
+		}
+		dumped = true
+
+		fmt.Printf("[WATCHDOG] no operation completed for %s\n", stalled.Round(time.Millisecond))
+		if path, err := w.dump(ctx, stalled); err != nil {
+			fmt.Printf("[WATCHDOG] write stall dump failed %v\n", err)
+		} else {
+			fmt.Printf("[WATCHDOG] stall dump written to %s\n", path)
+		}
+
+		if w.abort {
+			fmt.Println("[WATCHDOG] aborting the run")
+			cancel(fmt.Errorf("the watchdog aborted the run, no operation completed for %s", stalled.Round(time.Millisecond)))
+			return
+		}
+	}
+}
+
+func (w *watchdog) dump(ctx context.Context, stalled time.Duration) (string, error) {
+	if err := os.MkdirAll(w.dir, 0755); err != nil {
+		return "", err
+	}
+
+	path := filepath.Join(w.dir, fmt.Sprintf("stall-%d.txt", time.Now().Unix()))
+	f, err := os.Create(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	bw := bufio.NewWriter(f)
+	fmt.Fprintf(bw, "no operation completed for %s, %d operations completed in total\n\n", stalled, atomic.LoadInt64(&w.completed))
+
+	fmt.Fprintln(bw, "***************** engine stats *****************")
+	if statsDB, ok := w.db.(ycsb.StatsDB); ok {
+		stats, err := statsDB.Stats(ctx)
+		if err != nil {
+			fmt.Fprintf(bw, "get engine stats failed %v\n", err)
+		}
+		keys := make([]string, 0, len(stats))
+		for k := range stats {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(bw, "%s=%v\n", k, stats[k])
+		}
+	}
+
+	fmt.Fprintln(bw, "\n***************** goroutines *****************")
+	if err := pprof.Lookup("goroutine").WriteTo(bw, 2); err != nil {
+		return "", err
+	}
+
+	return path, bw.Flush()
+}
@@ -0,0 +1,48 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"math/rand"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// Bounded stops an increasing sequence at an end, it generates -1 once the
+// sequence reaches it.
+type Bounded struct {
+	g   ycsb.Generator
+	end int64
+}
+
+// NewBounded creates the Bounded generator over g with the end excluded.
+func NewBounded(g ycsb.Generator, end int64) *Bounded {
+	return &Bounded{
+		g:   g,
+		end: end,
+	}
+}
+
+// Next implements Generator Next interface.
+func (b *Bounded) Next(r *rand.Rand) int64 {
+	if n := b.g.Next(r); n < b.end {
+		return n
+	}
+	return -1
+}
+
+// Last implements Generator Last interface.
+func (b *Bounded) Last() int64 {
+	return min(b.g.Last(), b.end-1)
+}
@@ -0,0 +1,43 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package generator
+
+import (
+	"math/rand"
+	"sync/atomic"
+)
+
+// Stripe generates a strided sequence of integers. [start, start+stride, ...]
+type Stripe struct {
+	counter int64
+	stride  int64
+}
+
+// NewStripe creates the Stripe generator.
+func NewStripe(start int64, stride int64) *Stripe {
+	return &Stripe{
+		counter: start,
+		stride:  stride,
+	}
+}
+
+// Next implements Generator Next interface.
+func (s *Stripe) Next(_ *rand.Rand) int64 {
+	return atomic.AddInt64(&s.counter, s.stride) - s.stride
+}
+
+// Last implements Generator Last interface.
+func (s *Stripe) Last() int64 {
+	return atomic.LoadInt64(&s.counter) - s.stride
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// annotationsFile is the file in the output directory the annotations are
+// appended to.
+const annotationsFile = "annotations.jsonl"
+
+// Annotation is an external event recorded during the run, such as an
+// operator dropping the caches, to be matched with latency excursions.
+type Annotation struct {
+	Time    time.Time `json:"time"`
+	Elapsed float64   `json:"elapsed"`
+	Text    string    `json:"text"`
+}
+
+var annotations struct {
+	sync.Mutex
+	start time.Time
+	list  []Annotation
+}
+
+// Annotate records an annotation. It is printed in between the periodic
+// summaries and appended to the annotations file in the output directory.
+func Annotate(text string) {
+	now := time.Now()
+
+	annotations.Lock()
+	defer annotations.Unlock()
+
+	a := Annotation{Time: now, Elapsed: now.Sub(annotations.start).Seconds(), Text: text}
+	annotations.list = append(annotations.list, a)
+	fmt.Printf("[ANNOTATION] %s (%.1fs): %s\n", now.Format(time.RFC3339), a.Elapsed, text)
+
+	if err := appendAnnotation(a); err != nil {
+		fmt.Printf("[ANNOTATION] write %s failed %v\n", annotationsFile, err)
+	}
+}
+
+func appendAnnotation(a Annotation) error {
+	dir := globalMeasure.p.GetString(prop.OutputDir, prop.OutputDirDefault)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(filepath.Join(dir, annotationsFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return json.NewEncoder(f).Encode(a)
+}
+
+// Annotations returns the annotations recorded after the first from ones and
+// the total number of annotations, to be passed as from in the next call.
+func Annotations(from int) ([]Annotation, int) {
+	annotations.Lock()
+	defer annotations.Unlock()
+
+	n := len(annotations.list)
+	if from >= n {
+		return nil, n
+	}
+	return append([]Annotation(nil), annotations.list[from:]...), n
+}
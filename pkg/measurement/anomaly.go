@@ -0,0 +1,203 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// anomalyMinSamples is the number of intervals below which the median doesn't
+// describe the steady state of the run.
+const anomalyMinSamples = 5
+
+// anomalyMinChange is the change from the median, as a share of it, an
+// interval must exceed as well, so a steady series with a MAD close to 0
+// doesn't flag every jitter.
+const anomalyMinChange = 0.1
+
+// anomalyMetrics are the metrics of the intervals searched for anomalies.
+var anomalyMetrics = []struct {
+	name string
+	unit string
+	// high is whether the anomalies are above the median, the throughput
+	// cliffs are below.
+	high  bool
+	value func(s IntervalStats) float64
+}{
+	{"OPS", "ops/sec", false, func(s IntervalStats) float64 { return s.OPS }},
+	{"99th", "us", true, func(s IntervalStats) float64 { return float64(s.Per99th) }},
+}
+
+type anomalySample struct {
+	from  time.Time
+	to    time.Time
+	stats map[string]IntervalStats
+}
+
+// anomalySeries samples the interval statistics for the anomalies listed at
+// the end of the run.
+type anomalySeries struct {
+	k       float64
+	start   time.Time
+	last    time.Time
+	samples []anomalySample
+}
+
+func newAnomalySeries(p *properties.Properties) *anomalySeries {
+	if !p.GetBool(prop.MeasurementAnomalies, prop.MeasurementAnomaliesDefault) {
+		return nil
+	}
+	now := time.Now()
+	return &anomalySeries{
+		k:     p.GetFloat64(prop.MeasurementAnomaliesK, prop.MeasurementAnomaliesKDefault),
+		start: now,
+		last:  now,
+	}
+}
+
+// anomaly is a run of consecutive intervals where a metric of an operation
+// is off the median.
+type anomaly struct {
+	op     string
+	metric int
+	from   time.Time
+	to     time.Time
+	worst  float64
+	median float64
+}
+
+// sampleAnomalies adds the interval since the previous sample to the series.
+// The first one only starts the interval histograms.
+func (m *measurement) sampleAnomalies() {
+	m.Lock()
+	defer m.Unlock()
+
+	s := m.anomalies
+	h, ok := m.measurer.(*histograms)
+	if s == nil || !ok {
+		return
+	}
+	now := time.Now()
+	stats := h.window(now.Sub(s.last))
+	if len(stats) > 0 {
+		s.samples = append(s.samples, anomalySample{from: s.last, to: now, stats: stats})
+	}
+	s.last = now
+}
+
+// detectAnomalies returns the runs of intervals where a metric of an
+// operation is beyond k times the MAD of its series, by start time.
+func detectAnomalies(samples []anomalySample, k float64) []anomaly {
+	ops := make(map[string]struct{})
+	for _, s := range samples {
+		for op := range s.stats {
+			if !IsFailure(op) {
+				ops[op] = struct{}{}
+			}
+		}
+	}
+
+	var found []anomaly
+	for op := range ops {
+		for metric, am := range anomalyMetrics {
+			var values []float64
+			var index []int
+			for i, s := range samples {
+				st, ok := s.stats[op]
+				// an operation missing from an interval has no latency but
+				// a throughput of 0.
+				if !ok && am.high {
+					continue
+				}
+				values = append(values, am.value(st))
+				index = append(index, i)
+			}
+			if len(values) < anomalyMinSamples {
+				continue
+			}
+
+			median, mad := util.MedianAbsDeviation(values)
+			limit := math.Max(k*mad, anomalyMinChange*median)
+			run := -1
+			for j, v := range values {
+				off := v - median
+				if !am.high {
+					off = -off
+				}
+				if off <= limit {
+					run = -1
+					continue
+				}
+				if run >= 0 && index[j] == index[j-1]+1 {
+					a := &found[run]
+					a.to = samples[index[j]].to
+					if (am.high && v > a.worst) || (!am.high && v < a.worst) {
+						a.worst = v
+					}
+					continue
+				}
+				found = append(found, anomaly{
+					op:     op,
+					metric: metric,
+					from:   samples[index[j]].from,
+					to:     samples[index[j]].to,
+					worst:  v,
+					median: median,
+				})
+				run = len(found) - 1
+			}
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if !found[i].from.Equal(found[j].from) {
+			return found[i].from.Before(found[j].from)
+		}
+		return found[i].op < found[j].op
+	})
+	return found
+}
+
+// outputAnomalies writes the anomalies of the series, it must be called with
+// the lock held.
+func (m *measurement) outputAnomalies(w io.Writer) {
+	s := m.anomalies
+	if s == nil {
+		return
+	}
+	if len(s.samples) < anomalyMinSamples {
+		fmt.Fprintf(w, "[ANOMALY] %d intervals sampled, at least %d are needed\n", len(s.samples), anomalyMinSamples)
+		return
+	}
+
+	found := detectAnomalies(s.samples, s.k)
+	if len(found) == 0 {
+		fmt.Fprintf(w, "[ANOMALY] none in %d intervals\n", len(s.samples))
+		return
+	}
+	for _, a := range found {
+		am := anomalyMetrics[a.metric]
+		fmt.Fprintf(w, "[ANOMALY] %s (%.1fs) for %s: %s %s %.1f %s, median %.1f %s\n",
+			a.from.Format(time.RFC3339), a.from.Sub(s.start).Seconds(), a.to.Sub(a.from).Round(time.Second),
+			a.op, am.name, a.worst, am.unit, a.median, am.unit)
+	}
+}
@@ -0,0 +1,78 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestDetectAnomalies(t *testing.T) {
+	constant := func(n int, v int64) []int64 {
+		return slices.Repeat([]int64{v}, n)
+	}
+
+	// a negative OPS leaves READ out of the interval.
+	cases := []struct {
+		name string
+		ops  []float64
+		p99  []int64
+		want []string
+	}{
+		{"steady", []float64{100, 101, 99, 100, 100, 100, 101, 99}, constant(8, 10), nil},
+		{"too few intervals", []float64{100, 0, 100, 100}, constant(4, 10), nil},
+		{"under the MAD floor", []float64{100, 100, 100, 100, 92, 100, 100}, constant(7, 10), nil},
+		{"over the MAD floor", []float64{100, 100, 100, 100, 85, 100, 100}, constant(7, 10), []string{"OPS 4-4 85"}},
+		{"beyond k MADs", []float64{100, 80, 120, 100, 90, 110, 100, 60}, constant(8, 10), []string{"OPS 7-7 60"}},
+		{"cliff run", []float64{100, 100, 60, 40, 50, 100, 100, 100}, constant(8, 10), []string{"OPS 2-4 40"}},
+		{"two cliffs", []float64{100, 50, 100, 40, 100, 100, 100}, constant(7, 10), []string{"OPS 1-1 50", "OPS 3-3 40"}},
+		{"spike run", []float64{100, 100, 100, 100, 100, 100, 100}, []int64{10, 10, 30, 50, 20, 10, 10}, []string{"99th 2-4 50"}},
+		{
+			"gap in a spike",
+			[]float64{100, 100, 100, 100, -1, 100, 100, 100},
+			[]int64{10, 10, 10, 50, 0, 60, 10, 10},
+			[]string{"99th 3-3 50", "OPS 4-4 0", "99th 5-5 60"},
+		},
+	}
+	start := time.Unix(0, 0)
+	for _, c := range cases {
+		var samples []anomalySample
+		for i := range c.ops {
+			s := anomalySample{
+				from: start.Add(time.Duration(i) * time.Second),
+				to:   start.Add(time.Duration(i+1) * time.Second),
+				// failures are left out whatever their values.
+				stats: map[string]IntervalStats{"READ_ERROR": {OPS: float64(i * 1000), Per99th: int64(i * 1000)}},
+			}
+			if c.ops[i] >= 0 {
+				s.stats["READ"] = IntervalStats{OPS: c.ops[i], Per99th: c.p99[i]}
+			}
+			samples = append(samples, s)
+		}
+
+		var got []string
+		for _, a := range detectAnomalies(samples, 3) {
+			if a.op != "READ" {
+				t.Fatalf("expect %s to only flag READ, but got %s", c.name, a.op)
+			}
+			got = append(got, fmt.Sprintf("%s %d-%d %v", anomalyMetrics[a.metric].name,
+				int(a.from.Sub(start)/time.Second), int(a.to.Sub(start)/time.Second)-1, a.worst))
+		}
+		if !slices.Equal(got, c.want) {
+			t.Fatalf("expect %s to find %v, but got %v", c.name, c.want, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// opBytes are the logical bytes of every operation, *byteCounter keyed by
+// the operation.
+var opBytes sync.Map
+
+type byteCounter struct {
+	read    int64
+	written int64
+}
+
+// AddBytes counts the logical bytes, the lengths of the field values, the
+// operation read and wrote.
+func AddBytes(op string, read int64, written int64) {
+	if !IsWarmUpFinished() {
+		return
+	}
+	v, ok := opBytes.Load(op)
+	if !ok {
+		v, _ = opBytes.LoadOrStore(op, new(byteCounter))
+	}
+	c := v.(*byteCounter)
+	atomic.AddInt64(&c.read, read)
+	atomic.AddInt64(&c.written, written)
+}
+
+func resetBytes() {
+	opBytes.Range(func(key, _ interface{}) bool {
+		opBytes.Delete(key)
+		return true
+	})
+}
+
+// bytesOf returns the bytes the operation read and wrote.
+func bytesOf(op string) (read int64, written int64) {
+	v, ok := opBytes.Load(op)
+	if !ok {
+		return 0, 0
+	}
+	c := v.(*byteCounter)
+	return atomic.LoadInt64(&c.read), atomic.LoadInt64(&c.written)
+}
+
+func mbps(bytes int64, elapsed float64) float64 {
+	if elapsed <= 0 {
+		return 0
+	}
+	return float64(bytes) / elapsed / (1 << 20)
+}
+
+// bandwidthOps returns the operations with bytes in the results, sorted.
+func bandwidthOps(results map[string]Result) []string {
+	var ops []string
+	for op := range results {
+		if read, written := bytesOf(op); read+written > 0 {
+			ops = append(ops, op)
+		}
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+// summaryBandwidth prints the bandwidth of every operation in one line.
+func (m *measurement) summaryBandwidth() {
+	results := m.results()
+	ops := bandwidthOps(results)
+	if len(ops) == 0 {
+		return
+	}
+	parts := make([]string, 0, len(ops))
+	for _, op := range ops {
+		parts = append(parts, fmt.Sprintf("%s %.1f MB/s", op, results[op].MBps))
+	}
+	fmt.Printf("[BANDWIDTH] %s\n", strings.Join(parts, ", "))
+}
+
+func (m *measurement) outputBandwidth(w io.Writer) {
+	results := m.results()
+	for _, op := range bandwidthOps(results) {
+		read, written := bytesOf(op)
+		fmt.Fprintf(w, "[BANDWIDTH] %s: read %.1f MB, written %.1f MB, %.1f MB/s at %.1f ops/sec\n",
+			op, float64(read)/(1<<20), float64(written)/(1<<20), results[op].MBps, results[op].OPS)
+	}
+}
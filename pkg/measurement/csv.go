@@ -4,43 +4,50 @@ import (
 	"fmt"
 	"io"
 	"time"
+
+	"github.com/magiconair/properties"
 )
 
 type csventry struct {
 	// start time of the operation in us from unix epoch
 	startUs int64
-	// latency of the operation in us
-	latencyUs int64
+	// latency of the operation in the unit of the csvs
+	latency int64
 }
 
 type csvs struct {
-	opCsv map[string][]csventry
+	unit     time.Duration
+	unitName string
+	opCsv    map[string][]csventry
 }
 
 func (c *csvs) GenerateExtendedOutputs() {
 }
 
-func InitCSV() *csvs {
+func InitCSV(p *properties.Properties) *csvs {
+	unit, unitName := latencyUnit(p)
 	return &csvs{
-		opCsv: make(map[string][]csventry),
+		unit:     unit,
+		unitName: unitName,
+		opCsv:    make(map[string][]csventry),
 	}
 }
 
 func (c *csvs) Measure(op string, start time.Time, lan time.Duration) {
 	c.opCsv[op] = append(c.opCsv[op], csventry{
-		startUs:   start.UnixMicro(),
-		latencyUs: lan.Microseconds(),
+		startUs: start.UnixMicro(),
+		latency: int64(lan / c.unit),
 	})
 }
 
 func (c *csvs) Output(w io.Writer) error {
-	_, err := fmt.Fprintln(w, "operation,timestamp_us,latency_us")
+	_, err := fmt.Fprintf(w, "operation,timestamp_us,latency_%s\n", c.unitName)
 	if err != nil {
 		return err
 	}
 	for op, entries := range c.opCsv {
 		for _, entry := range entries {
-			_, err := fmt.Fprintf(w, "%s,%d,%d\n", op, entry.startUs, entry.latencyUs)
+			_, err := fmt.Fprintf(w, "%s,%d,%d\n", op, entry.startUs, entry.latency)
 			if err != nil {
 				return err
 			}
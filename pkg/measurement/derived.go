@@ -0,0 +1,127 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+// derivedPrefix is the prefix of the derived metric properties, e.g.
+// metric.cost_per_mop=watts*elapsed/(ops/1e6).
+const derivedPrefix = "metric."
+
+// resultVars are the variables of the results of an operation by their suffix.
+var resultVars = map[string]func(r Result) float64{
+	"count": func(r Result) float64 { return float64(r.Count) },
+	"ops":   func(r Result) float64 { return r.OPS },
+	"avg":   func(r Result) float64 { return float64(r.Avg) },
+	"p50":   func(r Result) float64 { return float64(r.P50) },
+	"p90":   func(r Result) float64 { return float64(r.P90) },
+	"p95":   func(r Result) float64 { return float64(r.P95) },
+	"p99":   func(r Result) float64 { return float64(r.P99) },
+	"p999":  func(r Result) float64 { return float64(r.P999) },
+	"p9999": func(r Result) float64 { return float64(r.P9999) },
+}
+
+type derivedMetric struct {
+	name string
+	expr util.Expr
+}
+
+// parseDerived parses the derived metrics of the properties.
+func parseDerived(p *properties.Properties) []derivedMetric {
+	defs := p.FilterStripPrefix(derivedPrefix)
+	names := defs.Keys()
+	sort.Strings(names)
+
+	metrics := make([]derivedMetric, 0, len(names))
+	for _, name := range names {
+		s, _ := defs.Get(name)
+		expr, err := util.ParseExpr(s)
+		if err != nil {
+			util.Fatalf("invalid metric %s: %v", name, err)
+		}
+		metrics = append(metrics, derivedMetric{name: name, expr: expr})
+	}
+	return metrics
+}
+
+// derivedLookup resolves the variables of the derived metrics: elapsed is the
+// measured time in seconds, ops the number of successful operations, failed
+// the number of failed ones, <OP>.count, <OP>.ops, <OP>.avg and <OP>.p50 to
+// <OP>.p9999 the results of an operation, the latencies in microseconds. The
+// variables set with SetVariable come next and other names are numeric
+// properties.
+func derivedLookup(p *properties.Properties, results map[string]Result, extra map[string]float64) func(string) (float64, bool) {
+	vars := make(map[string]float64)
+	for op, r := range results {
+		if r.Elapsed > vars["elapsed"] {
+			vars["elapsed"] = r.Elapsed
+		}
+		for suffix, value := range resultVars {
+			vars[op+"."+suffix] = value(r)
+		}
+	}
+	vars["ops"] = vars["TOTAL.count"]
+	vars["failed"] = float64(failures(results))
+
+	return func(name string) (float64, bool) {
+		if v, ok := vars[name]; ok {
+			return v, true
+		}
+		if v, ok := extra[name]; ok {
+			return v, true
+		}
+		if v, ok := p.Get(name); ok {
+			f, err := strconv.ParseFloat(v, 64)
+			return f, err == nil
+		}
+		// the results of an operation that didn't happen are 0.
+		i := strings.LastIndexByte(name, '.')
+		_, ok := resultVars[name[i+1:]]
+		return 0, i > 0 && ok
+	}
+}
+
+// outputDerived writes the derived metrics, it must be called with the lock held.
+func (m *measurement) outputDerived(w io.Writer) {
+	if len(m.derived) == 0 {
+		return
+	}
+
+	lookup := derivedLookup(m.p, m.results(), m.vars)
+	for _, d := range m.derived {
+		v, err := d.expr.Eval(lookup)
+		if err != nil {
+			fmt.Fprintf(w, "[METRIC] %s: %v\n", d.name, err)
+			continue
+		}
+		fmt.Fprintf(w, "[METRIC] %s: %.3f\n", d.name, v)
+	}
+}
+
+// SetVariable sets a variable of the derived metrics, like the energy used by
+// the run.
+func SetVariable(name string, v float64) {
+	globalMeasure.Lock()
+	globalMeasure.vars[name] = v
+	globalMeasure.Unlock()
+}
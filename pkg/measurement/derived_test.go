@@ -0,0 +1,63 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+)
+
+func TestDerivedLookup(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set("threadcount", "4")
+	p.Set("watts", "1")
+	p.Set("dbname", "fredb")
+
+	results := map[string]Result{
+		"READ":             {Elapsed: 10, Count: 100, OPS: 10, P99: 250},
+		"TOTAL":            {Elapsed: 12, Count: 150},
+		"READ_ERROR":       {Elapsed: 5, Count: 3},
+		"UPDATE_NOT_FOUND": {Elapsed: 5, Count: 2},
+	}
+	extra := map[string]float64{"watts": 30, "ops": 1}
+	lookup := derivedLookup(p, results, extra)
+
+	cases := []struct {
+		name string
+		want float64
+		ok   bool
+	}{
+		{"elapsed", 12, true},
+		{"ops", 150, true},
+		{"failed", 5, true},
+		{"READ.count", 100, true},
+		{"READ.ops", 10, true},
+		{"READ.p99", 250, true},
+		{"READ_ERROR.count", 3, true},
+		{"watts", 30, true},
+		{"threadcount", 4, true},
+		{"dbname", 0, false},
+		{"SCAN.p99", 0, true},
+		{"SCAN.p42", 0, false},
+		{".p99", 0, false},
+		{"unknown", 0, false},
+	}
+	for _, c := range cases {
+		v, ok := lookup(c.name)
+		if ok != c.ok || (ok && v != c.want) {
+			t.Fatalf("expect %s to be %v %v, but got %v %v", c.name, c.want, c.ok, v, ok)
+		}
+	}
+}
@@ -22,9 +22,16 @@ import (
 )
 
 type histogram struct {
+	// unit is the unit of the recorded latencies.
+	unit        time.Duration
 	boundCounts util.ConcurrentMap
 	startTime   time.Time
 	hist        *hdrhistogram.Histogram
+	// interval is only created once the interval statistics are requested.
+	interval *hdrhistogram.Histogram
+	// window is the interval of the anomaly series, apart from the one of the
+	// dashboard as they are sampled at different rates.
+	window *hdrhistogram.Histogram
 }
 
 // Metric name.
@@ -43,15 +50,34 @@ const (
 	PER9999TH = "PER9999TH"
 )
 
-func newHistogram() *histogram {
+// newLatencyHistogram returns a histogram of latencies in the unit up to a
+// day.
+func newLatencyHistogram(unit time.Duration) *hdrhistogram.Histogram {
+	return hdrhistogram.New(1, int64(24*time.Hour/unit), 3)
+}
+
+func newHistogram(unit time.Duration) *histogram {
 	h := new(histogram)
+	h.unit = unit
 	h.startTime = time.Now()
-	h.hist = hdrhistogram.New(1, 24*60*60*1000*1000, 3)
+	h.hist = newLatencyHistogram(unit)
 	return h
 }
 
 func (h *histogram) Measure(latency time.Duration) {
-	h.hist.RecordValue(latency.Microseconds())
+	v := int64(latency / h.unit)
+	h.hist.RecordValue(v)
+	if h.interval != nil {
+		h.interval.RecordValue(v)
+	}
+	if h.window != nil {
+		h.window.RecordValue(v)
+	}
+}
+
+// micros converts a latency in the unit of the histogram to microseconds.
+func (h *histogram) micros(v int64) int64 {
+	return v * int64(h.unit) / int64(time.Microsecond)
 }
 
 func (h *histogram) Summary() []string {
@@ -16,6 +16,9 @@ import (
 type histograms struct {
 	p *properties.Properties
 
+	unit     time.Duration
+	unitName string
+
 	histograms map[string]*histogram
 }
 
@@ -44,7 +47,7 @@ func (h *histograms) GenerateExtendedOutputs() {
 func (h *histograms) Measure(op string, start time.Time, lan time.Duration) {
 	opM, ok := h.histograms[op]
 	if !ok {
-		opM = newHistogram()
+		opM = newHistogram(h.unit)
 		h.histograms[op] = opM
 	}
 
@@ -81,11 +84,11 @@ func (h *histograms) Output(w io.Writer) error {
 	outputStyle := h.p.GetString(prop.OutputStyle, util.OutputStylePlain)
 	switch outputStyle {
 	case util.OutputStylePlain:
-		util.RenderString(w, "%-6s - %s\n", header, lines)
+		util.RenderString(w, "%-6s - %s\n", header(h.unitName), lines)
 	case util.OutputStyleJson:
-		util.RenderJson(w, header, lines)
+		util.RenderJson(w, header(h.unitName), lines)
 	case util.OutputStyleTable:
-		util.RenderTable(w, header, lines)
+		util.RenderTable(w, header(h.unitName), lines)
 	default:
 		panic("unsupported outputstyle: " + outputStyle)
 	}
@@ -93,8 +96,11 @@ func (h *histograms) Output(w io.Writer) error {
 }
 
 func InitHistograms(p *properties.Properties) *histograms {
+	unit, unitName := latencyUnit(p)
 	return &histograms{
 		p:          p,
+		unit:       unit,
+		unitName:   unitName,
 		histograms: make(map[string]*histogram, 16),
 	}
 }
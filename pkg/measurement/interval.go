@@ -0,0 +1,90 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"time"
+
+	hdrhistogram "github.com/HdrHistogram/hdrhistogram-go"
+)
+
+// IntervalStats are the statistics of an operation in one interval, the
+// latencies are in microseconds.
+type IntervalStats struct {
+	Count    int64   `json:"count"`
+	OPS      float64 `json:"ops"`
+	Avg      int64   `json:"avg"`
+	Max      int64   `json:"max"`
+	Per50th  int64   `json:"p50"`
+	Per99th  int64   `json:"p99"`
+	Per999th int64   `json:"p999"`
+}
+
+type intervalMeasurer interface {
+	interval(elapsed time.Duration) map[string]IntervalStats
+}
+
+func (h *histograms) interval(elapsed time.Duration) map[string]IntervalStats {
+	return h.drain(elapsed, func(opM *histogram) **hdrhistogram.Histogram { return &opM.interval })
+}
+
+// window returns the statistics of the anomaly series, see interval.
+func (h *histograms) window(elapsed time.Duration) map[string]IntervalStats {
+	return h.drain(elapsed, func(opM *histogram) **hdrhistogram.Histogram { return &opM.window })
+}
+
+// drain returns the statistics of the interval histograms of the field and
+// resets them. The histograms are created at the first call, so the
+// operations before it are only in the totals.
+func (h *histograms) drain(elapsed time.Duration, field func(opM *histogram) **hdrhistogram.Histogram) map[string]IntervalStats {
+	stats := make(map[string]IntervalStats, len(h.histograms))
+	for op, opM := range h.histograms {
+		f := field(opM)
+		if *f == nil {
+			*f = newLatencyHistogram(opM.unit)
+			continue
+		}
+
+		hist := *f
+		stats[op] = IntervalStats{
+			Count:    hist.TotalCount(),
+			OPS:      float64(hist.TotalCount()) / elapsed.Seconds(),
+			Avg:      opM.micros(int64(hist.Mean())),
+			Max:      opM.micros(hist.Max()),
+			Per50th:  opM.micros(hist.ValueAtPercentile(50)),
+			Per99th:  opM.micros(hist.ValueAtPercentile(99)),
+			Per999th: opM.micros(hist.ValueAtPercentile(99.9)),
+		}
+		hist.Reset()
+	}
+	return stats
+}
+
+// Interval returns the statistics of every operation since the previous call
+// and starts a new interval. It returns nil if the measurement type doesn't
+// keep histograms.
+func Interval() map[string]IntervalStats {
+	globalMeasure.Lock()
+	defer globalMeasure.Unlock()
+
+	im, ok := globalMeasure.measurer.(intervalMeasurer)
+	if !ok {
+		return nil
+	}
+
+	now := time.Now()
+	elapsed := now.Sub(globalMeasure.intervalStart)
+	globalMeasure.intervalStart = now
+	return im.interval(elapsed)
+}
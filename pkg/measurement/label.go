@@ -0,0 +1,45 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import "context"
+
+type labelKey struct{}
+
+// WithLabel returns a context whose operations are also measured under
+// "<label>-<operation>", e.g. to break down the measurements of a composed
+// workload.
+func WithLabel(ctx context.Context, label string) context.Context {
+	return context.WithValue(ctx, labelKey{}, label)
+}
+
+// Label returns the measurement label of the context.
+func Label(ctx context.Context) string {
+	label, _ := ctx.Value(labelKey{}).(string)
+	return label
+}
+
+type unmeasuredKey struct{}
+
+// WithoutMeasurement returns a context whose operations aren't measured, for
+// the checks a workload makes around the operation it measures.
+func WithoutMeasurement(ctx context.Context) context.Context {
+	return context.WithValue(ctx, unmeasuredKey{}, true)
+}
+
+// Unmeasured reports whether the operations of the context aren't measured.
+func Unmeasured(ctx context.Context) bool {
+	unmeasured, _ := ctx.Value(unmeasuredKey{}).(bool)
+	return unmeasured
+}
@@ -15,6 +15,7 @@ package measurement
 
 import (
 	"bufio"
+	"fmt"
 	"os"
 	"sync"
 	"sync/atomic"
@@ -25,7 +26,31 @@ import (
 	"github.com/pingcap/go-ycsb/pkg/ycsb"
 )
 
-var header = []string{"Operation", "Takes(s)", "Count", "OPS", "Avg(us)", "Min(us)", "Max(us)", "50th(us)", "90th(us)", "95th(us)", "99th(us)", "99.9th(us)", "99.99th(us)"}
+// header returns the header of the summaries with the latencies in the unit.
+func header(unit string) []string {
+	h := []string{"Operation", "Takes(s)", "Count", "OPS"}
+	for _, name := range []string{"Avg", "Min", "Max", "50th", "90th", "95th", "99th", "99.9th", "99.99th"} {
+		h = append(h, name+"("+unit+")")
+	}
+	return h
+}
+
+// latencyUnits are the units of prop.MeasurementUnit.
+var latencyUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"ms": time.Millisecond,
+}
+
+// latencyUnit returns the unit of the latencies and its name.
+func latencyUnit(p *properties.Properties) (time.Duration, string) {
+	name := p.GetString(prop.MeasurementUnit, prop.MeasurementUnitDefault)
+	unit, ok := latencyUnits[name]
+	if !ok {
+		panic("unsupported measurement unit: " + name)
+	}
+	return unit, name
+}
 
 type measurement struct {
 	sync.RWMutex
@@ -33,6 +58,19 @@ type measurement struct {
 	p *properties.Properties
 
 	measurer ycsb.Measurer
+
+	intervalStart time.Time
+
+	derived []derivedMetric
+	// vars are the variables of the derived metrics set by the client.
+	vars map[string]float64
+
+	successOnly bool
+	// timer is the calibration subtracted from the latencies, nil without
+	// prop.MeasurementTimerCorrection.
+	timer *timerCalibration
+	// anomalies is the interval series, nil without prop.MeasurementAnomalies.
+	anomalies *anomalySeries
 }
 
 func (m *measurement) measure(op string, start time.Time, lan time.Duration) {
@@ -62,6 +100,12 @@ func (m *measurement) output() {
 	if err != nil {
 		panic("failed to write output: " + err.Error())
 	}
+	m.outputThroughput(w)
+	m.outputBandwidth(w)
+	m.outputMix(w)
+	m.outputAnomalies(w)
+	m.outputTimer(w)
+	m.outputDerived(w)
 
 	err = w.Flush()
 	if err != nil {
@@ -72,23 +116,42 @@ func (m *measurement) output() {
 func (m *measurement) summary() {
 	m.RLock()
 	globalMeasure.measurer.Summary()
+	m.summaryBandwidth()
+	m.summaryMix()
 	m.RUnlock()
+	m.sampleAnomalies()
 }
 
 // InitMeasure initializes the global measurement.
 func InitMeasure(p *properties.Properties) {
 	globalMeasure = new(measurement)
 	globalMeasure.p = p
+	globalMeasure.intervalStart = time.Now()
+	annotations.Lock()
+	annotations.start = globalMeasure.intervalStart
+	annotations.Unlock()
 	measurementType := p.GetString(prop.MeasurementType, prop.MeasurementTypeDefault)
 	switch measurementType {
 	case "histogram":
 		globalMeasure.measurer = InitHistograms(p)
 	case "raw", "csv":
-		globalMeasure.measurer = InitCSV()
+		globalMeasure.measurer = InitCSV(globalMeasure.p)
 	default:
 		panic("unsupported measurement type: " + measurementType)
 	}
+	resetBytes()
+	resetMix()
+	globalMeasure.derived = parseDerived(p)
+	globalMeasure.vars = make(map[string]float64)
+	globalMeasure.anomalies = newAnomalySeries(p)
+	globalMeasure.successOnly = p.GetBool(prop.MeasurementSuccessOnly, prop.MeasurementSuccessOnlyDefault)
+	if p.GetBool(prop.MeasurementTimerCorrection, prop.MeasurementTimerCorrectionDefault) {
+		c := calibrateTimer()
+		globalMeasure.timer = &c
+		fmt.Printf("[TIMER] %s\n", globalMeasure.timer)
+	}
 	EnableWarmUp(p.GetInt64(prop.WarmUpTime, 0) > 0)
+	initTrace(p)
 }
 
 // Output prints the complete measurements.
@@ -119,7 +182,7 @@ func IsWarmUpFinished() bool {
 // Measure measures the operation.
 func Measure(op string, start time.Time, lan time.Duration) {
 	if IsWarmUpFinished() {
-		globalMeasure.measure(op, start, lan)
+		globalMeasure.measure(op, start, globalMeasure.timer.correct(lan))
 	}
 }
 
@@ -0,0 +1,139 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// opMix is the operation mix of the workload, *mixCounter keyed by the
+// operation, nil if the workload doesn't choose its operations by
+// proportions.
+var opMix atomic.Pointer[map[string]*mixCounter]
+
+type mixCounter struct {
+	intended float64
+	// total is the operations done since the start and interval those done
+	// since the last summary.
+	total    int64
+	interval int64
+}
+
+// SetIntendedMix sets the proportions the workload chooses its operations
+// with, they don't have to sum to 1.
+func SetIntendedMix(proportions map[string]float64) {
+	var sum float64
+	for _, proportion := range proportions {
+		sum += proportion
+	}
+	mix := make(map[string]*mixCounter, len(proportions))
+	for op, proportion := range proportions {
+		if proportion > 0 {
+			mix[op] = &mixCounter{intended: proportion / sum}
+		}
+	}
+	opMix.Store(&mix)
+}
+
+// CountOp counts n operations of the mix done, the failed ones aren't
+// counted so the achieved mix shows how errors skew it.
+func CountOp(op string, n int64) {
+	if !IsWarmUpFinished() {
+		return
+	}
+	mix := opMix.Load()
+	if mix == nil {
+		return
+	}
+	if c, ok := (*mix)[op]; ok {
+		atomic.AddInt64(&c.total, n)
+		atomic.AddInt64(&c.interval, n)
+	}
+}
+
+func resetMix() {
+	mix := opMix.Load()
+	if mix == nil {
+		return
+	}
+	for _, c := range *mix {
+		atomic.StoreInt64(&c.total, 0)
+		atomic.StoreInt64(&c.interval, 0)
+	}
+}
+
+// mixOps returns the operations of the mix, sorted.
+func mixOps(mix map[string]*mixCounter) []string {
+	ops := make([]string, 0, len(mix))
+	for op := range mix {
+		ops = append(ops, op)
+	}
+	sort.Strings(ops)
+	return ops
+}
+
+func percentOf(n int64, sum int64) float64 {
+	if sum == 0 {
+		return 0
+	}
+	return float64(n) / float64(sum) * 100
+}
+
+// summaryMix prints the mix achieved since the last summary in one line.
+func (m *measurement) summaryMix() {
+	mix := opMix.Load()
+	if mix == nil || len(*mix) < 2 {
+		return
+	}
+	ops := mixOps(*mix)
+	counts := make([]int64, len(ops))
+	var sum int64
+	for i, op := range ops {
+		counts[i] = atomic.SwapInt64(&(*mix)[op].interval, 0)
+		sum += counts[i]
+	}
+	if sum == 0 {
+		return
+	}
+	parts := make([]string, 0, len(ops))
+	for i, op := range ops {
+		parts = append(parts, fmt.Sprintf("%s %.1f%% (%.1f%%)", op, percentOf(counts[i], sum), (*mix)[op].intended*100))
+	}
+	fmt.Printf("[MIX] %s\n", strings.Join(parts, ", "))
+}
+
+func (m *measurement) outputMix(w io.Writer) {
+	mix := opMix.Load()
+	if mix == nil || len(*mix) < 2 {
+		return
+	}
+	ops := mixOps(*mix)
+	var sum int64
+	for _, op := range ops {
+		sum += atomic.LoadInt64(&(*mix)[op].total)
+	}
+	if sum == 0 {
+		return
+	}
+	for _, op := range ops {
+		c := (*mix)[op]
+		total := atomic.LoadInt64(&c.total)
+		fmt.Fprintf(w, "[MIX] %s: %d ops, achieved %.2f%%, intended %.2f%%\n",
+			op, total, percentOf(total, sum), c.intended*100)
+	}
+}
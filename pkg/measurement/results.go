@@ -0,0 +1,190 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+)
+
+var repeatHeader = []string{"Operation", "Runs", "Mean", "StdDev", "95% CI Low", "95% CI High"}
+
+// resultMetrics are the metrics of a Result compared across repeated runs.
+var resultMetrics = []struct {
+	name  string
+	value func(r Result) float64
+}{
+	{"OPS", func(r Result) float64 { return r.OPS }},
+	{"MB/s", func(r Result) float64 { return r.MBps }},
+	{"Avg(us)", func(r Result) float64 { return float64(r.Avg) }},
+	{"50th(us)", func(r Result) float64 { return float64(r.P50) }},
+	{"99th(us)", func(r Result) float64 { return float64(r.P99) }},
+	{"99.9th(us)", func(r Result) float64 { return float64(r.P999) }},
+}
+
+// Result is the summary of the latencies of an operation, the latencies are
+// in microseconds whatever prop.MeasurementUnit is.
+type Result struct {
+	// Elapsed is the time in seconds since the operation was first measured.
+	Elapsed float64 `json:"elapsed"`
+	Count   int64   `json:"count"`
+	OPS     float64 `json:"ops"`
+	Avg     int64   `json:"avg"`
+	P50     int64   `json:"p50"`
+	P90     int64   `json:"p90"`
+	P95     int64   `json:"p95"`
+	P99     int64   `json:"p99"`
+	P999    int64   `json:"p999"`
+	P9999   int64   `json:"p9999"`
+	// MBps is the bandwidth of the logical bytes read and written.
+	MBps float64 `json:"mbps"`
+}
+
+// Results returns the summary of every measured operation. It returns nil if
+// the measurement type doesn't keep histograms.
+func Results() map[string]Result {
+	globalMeasure.RLock()
+	defer globalMeasure.RUnlock()
+	return globalMeasure.results()
+}
+
+// results must be called with the lock held.
+func (m *measurement) results() map[string]Result {
+	h, ok := m.measurer.(*histograms)
+	if !ok {
+		return nil
+	}
+
+	results := make(map[string]Result, len(h.histograms))
+	for op, opM := range h.histograms {
+		info := opM.getInfo()
+		read, written := bytesOf(op)
+		results[op] = Result{
+			Elapsed: info[ELAPSED].(float64),
+			Count:   info[COUNT].(int64),
+			OPS:     info[QPS].(float64),
+			Avg:     opM.micros(info[AVG].(int64)),
+			P50:     opM.micros(info[PER50TH].(int64)),
+			P90:     opM.micros(info[PER90TH].(int64)),
+			P95:     opM.micros(info[PER95TH].(int64)),
+			P99:     opM.micros(info[PER99TH].(int64)),
+			P999:    opM.micros(info[PER999TH].(int64)),
+			P9999:   opM.micros(info[PER9999TH].(int64)),
+			MBps:    mbps(read+written, info[ELAPSED].(float64)),
+		}
+	}
+	return results
+}
+
+// OutputRepeats writes the mean, standard deviation and 95% confidence
+// interval of the metrics of every operation across the results of repeated
+// runs. An operation missing in some runs is summarized over the others.
+func OutputRepeats(w io.Writer, p *properties.Properties, results []map[string]Result) {
+	ops := make(map[string][]Result)
+	for _, res := range results {
+		for op, r := range res {
+			ops[op] = append(ops[op], r)
+		}
+	}
+	keys := make([]string, 0, len(ops))
+	for op := range ops {
+		keys = append(keys, op)
+	}
+	sort.Strings(keys)
+
+	lines := [][]string{}
+	for _, op := range keys {
+		for _, m := range resultMetrics {
+			values := make([]float64, len(ops[op]))
+			for i, r := range ops[op] {
+				values[i] = m.value(r)
+			}
+			s := util.Summarize(values)
+			lines = append(lines, []string{
+				op + " " + m.name,
+				util.IntToString(s.N),
+				util.FloatToOneString(s.Mean),
+				util.FloatToOneString(s.StdDev),
+				util.FloatToOneString(s.CILow),
+				util.FloatToOneString(s.CIHigh),
+			})
+		}
+	}
+
+	outputStyle := p.GetString(prop.OutputStyle, util.OutputStylePlain)
+	switch outputStyle {
+	case util.OutputStylePlain:
+		util.RenderString(w, "%-6s - %s\n", repeatHeader, lines)
+	case util.OutputStyleJson:
+		util.RenderJson(w, repeatHeader, lines)
+	case util.OutputStyleTable:
+		util.RenderTable(w, repeatHeader, lines)
+	default:
+		panic("unsupported outputstyle: " + outputStyle)
+	}
+}
+
+// OutputComparison writes the metrics of every operation of two runs side by
+// side, with the change from the first to the second one.
+func OutputComparison(w io.Writer, p *properties.Properties, nameA string, nameB string, a map[string]Result, b map[string]Result) {
+	ops := make(map[string]struct{})
+	for op := range a {
+		ops[op] = struct{}{}
+	}
+	for op := range b {
+		ops[op] = struct{}{}
+	}
+	keys := make([]string, 0, len(ops))
+	for op := range ops {
+		keys = append(keys, op)
+	}
+	sort.Strings(keys)
+
+	header := []string{"Operation", nameA, nameB, "Delta"}
+	lines := [][]string{}
+	for _, op := range keys {
+		ra, okA := a[op]
+		rb, okB := b[op]
+		for _, m := range resultMetrics {
+			line := []string{op + " " + m.name, "-", "-", "-"}
+			if okA {
+				line[1] = util.FloatToOneString(m.value(ra))
+			}
+			if okB {
+				line[2] = util.FloatToOneString(m.value(rb))
+			}
+			if okA && okB && m.value(ra) != 0 {
+				line[3] = fmt.Sprintf("%+.1f%%", (m.value(rb)-m.value(ra))/m.value(ra)*100)
+			}
+			lines = append(lines, line)
+		}
+	}
+
+	outputStyle := p.GetString(prop.OutputStyle, util.OutputStylePlain)
+	switch outputStyle {
+	case util.OutputStylePlain:
+		util.RenderString(w, "%-6s - %s\n", header, lines)
+	case util.OutputStyleJson:
+		util.RenderJson(w, header, lines)
+	case util.OutputStyleTable:
+		util.RenderTable(w, header, lines)
+	default:
+		panic("unsupported outputstyle: " + outputStyle)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+)
+
+// failureSuffixes are the suffixes of the operations measuring failures.
+var failureSuffixes = []string{"_ERROR", "_NOT_FOUND"}
+
+// IsFailure returns whether the operation measures failures, the operations
+// of a label are left out as they are measured twice.
+func IsFailure(op string) bool {
+	if strings.Contains(op, "-") {
+		return false
+	}
+	for _, suffix := range failureSuffixes {
+		if strings.HasSuffix(op, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// failures returns the number of failed operations of the results.
+func failures(results map[string]Result) int64 {
+	var failed int64
+	for op, r := range results {
+		if IsFailure(op) {
+			failed += r.Count
+		}
+	}
+	return failed
+}
+
+// SuccessOnly returns whether the reads and scans returning no rows count as
+// failures.
+func SuccessOnly() bool {
+	return globalMeasure != nil && globalMeasure.successOnly
+}
+
+// outputThroughput writes the throughput over the successful operations and
+// the failures of every operation, it must be called with the lock held.
+func (m *measurement) outputThroughput(w io.Writer) {
+	if !m.successOnly {
+		return
+	}
+
+	results := m.results()
+	if results == nil {
+		return
+	}
+	total := results["TOTAL"]
+	failed := failures(results)
+	ops := 0.0
+	if total.Elapsed > 0 {
+		ops = float64(total.Count) / total.Elapsed
+	}
+	fmt.Fprintf(w, "[THROUGHPUT] %d successful operations in %.1fs, %.1f ops/sec\n", total.Count, total.Elapsed, ops)
+	if failed == 0 {
+		return
+	}
+
+	keys := make([]string, 0, len(results))
+	for op := range results {
+		if IsFailure(op) {
+			keys = append(keys, op)
+		}
+	}
+	sort.Strings(keys)
+	parts := make([]string, 0, len(keys))
+	for _, op := range keys {
+		parts = append(parts, fmt.Sprintf("%s %d", op, results[op].Count))
+	}
+	fmt.Fprintf(w, "[THROUGHPUT] %d failed operations, %.1f%% of all, left out: %s\n",
+		failed, float64(failed)/float64(failed+total.Count)*100, strings.Join(parts, ", "))
+}
@@ -0,0 +1,83 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"time"
+)
+
+const (
+	// timerSamples is the number of samples of the calibration.
+	timerSamples = 10001
+	// resolutionSamples is the number of clock ticks waited for to find
+	// the resolution.
+	resolutionSamples = 100
+)
+
+// timerCalibration is the resolution of the clock and the overhead of
+// timing an operation, the latency of timing an empty one.
+type timerCalibration struct {
+	resolution time.Duration
+	overhead   time.Duration
+}
+
+// calibrateTimer measures the smallest step of the clock and the median
+// latency of timing nothing, which every measured latency includes.
+func calibrateTimer() timerCalibration {
+	var c timerCalibration
+	for i := 0; i < resolutionSamples; i++ {
+		start := time.Now()
+		for {
+			if d := time.Since(start); d > 0 {
+				if c.resolution == 0 || d < c.resolution {
+					c.resolution = d
+				}
+				break
+			}
+		}
+	}
+
+	samples := make([]time.Duration, timerSamples)
+	for i := range samples {
+		start := time.Now()
+		samples[i] = time.Now().Sub(start)
+	}
+	sort.Slice(samples, func(i, j int) bool { return samples[i] < samples[j] })
+	c.overhead = samples[len(samples)/2]
+	return c
+}
+
+// correct subtracts the overhead of the timer from the latency.
+func (c *timerCalibration) correct(lan time.Duration) time.Duration {
+	if c == nil {
+		return lan
+	}
+	if lan -= c.overhead; lan < 0 {
+		return 0
+	}
+	return lan
+}
+
+func (c *timerCalibration) String() string {
+	return fmt.Sprintf("clock resolution %s, timer overhead %s subtracted from the latencies", c.resolution, c.overhead)
+}
+
+func (m *measurement) outputTimer(w io.Writer) {
+	if m.timer != nil {
+		fmt.Fprintf(w, "[TIMER] %s\n", m.timer)
+	}
+}
@@ -0,0 +1,199 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package measurement
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// traceFile is the file in the output directory the sampled operations are
+// appended to.
+const traceFile = "trace.jsonl"
+
+// traceMaxKeys is the number of keys of a batch operation kept in a trace.
+const traceMaxKeys = 8
+
+type traceKeyType string
+
+const (
+	traceKey   = traceKeyType("trace")
+	retriesKey = traceKeyType("retries")
+)
+
+// Trace is the full detail of a sampled operation, the durations are in
+// microseconds. Bindings add their own timings, such as encode, decode and
+// engine, to the trace returned by TraceFrom.
+type Trace struct {
+	Start      time.Time          `json:"start"`
+	Op         string             `json:"op"`
+	Table      string             `json:"table"`
+	Keys       []string           `json:"keys"`
+	KeyCount   int                `json:"key_count"`
+	Fields     int                `json:"fields,omitempty"`
+	WriteBytes int                `json:"write_bytes,omitempty"`
+	ReadBytes  int                `json:"read_bytes,omitempty"`
+	Retries    int64              `json:"retries,omitempty"`
+	Latency    float64            `json:"latency"`
+	Timings    map[string]float64 `json:"timings,omitempty"`
+	Error      string             `json:"error,omitempty"`
+
+	mu sync.Mutex
+}
+
+var tracer struct {
+	rate  int64
+	count int64
+
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func initTrace(p *properties.Properties) {
+	tracer.rate = p.GetInt64(prop.TraceRate, prop.TraceRateDefault)
+	if tracer.rate <= 0 {
+		return
+	}
+
+	dir := p.GetString(prop.OutputDir, prop.OutputDirDefault)
+	path := filepath.Join(dir, traceFile)
+	err := os.MkdirAll(dir, 0755)
+	var f *os.File
+	if err == nil {
+		f, err = os.Create(path)
+	}
+	if err != nil {
+		fmt.Printf("[TRACE] create %s failed %v, tracing is disabled\n", path, err)
+		tracer.rate = 0
+		return
+	}
+	tracer.enc = json.NewEncoder(f)
+}
+
+// StartTrace samples one in trace.rate operations. It returns nil if the
+// operation is not sampled, otherwise the returned context carries the trace
+// for the bindings.
+func StartTrace(ctx context.Context, op string, table string, keys ...string) (context.Context, *Trace) {
+	if tracer.rate <= 0 || atomic.AddInt64(&tracer.count, 1)%tracer.rate != 0 {
+		return ctx, nil
+	}
+
+	t := &Trace{
+		Start:    time.Now(),
+		Op:       op,
+		Table:    table,
+		KeyCount: len(keys),
+	}
+	if len(keys) > traceMaxKeys {
+		keys = keys[:traceMaxKeys]
+	}
+	t.Keys = append([]string(nil), keys...)
+	if retries, ok := ctx.Value(retriesKey).(int64); ok {
+		t.Retries = retries
+	}
+	return context.WithValue(ctx, traceKey, t), t
+}
+
+// AddFields records the number of fields the operation accesses.
+func (t *Trace) AddFields(n int) {
+	if t != nil {
+		t.Fields += n
+	}
+}
+
+// AddWrite records the size of written values.
+func (t *Trace) AddWrite(values ...map[string][]byte) {
+	if t != nil {
+		t.WriteBytes += valuesSize(values)
+	}
+}
+
+// AddRead records the size of read values.
+func (t *Trace) AddRead(values ...map[string][]byte) {
+	if t != nil {
+		t.ReadBytes += valuesSize(values)
+	}
+}
+
+func valuesSize(values []map[string][]byte) int {
+	n := 0
+	for _, m := range values {
+		for field, value := range m {
+			n += len(field) + len(value)
+		}
+	}
+	return n
+}
+
+// Finish writes the trace to the trace file.
+func (t *Trace) Finish(err error) {
+	if t == nil {
+		return
+	}
+
+	t.Latency = float64(time.Now().Sub(t.Start).Nanoseconds()) / 1e3
+	if err != nil {
+		t.Error = err.Error()
+	}
+
+	tracer.mu.Lock()
+	defer tracer.mu.Unlock()
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	tracer.enc.Encode(t)
+}
+
+// TraceFrom returns the trace of the operation in the context, or nil if the
+// operation is not traced. All the methods of a nil trace do nothing.
+func TraceFrom(ctx context.Context) *Trace {
+	t, _ := ctx.Value(traceKey).(*Trace)
+	return t
+}
+
+// Timing adds the time since start to the named timing.
+func (t *Trace) Timing(name string, start time.Time) {
+	if t == nil {
+		return
+	}
+
+	d := float64(time.Now().Sub(start).Nanoseconds()) / 1e3
+	t.mu.Lock()
+	if t.Timings == nil {
+		t.Timings = make(map[string]float64)
+	}
+	t.Timings[name] += d
+	t.mu.Unlock()
+}
+
+// Retry records a retry of the operation by the binding.
+func (t *Trace) Retry() {
+	if t != nil {
+		atomic.AddInt64(&t.Retries, 1)
+	}
+}
+
+// WithRetries returns a context for an operation retried by the workload,
+// the retries are recorded if the operation is traced.
+func WithRetries(ctx context.Context, retries int64) context.Context {
+	return context.WithValue(ctx, retriesKey, retries)
+}
@@ -13,6 +13,8 @@
 
 package prop
 
+import "time"
+
 // Properties
 const (
 	InsertStart        = "insertstart"
@@ -38,6 +40,70 @@ const (
 	BatchSize        = "batch.size"
 	DefaultBatchSize = int(1)
 
+	// Tables lists the tables of a multi-table workload, every property can be
+	// overridden per table with table.<name>.<property>, e.g. table.users.weight.
+	Tables             = "tables"
+	TableWeight        = "weight"
+	TableWeightDefault = float64(1)
+
+	// ScanMissingStartKey is what a scan does when its start key doesn't
+	// exist: "seek" starts at the next existing key, "empty" returns no rows
+	// and "error" fails the scan.
+	ScanMissingStartKey        = "scan.missingstartkey"
+	ScanMissingStartKeyDefault = "seek"
+
+	// InsertKeyStrategy is how the load phase threads share the key space:
+	// "shared" takes the next key from one counter, "blocks" gives every
+	// thread a contiguous block of keys and "stripes" interleaves the threads
+	// round-robin.
+	InsertKeyStrategy        = "insertkeystrategy"
+	InsertKeyStrategyDefault = "shared"
+
+	// SessionQueue is how many published writes of the session workload wait
+	// for a reader, writes are dropped once it is full. SessionTimeout is how
+	// long a reader retries before it reports a write as never visible.
+	SessionQueue          = "session.queue"
+	SessionQueueDefault   = 16
+	SessionTimeout        = "session.timeout"
+	SessionTimeoutDefault = time.Second
+
+	// TombstoneRange is the number of keys in a range of the tombstone
+	// workload, TombstoneFraction the share of the keys deleted in the churned
+	// ranges.
+	TombstoneRange           = "tombstone.range"
+	TombstoneRangeDefault    = 1000
+	TombstoneFraction        = "tombstone.fraction"
+	TombstoneFractionDefault = 0.9
+
+	// ValueGrowthFactor multiplies the length of the values written by the
+	// updates of the core workload every ValueGrowthPeriod, up to
+	// ValueGrowthMaxLength bytes per field. The logical size of the data set
+	// and the size of the database on disk are reported every
+	// ValueGrowthReport.
+	ValueGrowthFactor           = "valuegrowth.factor"
+	ValueGrowthFactorDefault    = 1.0
+	ValueGrowthPeriod           = "valuegrowth.period"
+	ValueGrowthPeriodDefault    = time.Minute
+	ValueGrowthMaxLength        = "valuegrowth.maxlength"
+	ValueGrowthMaxLengthDefault = 65536
+	ValueGrowthReport           = "valuegrowth.report"
+	ValueGrowthReportDefault    = 10 * time.Second
+
+	// ValueGrowthMinLength is the minimum length of the values shrunk by a
+	// ValueGrowthFactor below 1.
+	ValueGrowthMinLength        = "valuegrowth.minlength"
+	ValueGrowthMinLengthDefault = 1
+
+	// Script is a script file computing the operations of the run phase.
+	Script = "script"
+
+	// Compose lists the workloads of the compose workload, each is configured
+	// with compose.<name>.file and compose.<name>.<property>.
+	Compose              = "compose"
+	ComposeFile          = "file"
+	ComposeWeight        = "weight"
+	ComposeWeightDefault = float64(1)
+
 	TableName         = "table"
 	TableNameDefault  = "usertable"
 	FieldCount        = "fieldcount"
@@ -66,6 +132,18 @@ const (
 	ScanProportionDefault            = float64(0.0)
 	ReadModifyWriteProportion        = "readmodifywriteproportion"
 	ReadModifyWriteProportionDefault = float64(0.0)
+	ReadAfterDeleteProportion        = "readafterdeleteproportion"
+	ReadAfterDeleteProportionDefault = float64(0.0)
+	// PagedScanProportion scans in pages of ScanPageSize rows, every page
+	// resuming from the last key of the previous one.
+	PagedScanProportion        = "pagedscanproportion"
+	PagedScanProportionDefault = float64(0.0)
+	ScanPageSize               = "scanpagesize"
+	ScanPageSizeDefault        = int64(10)
+	// FullScanProportion iterates over the whole table, the reads running
+	// meanwhile are measured apart too.
+	FullScanProportion        = "fullscanproportion"
+	FullScanProportionDefault = float64(0.0)
 	// "uniform", "zipfian", "latest"
 	RequestDistribution        = "requestdistribution"
 	RequestDistributionDefault = "uniform"
@@ -78,6 +156,10 @@ const (
 	// "uniform", "zipfian"
 	ScanLengthDistribution        = "scanlengthdistribution"
 	ScanLengthDistributionDefault = "uniform"
+	// ScanStartDistribution is the distribution of the start keys of the
+	// scans, "uniform", "zipfian" or "recent", empty for the request
+	// distribution.
+	ScanStartDistribution = "scanstartdistribution"
 	// "ordered", "hashed"
 	InsertOrder                   = "insertorder"
 	InsertOrderDefault            = "hashed"
@@ -109,6 +191,9 @@ const (
 	KeyPrefix        = "keyprefix"
 	KeyPrefixDefault = "user"
 
+	// RandomSeed seeds the per-thread random generators, the current time is used if unset.
+	RandomSeed = "randomseed"
+
 	LogInterval = "measurement.interval"
 
 	MeasurementType          = "measurementtype"
@@ -124,4 +209,278 @@ const (
 	MeasurementHistogramPercentileExportDefault         = false
 	MeasurementHistogramPercentileExportFilepath        = "histogram.percentiles.export.filepath"
 	MeasurementHistogramPercentileExportFilepathDefault = "./"
+
+	// MeasurementSuccessOnly counts the reads and scans returning no rows as
+	// failures and reports the throughput over the successful operations.
+	MeasurementSuccessOnly        = "measurement.successonly"
+	MeasurementSuccessOnlyDefault = false
+
+	// MeasurementUnit is the unit of the latencies the histograms record and
+	// print, one of "ns", "us" or "ms".
+	MeasurementUnit        = "measurement.unit"
+	MeasurementUnitDefault = "us"
+
+	// MeasurementTimerCorrection calibrates the overhead of timing an
+	// operation at the start and subtracts it from the latencies.
+	MeasurementTimerCorrection        = "measurement.timer_correction"
+	MeasurementTimerCorrectionDefault = false
+
+	// MeasurementAnomalies samples the interval statistics at every
+	// measurement.interval and lists the throughput cliffs and percentile
+	// spikes beyond measurement.anomalies.k times the median absolute
+	// deviation at the end.
+	MeasurementAnomalies         = "measurement.anomalies"
+	MeasurementAnomaliesDefault  = false
+	MeasurementAnomaliesK        = "measurement.anomalies.k"
+	MeasurementAnomaliesKDefault = 5.0
+
+	// MeasurementInFlight prints the operations in flight and MeasurementOS
+	// the writeback and I/O wait statistics after every periodic summary.
+	MeasurementInFlight        = "measurement.inflight"
+	MeasurementInFlightDefault = false
+	MeasurementOS              = "measurement.os"
+	MeasurementOSDefault       = false
+
+	// OutputDir is where run artifacts such as profiles and dumps are written.
+	OutputDir        = "output.dir"
+	OutputDirDefault = "."
+
+	// LimitsMaxRSSMB aborts or flags the run once the process RSS exceeds it, 0 disables the check.
+	LimitsMaxRSSMB             = "limits.max_rss_mb"
+	LimitsMaxRSSMBDefault      = int64(0)
+	LimitsCheckInterval        = "limits.check_interval"
+	LimitsCheckIntervalDefault = time.Second
+	// "abort", "flag"
+	LimitsAction        = "limits.action"
+	LimitsActionDefault = "abort"
+
+	// WatchdogTimeout dumps goroutine stacks and engine stats once no operation
+	// completes for this long, 0 disables the watchdog.
+	WatchdogTimeout        = "watchdog.timeout"
+	WatchdogTimeoutDefault = time.Duration(0)
+	WatchdogAbort          = "watchdog.abort"
+	WatchdogAbortDefault   = false
+
+	// MaxExecutionTimeGrace is how long the workers still in an operation
+	// are waited for once the run stopped, before the measurements are
+	// written without them.
+	MaxExecutionTimeGrace        = "maxexecutiontime.grace"
+	MaxExecutionTimeGraceDefault = 10 * time.Second
+
+	// SpillDir is where the spill binding writes the encoded rows of the load
+	// phase and where the ingest command reads them from.
+	SpillDir        = "spill.dir"
+	SpillDirDefault = "/tmp/ycsb-spill"
+
+	// ExportHMACKey is the secret the export command hashes the keys with,
+	// empty keeps the keys. ExportRedact replaces the exported values with
+	// random letters of the same length.
+	ExportHMACKey       = "export.hmac_key"
+	ExportRedact        = "export.redact"
+	ExportRedactDefault = false
+
+	// LoadCommitter makes the load phase workers generate rows into a bounded
+	// queue consumed by a single committer writing large transactions.
+	LoadCommitter             = "load.committer"
+	LoadCommitterDefault      = false
+	LoadCommitterQueue        = "load.committer.queue"
+	LoadCommitterQueueDefault = 1024
+	LoadCommitterBatch        = "load.committer.batch"
+	LoadCommitterBatchDefault = 1000
+
+	// WriteOrdering is the guarantee on the order of the writes of a thread
+	// to a key: "any" lets the batches write a key more than once in any
+	// order, "strict" issues them in the order they are generated, which
+	// verification runs need.
+	WriteOrdering        = "write.ordering"
+	WriteOrderingDefault = "any"
+
+	// RecoverPanics recovers panics in the workers and records them as PANIC operations.
+	RecoverPanics        = "recoverpanics"
+	RecoverPanicsDefault = true
+
+	// OpLogSize keeps the last operations in memory and writes them to a repro
+	// file in the output directory on the first failed operation, 0 disables it.
+	OpLogSize        = "oplog.size"
+	OpLogSizeDefault = 0
+
+	// Oracle mirrors all writes into an in-memory map and validates every read
+	// against it, only suitable for small record counts.
+	Oracle        = "oracle"
+	OracleDefault = false
+
+	// VerifyRate reads and checks this many random rows per second in the
+	// background of the run, apart from the measurements, 0 disables it.
+	VerifyRate        = "verify.rate"
+	VerifyRateDefault = 0.0
+
+	// Fingerprint writes a fingerprint of the loaded data set to the manifest
+	// after the load phase, and checks it before the run phase and after ingest.
+	Fingerprint        = "fingerprint"
+	FingerprintDefault = false
+
+	// Manifest records the binary, the effective properties, the seed and the
+	// environment of every load and run in the manifest, so the run can be
+	// replayed from it.
+	Manifest        = "manifest"
+	ManifestDefault = false
+
+	// PoolDir is the directory of the pool of pre-built data sets. The run
+	// phase restores the data set matching its properties from the pool and
+	// builds it first if there is none.
+	PoolDir = "pool.dir"
+
+	// TraceRate writes the full detail of one in every TraceRate operations to
+	// trace.jsonl in the output directory, 0 disables tracing.
+	TraceRate        = "trace.rate"
+	TraceRateDefault = int64(0)
+
+	// OutlierThreshold captures the engine stats, GC stats and queue depth to
+	// outliers.jsonl in the output directory when an operation takes longer
+	// than it, 0 disables the capture. At most OutlierMax are captured.
+	OutlierThreshold        = "outlier.threshold"
+	OutlierThresholdDefault = time.Duration(0)
+	OutlierMax              = "outlier.max"
+	OutlierMaxDefault       = int64(100)
+
+	// StallMultiple detects a write stall when StallOps consecutive writes
+	// take longer than this multiple of the median write latency, 0
+	// disables the detection.
+	StallMultiple        = "stall.multiple"
+	StallMultipleDefault = float64(0)
+	StallOps             = "stall.ops"
+	StallOpsDefault      = 100
+
+	// DBMiddleware lists the middlewares the operations pass through before
+	// the database, the first one sees the operations first. The trace
+	// middleware is added last if TraceRate is set and it isn't listed.
+	DBMiddleware        = "db.middleware"
+	DBMiddlewareDefault = ""
+
+	// MiddlewareTimeout is the deadline of an operation with the timeout
+	// middleware.
+	MiddlewareTimeout        = "middleware.timeout"
+	MiddlewareTimeoutDefault = time.Second
+	// MiddlewareRetryLimit is how many times the retry middleware retries a
+	// failed operation, waiting MiddlewareRetryBackoff more before each retry.
+	MiddlewareRetryLimit          = "middleware.retry.limit"
+	MiddlewareRetryLimitDefault   = int64(3)
+	MiddlewareRetryBackoff        = "middleware.retry.backoff"
+	MiddlewareRetryBackoffDefault = 10 * time.Millisecond
+	// MiddlewareFaultRate is the fraction of operations the fault middleware
+	// fails, MiddlewareFaultDelay is added to every operation.
+	MiddlewareFaultRate         = "middleware.fault.rate"
+	MiddlewareFaultRateDefault  = float64(0.01)
+	MiddlewareFaultDelay        = "middleware.fault.delay"
+	MiddlewareFaultDelayDefault = time.Duration(0)
+	// MiddlewareCacheSize is the number of keys the cache middleware keeps.
+	MiddlewareCacheSize        = "middleware.cache.size"
+	MiddlewareCacheSizeDefault = 10000
+
+	// TargetFile is a property file whose target property replaces the target
+	// throughput on every SIGHUP.
+	TargetFile = "target.file"
+
+	// MaintenanceInterval starts a maintenance window this long after the end
+	// of the previous one, 0 disables them. During a window of
+	// MaintenanceDuration the target is MaintenanceTarget, 0 pauses the
+	// workers. The recovery lasts until the throughput is back to
+	// MaintenanceRecovery of the throughput before the window.
+	MaintenanceInterval        = "maintenance.interval"
+	MaintenanceIntervalDefault = time.Duration(0)
+	MaintenanceDuration        = "maintenance.duration"
+	MaintenanceDurationDefault = 30 * time.Second
+	MaintenanceTarget          = "maintenance.target"
+	MaintenanceTargetDefault   = int64(0)
+	MaintenanceRecovery        = "maintenance.recovery"
+	MaintenanceRecoveryDefault = float64(0.9)
+
+	// Pattern changes the target over time following a load pattern,
+	// recomputing it every PatternStep.
+	Pattern            = "pattern"
+	PatternDefault     = ""
+	PatternStep        = "pattern.step"
+	PatternStepDefault = 100 * time.Millisecond
+	// PatternBurstPeak is the target during the first PatternBurstDuty of
+	// every PatternBurstPeriod, 0 is unlimited, and PatternBurstIdle the
+	// target during the rest of it, 0 pauses the workers.
+	PatternBurstPeriod        = "pattern.burst.period"
+	PatternBurstPeriodDefault = 10 * time.Second
+	PatternBurstDuty          = "pattern.burst.duty"
+	PatternBurstDutyDefault   = float64(0.5)
+	PatternBurstPeak          = "pattern.burst.peak"
+	PatternBurstPeakDefault   = int64(0)
+	PatternBurstIdle          = "pattern.burst.idle"
+	PatternBurstIdleDefault   = int64(0)
+	// PatternDiurnalMean is the mean target of the diurnal pattern, it swings
+	// by PatternDiurnalAmplitude of it along a sine of PatternDiurnalPeriod.
+	PatternDiurnalPeriod           = "pattern.diurnal.period"
+	PatternDiurnalPeriodDefault    = time.Hour
+	PatternDiurnalMean             = "pattern.diurnal.mean"
+	PatternDiurnalAmplitude        = "pattern.diurnal.amplitude"
+	PatternDiurnalAmplitudeDefault = float64(0.5)
+
+	// ABMode is how the ab command runs its two sides, "sequential" runs A then
+	// B, "interleaved" alternates them in ABRounds rounds. Every property can be
+	// overridden per side with ab.a.<property> and ab.b.<property>.
+	ABMode          = "ab.mode"
+	ABModeDefault   = "sequential"
+	ABRounds        = "ab.rounds"
+	ABRoundsDefault = 4
+
+	// Energy samples the RAPL energy counters of the CPU packages during the
+	// measured window and reports the joules used and the ops per joule, Linux
+	// only.
+	Energy        = "energy"
+	EnergyDefault = false
+
+	// DashboardAddr serves a live dashboard and pushes the interval metrics
+	// over a WebSocket, empty disables it.
+	DashboardAddr            = "dashboard.addr"
+	DashboardAddrDefault     = ""
+	DashboardInterval        = "dashboard.interval"
+	DashboardIntervalDefault = time.Second
+
+	// Report writes a self-contained HTML report of the run, with its
+	// results, throughput chart, properties, environment and anomalies, to
+	// report.html in the output directory.
+	Report        = "report"
+	ReportDefault = false
+
+	// NotifyWebhook posts the summary of the run, or why it failed with the
+	// last error classes, to the URL when the run ends, empty disables it.
+	NotifyWebhook = "notify.webhook"
+
+	// ResultsFile appends the results of every load or run as a JSON line to
+	// the file, to track them across scheduled runs.
+	ResultsFile = "results.file"
+
+	// ShadowDB runs every operation on a shadow database of this binding too
+	// and compares their outcomes. The properties under ShadowPrefix override
+	// the ones of the shadow, like shadow.fredb.path.
+	ShadowDB     = "shadow.db"
+	ShadowPrefix = "shadow."
+
+	// ShadowCompare compares the values read from the primary and the shadow
+	// database field by field, not only the number of fields and rows.
+	ShadowCompare        = "shadow.compare"
+	ShadowCompareDefault = false
+
+	// BufPoolInitialSize is the capacity in bytes of the new buffers of the
+	// bindings' encoding pool, and BufPoolMaxRetained the capacity above which
+	// a buffer is dropped instead of kept, 0 keeps all of them.
+	BufPoolInitialSize        = "bufpool.initial_size"
+	BufPoolInitialSizeDefault = 0
+	BufPoolMaxRetained        = "bufpool.max_retained"
+	BufPoolMaxRetainedDefault = 0
+
+	// RowMetadata embeds the write timestamp and a generation in every row
+	// encoded by the row codec.
+	RowMetadata        = "row.metadata"
+	RowMetadataDefault = false
+
+	// ReadStaleness measures the age of every row read, the time since it
+	// was written, from the write timestamp of prop.RowMetadata.
+	ReadStaleness        = "read.staleness"
+	ReadStalenessDefault = false
 )
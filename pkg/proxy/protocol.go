@@ -0,0 +1,92 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package proxy is the protocol between the remote binding and the agent
+// hosting the database on another machine. It's net/rpc over TCP: every
+// thread of the client has a connection of its own, which the agent serves
+// with a thread of the hosted database.
+package proxy
+
+// ServiceName is the name of the RPC service of the agent.
+const ServiceName = "DB"
+
+// Methods of the service.
+const (
+	MethodInitThread  = ServiceName + ".InitThread"
+	MethodRead        = ServiceName + ".Read"
+	MethodScan        = ServiceName + ".Scan"
+	MethodUpdate      = ServiceName + ".Update"
+	MethodInsert      = ServiceName + ".Insert"
+	MethodDelete      = ServiceName + ".Delete"
+	MethodBatchRead   = ServiceName + ".BatchRead"
+	MethodBatchInsert = ServiceName + ".BatchInsert"
+	MethodBatchUpdate = ServiceName + ".BatchUpdate"
+	MethodBatchDelete = ServiceName + ".BatchDelete"
+)
+
+// Empty is the argument or the reply of the methods without any.
+type Empty struct{}
+
+// InitThreadArgs starts the thread of the connection in the agent, it must be
+// the first call of the connection.
+type InitThreadArgs struct {
+	ThreadID    int
+	ThreadCount int
+}
+
+// ReadArgs are the arguments of Read.
+type ReadArgs struct {
+	Table  string
+	Key    string
+	Fields []string
+}
+
+// ScanArgs are the arguments of Scan.
+type ScanArgs struct {
+	Table    string
+	StartKey string
+	Count    int
+	Fields   []string
+}
+
+// WriteArgs are the arguments of Update and Insert.
+type WriteArgs struct {
+	Table  string
+	Key    string
+	Values map[string][]byte
+}
+
+// DeleteArgs are the arguments of Delete.
+type DeleteArgs struct {
+	Table string
+	Key   string
+}
+
+// BatchArgs are the arguments of the batch methods, the fields are only set
+// for BatchRead and the values for BatchInsert and BatchUpdate.
+type BatchArgs struct {
+	Table  string
+	Keys   []string
+	Fields []string
+	Values []map[string][]byte
+}
+
+// RowReply is the reply of Read.
+type RowReply struct {
+	Values map[string][]byte
+}
+
+// RowsReply is the reply of Scan and BatchRead.
+type RowsReply struct {
+	Rows []map[string][]byte
+}
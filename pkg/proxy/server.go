@@ -0,0 +1,221 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package proxy
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/rpc"
+	"sync"
+
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+var errNoThread = errors.New("the thread of the connection is not initialized")
+
+// Server serves a database to the remote binding.
+type Server struct {
+	db ycsb.DB
+
+	wg    sync.WaitGroup
+	mu    sync.Mutex
+	conns map[net.Conn]struct{}
+}
+
+// NewServer returns a server of the database.
+func NewServer(db ycsb.DB) *Server {
+	return &Server{db: db, conns: make(map[net.Conn]struct{})}
+}
+
+// Serve serves the connections of the listener until the context is done,
+// it then closes the connections and waits for their threads to end.
+func (s *Server) Serve(ctx context.Context, l net.Listener) error {
+	go func() {
+		<-ctx.Done()
+		l.Close()
+		s.mu.Lock()
+		for conn := range s.conns {
+			conn.Close()
+		}
+		s.mu.Unlock()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			s.wg.Wait()
+			if ctx.Err() != nil {
+				return nil
+			}
+			return err
+		}
+
+		s.mu.Lock()
+		s.conns[conn] = struct{}{}
+		if ctx.Err() != nil {
+			// accepted after the connections are closed.
+			conn.Close()
+		}
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.serveConn(ctx, conn)
+			s.mu.Lock()
+			delete(s.conns, conn)
+			s.mu.Unlock()
+		}()
+	}
+}
+
+// serveConn serves a thread of the client, the thread of the database is
+// cleaned up once the client closes the connection.
+func (s *Server) serveConn(ctx context.Context, conn net.Conn) {
+	t := &Thread{db: s.db, base: ctx}
+	srv := rpc.NewServer()
+	srv.RegisterName(ServiceName, t)
+	srv.ServeConn(conn)
+
+	if t.ctx != nil {
+		s.db.CleanupThread(t.ctx)
+	}
+}
+
+// Thread is the RPC service of a connection, its methods run the operations
+// in the thread of the database of the connection.
+type Thread struct {
+	db   ycsb.DB
+	base context.Context
+	ctx  context.Context
+}
+
+// InitThread initializes the thread of the database.
+func (t *Thread) InitThread(args *InitThreadArgs, _ *Empty) error {
+	if t.ctx != nil {
+		return errors.New("the thread of the connection is already initialized")
+	}
+	t.ctx = t.db.InitThread(t.base, args.ThreadID, args.ThreadCount)
+	return nil
+}
+
+// Read reads a record.
+func (t *Thread) Read(args *ReadArgs, reply *RowReply) (err error) {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	reply.Values, err = t.db.Read(t.ctx, args.Table, args.Key, args.Fields)
+	return err
+}
+
+// Scan scans records.
+func (t *Thread) Scan(args *ScanArgs, reply *RowsReply) (err error) {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	reply.Rows, err = t.db.Scan(t.ctx, args.Table, args.StartKey, args.Count, args.Fields)
+	return err
+}
+
+// Update updates a record.
+func (t *Thread) Update(args *WriteArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	return t.db.Update(t.ctx, args.Table, args.Key, args.Values)
+}
+
+// Insert inserts a record.
+func (t *Thread) Insert(args *WriteArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	return t.db.Insert(t.ctx, args.Table, args.Key, args.Values)
+}
+
+// Delete deletes a record.
+func (t *Thread) Delete(args *DeleteArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	return t.db.Delete(t.ctx, args.Table, args.Key)
+}
+
+// BatchRead reads records, one by one if the database has no batches.
+func (t *Thread) BatchRead(args *BatchArgs, reply *RowsReply) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	if batchDB, ok := t.db.(ycsb.BatchDB); ok {
+		var err error
+		reply.Rows, err = batchDB.BatchRead(t.ctx, args.Table, args.Keys, args.Fields)
+		return err
+	}
+	for _, key := range args.Keys {
+		row, err := t.db.Read(t.ctx, args.Table, key, args.Fields)
+		if err != nil {
+			return err
+		}
+		reply.Rows = append(reply.Rows, row)
+	}
+	return nil
+}
+
+// BatchInsert inserts records, one by one if the database has no batches.
+func (t *Thread) BatchInsert(args *BatchArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	if batchDB, ok := t.db.(ycsb.BatchDB); ok {
+		return batchDB.BatchInsert(t.ctx, args.Table, args.Keys, args.Values)
+	}
+	for i, key := range args.Keys {
+		if err := t.db.Insert(t.ctx, args.Table, key, args.Values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchUpdate updates records, one by one if the database has no batches.
+func (t *Thread) BatchUpdate(args *BatchArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	if batchDB, ok := t.db.(ycsb.BatchDB); ok {
+		return batchDB.BatchUpdate(t.ctx, args.Table, args.Keys, args.Values)
+	}
+	for i, key := range args.Keys {
+		if err := t.db.Update(t.ctx, args.Table, key, args.Values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// BatchDelete deletes records, one by one if the database has no batches.
+func (t *Thread) BatchDelete(args *BatchArgs, _ *Empty) error {
+	if t.ctx == nil {
+		return errNoThread
+	}
+	if batchDB, ok := t.db.(ycsb.BatchDB); ok {
+		return batchDB.BatchDelete(t.ctx, args.Table, args.Keys)
+	}
+	for _, key := range args.Keys {
+		if err := t.db.Delete(t.ctx, args.Table, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lua
+
+import (
+	"fmt"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+	lua "github.com/yuin/gopher-lua"
+)
+
+// nextOpFunc is the function the script must define, it's called with the
+// thread id and the operation sequence number of the thread and returns a
+// table like {op = "read", key = 42, fields = {"field0"}, count = 10}.
+const nextOpFunc = "next_op"
+
+type luaScript struct {
+	l      *lua.LState
+	fn     lua.LValue
+	thread lua.LNumber
+}
+
+type luaCreator struct{}
+
+func (luaCreator) Create(p *properties.Properties, path string, threadID int) (ycsb.OpScript, error) {
+	l := lua.NewState()
+	// prop(name, default) returns a workload property.
+	l.SetGlobal("prop", l.NewFunction(func(l *lua.LState) int {
+		l.Push(lua.LString(p.GetString(l.CheckString(1), l.OptString(2, ""))))
+		return 1
+	}))
+
+	if err := l.DoFile(path); err != nil {
+		l.Close()
+		return nil, err
+	}
+
+	fn := l.GetGlobal(nextOpFunc)
+	if fn.Type() != lua.LTFunction {
+		l.Close()
+		return nil, fmt.Errorf("%s doesn't define the %s function", path, nextOpFunc)
+	}
+
+	return &luaScript{
+		l:      l,
+		fn:     fn,
+		thread: lua.LNumber(threadID),
+	}, nil
+}
+
+func (s *luaScript) Next(seq int64) (ycsb.ScriptOp, error) {
+	var op ycsb.ScriptOp
+	if err := s.l.CallByParam(lua.P{Fn: s.fn, NRet: 1, Protect: true}, s.thread, lua.LNumber(seq)); err != nil {
+		return op, err
+	}
+	ret := s.l.Get(-1)
+	s.l.Pop(1)
+
+	t, ok := ret.(*lua.LTable)
+	if !ok {
+		return op, fmt.Errorf("%s must return a table, but got %s", nextOpFunc, ret.Type())
+	}
+
+	op.Op = lua.LVAsString(t.RawGetString("op"))
+	switch key := t.RawGetString("key").(type) {
+	case lua.LNumber:
+		op.KeyNum = int64(key)
+	case lua.LString:
+		op.Key = string(key)
+	default:
+		return op, fmt.Errorf("%s must return a number or string key, but got %s", nextOpFunc, key.Type())
+	}
+	if fields, ok := t.RawGetString("fields").(*lua.LTable); ok {
+		for i := 1; i <= fields.Len(); i++ {
+			op.Fields = append(op.Fields, lua.LVAsString(fields.RawGetInt(i)))
+		}
+	}
+	op.Count = int(lua.LVAsNumber(t.RawGetString("count")))
+	return op, nil
+}
+
+func (s *luaScript) Close() {
+	s.l.Close()
+}
+
+func init() {
+	ycsb.RegisterOpScriptCreator(".lua", luaCreator{})
+}
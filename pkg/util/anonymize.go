@@ -0,0 +1,73 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"math/rand"
+)
+
+// Anonymizer hides the keys and values of a data set so it can be shared.
+// The keys are replaced by their HMAC and the values by random letters, both
+// keep their lengths so the data set keeps its size and shape. The same key
+// is always replaced the same way.
+type Anonymizer struct {
+	secret []byte
+	redact bool
+}
+
+// NewAnonymizer creates an Anonymizer, the keys are kept if the secret is
+// empty.
+func NewAnonymizer(secret []byte, redact bool) *Anonymizer {
+	return &Anonymizer{secret: secret, redact: redact}
+}
+
+// Key returns the key to export, the hex HMAC of the key stretched or cut
+// to its length.
+func (a *Anonymizer) Key(key string) string {
+	if len(a.secret) == 0 {
+		return key
+	}
+
+	out := make([]byte, 0, len(key)+sha256.Size*2)
+	var counter [4]byte
+	for i := uint32(0); len(out) < len(key); i++ {
+		mac := hmac.New(sha256.New, a.secret)
+		binary.BigEndian.PutUint32(counter[:], i)
+		mac.Write(counter[:])
+		mac.Write(Slice(key))
+		out = hex.AppendEncode(out, mac.Sum(nil))
+	}
+	return string(out[:len(key)])
+}
+
+// Values returns the values to export, random letters seeded by the key if
+// the values are redacted.
+func (a *Anonymizer) Values(key string, values map[string][]byte) map[string][]byte {
+	if !a.redact {
+		return values
+	}
+
+	redacted := make(map[string][]byte, len(values))
+	for field, value := range values {
+		r := rand.New(rand.NewSource(StringHash64(key + "\x00" + field)))
+		v := make([]byte, len(value))
+		RandBytes(r, v)
+		redacted[field] = v
+	}
+	return redacted
+}
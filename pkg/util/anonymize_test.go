@@ -0,0 +1,63 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestAnonymizerKey(t *testing.T) {
+	a := NewAnonymizer([]byte("secret"), false)
+
+	for _, key := range []string{"", "k", "user6284781860667377211", string(make([]byte, 100))} {
+		hashed := a.Key(key)
+		if len(hashed) != len(key) {
+			t.Fatalf("expected %d bytes, got %q", len(key), hashed)
+		}
+		if hashed != a.Key(key) {
+			t.Fatalf("expected the same key for %q", key)
+		}
+		if len(key) > 1 && hashed == key {
+			t.Fatalf("expected %q to be hashed", key)
+		}
+	}
+
+	if a.Key("user1") == NewAnonymizer([]byte("other"), false).Key("user1") {
+		t.Fatal("expected another secret to hash the key differently")
+	}
+	if key := NewAnonymizer(nil, false).Key("user1"); key != "user1" {
+		t.Fatalf("expected the key to be kept, got %q", key)
+	}
+}
+
+func TestAnonymizerValues(t *testing.T) {
+	values := map[string][]byte{"field0": []byte("private"), "field1": {}}
+
+	if v := NewAnonymizer(nil, false).Values("user1", values); !bytes.Equal(v["field0"], values["field0"]) {
+		t.Fatalf("expected the values to be kept, got %q", v)
+	}
+
+	a := NewAnonymizer(nil, true)
+	redacted := a.Values("user1", values)
+	if len(redacted) != 2 || len(redacted["field0"]) != 7 || len(redacted["field1"]) != 0 {
+		t.Fatalf("unexpected redacted values %q", redacted)
+	}
+	if bytes.Equal(redacted["field0"], values["field0"]) {
+		t.Fatal("expected the value to be redacted")
+	}
+	if again := a.Values("user1", values); !bytes.Equal(again["field0"], redacted["field0"]) {
+		t.Fatal("expected the same redacted value for the same key")
+	}
+}
@@ -0,0 +1,169 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// chart layout in pixels.
+const (
+	chartWidth  = 800
+	chartHeight = 400
+	chartLeft   = 80
+	chartRight  = 180
+	chartTop    = 40
+	chartBottom = 50
+)
+
+var chartColors = []string{"#1f77b4", "#ff7f0e", "#2ca02c", "#d62728", "#9467bd", "#8c564b", "#e377c2", "#7f7f7f", "#bcbd22", "#17becf"}
+
+// ChartSeries is a line of a chart.
+type ChartSeries struct {
+	Name string
+	X    []float64
+	Y    []float64
+}
+
+// Chart is a line chart rendered as SVG.
+type Chart struct {
+	Title  string
+	XLabel string
+	YLabel string
+	// LogX plots the x axis on a log10 scale, the points with x <= 0 are
+	// left out.
+	LogX   bool
+	Series []ChartSeries
+}
+
+type chartTick struct {
+	value float64
+	label string
+}
+
+// WriteSVG renders the chart as a standalone SVG document.
+func (c *Chart) WriteSVG(w io.Writer) error {
+	x := func(v float64) (float64, bool) {
+		if !c.LogX {
+			return v, true
+		}
+		return math.Log10(v), v > 0
+	}
+
+	minX, maxX := math.Inf(1), math.Inf(-1)
+	minY, maxY := 0.0, math.Inf(-1)
+	for _, s := range c.Series {
+		for i := range s.X {
+			vx, ok := x(s.X[i])
+			if !ok {
+				continue
+			}
+			minX, maxX = math.Min(minX, vx), math.Max(maxX, vx)
+			minY, maxY = math.Min(minY, s.Y[i]), math.Max(maxY, s.Y[i])
+		}
+	}
+	if math.IsInf(minX, 1) {
+		minX, maxX, maxY = 0, 1, 1
+	}
+	if maxX == minX {
+		maxX = minX + 1
+	}
+	if maxY == minY {
+		maxY = minY + 1
+	}
+
+	plotW := float64(chartWidth - chartLeft - chartRight)
+	plotH := float64(chartHeight - chartTop - chartBottom)
+	px := func(v float64) float64 { return chartLeft + (v-minX)/(maxX-minX)*plotW }
+	py := func(v float64) float64 { return chartTop + plotH - (v-minY)/(maxY-minY)*plotH }
+
+	var b bytes.Buffer
+	fmt.Fprintf(&b, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" font-family="sans-serif" font-size="12">`+"\n", chartWidth, chartHeight)
+	fmt.Fprintf(&b, `<rect width="%d" height="%d" fill="white"/>`+"\n", chartWidth, chartHeight)
+	if c.Title != "" {
+		fmt.Fprintf(&b, `<text x="%d" y="24" font-size="16" text-anchor="middle">%s</text>`+"\n", chartWidth/2, html.EscapeString(c.Title))
+	}
+
+	var xTicks []chartTick
+	if c.LogX {
+		for e := math.Ceil(minX); e <= maxX; e++ {
+			xTicks = append(xTicks, chartTick{e, formatTick(math.Pow(10, e))})
+		}
+	} else {
+		xTicks = niceTicks(minX, maxX)
+	}
+	for _, t := range xTicks {
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", px(t.value), chartTop, px(t.value), chartTop+plotH)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%.1f" text-anchor="middle">%s</text>`+"\n", px(t.value), chartTop+plotH+16, t.label)
+	}
+	for _, t := range niceTicks(minY, maxY) {
+		fmt.Fprintf(&b, `<line x1="%d" y1="%.1f" x2="%.1f" y2="%.1f" stroke="#ddd"/>`+"\n", chartLeft, py(t.value), chartLeft+plotW, py(t.value))
+		fmt.Fprintf(&b, `<text x="%d" y="%.1f" text-anchor="end">%s</text>`+"\n", chartLeft-6, py(t.value)+4, t.label)
+	}
+	fmt.Fprintf(&b, `<rect x="%d" y="%d" width="%.1f" height="%.1f" fill="none" stroke="black"/>`+"\n", chartLeft, chartTop, plotW, plotH)
+	fmt.Fprintf(&b, `<text x="%.1f" y="%d" text-anchor="middle">%s</text>`+"\n", chartLeft+plotW/2, chartHeight-10, html.EscapeString(c.XLabel))
+	fmt.Fprintf(&b, `<text x="16" y="%.1f" text-anchor="middle" transform="rotate(-90 16 %.1f)">%s</text>`+"\n",
+		chartTop+plotH/2, chartTop+plotH/2, html.EscapeString(c.YLabel))
+
+	for i, s := range c.Series {
+		color := chartColors[i%len(chartColors)]
+		points := make([]string, 0, len(s.X))
+		for j := range s.X {
+			if vx, ok := x(s.X[j]); ok {
+				points = append(points, fmt.Sprintf("%.1f,%.1f", px(vx), py(s.Y[j])))
+			}
+		}
+		fmt.Fprintf(&b, `<polyline fill="none" stroke="%s" stroke-width="1.5" points="%s"/>`+"\n", color, strings.Join(points, " "))
+
+		ly := chartTop + 10 + i*18
+		fmt.Fprintf(&b, `<line x1="%.1f" y1="%d" x2="%.1f" y2="%d" stroke="%s" stroke-width="3"/>`+"\n",
+			chartLeft+plotW+12, ly, chartLeft+plotW+32, ly, color)
+		fmt.Fprintf(&b, `<text x="%.1f" y="%d">%s</text>`+"\n", chartLeft+plotW+38, ly+4, html.EscapeString(s.Name))
+	}
+	b.WriteString("</svg>\n")
+
+	_, err := w.Write(b.Bytes())
+	return err
+}
+
+// niceTicks returns about 5 ticks at round values between min and max.
+func niceTicks(min float64, max float64) []chartTick {
+	raw := (max - min) / 5
+	step := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, m := range []float64{1, 2, 5, 10} {
+		if step*m >= raw {
+			step *= m
+			break
+		}
+	}
+
+	var ticks []chartTick
+	for v := math.Ceil(min/step) * step; v <= max+step*1e-9; v += step {
+		ticks = append(ticks, chartTick{v, formatTick(v)})
+	}
+	return ticks
+}
+
+func formatTick(v float64) string {
+	if math.Abs(v) < 1e-9 {
+		return "0"
+	}
+	return strconv.FormatFloat(v, 'g', 4, 64)
+}
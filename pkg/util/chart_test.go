@@ -0,0 +1,58 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestNiceTicks(t *testing.T) {
+	var labels []string
+	for _, tick := range niceTicks(0, 97) {
+		labels = append(labels, tick.label)
+	}
+	if got := strings.Join(labels, " "); got != "0 20 40 60 80" {
+		t.Fatalf("want 0 20 40 60 80, but got %s", got)
+	}
+}
+
+func TestChartWriteSVG(t *testing.T) {
+	c := &Chart{
+		Title: "latency <READ>",
+		LogX:  true,
+		Series: []ChartSeries{
+			{Name: "READ", X: []float64{0, 10, 100, 1000}, Y: []float64{0, 50, 99, 100}},
+		},
+	}
+	var b bytes.Buffer
+	if err := c.WriteSVG(&b); err != nil {
+		t.Fatal(err)
+	}
+	svg := b.String()
+	if !strings.Contains(svg, "latency &lt;READ&gt;") {
+		t.Fatalf("the title is not escaped: %s", svg)
+	}
+	// the point at 0 can't be on a log scale.
+	if points := strings.Count(svg[strings.Index(svg, "<polyline"):], ","); points != 3 {
+		t.Fatalf("want 3 points, but got %d", points)
+	}
+
+	// an empty chart still renders.
+	b.Reset()
+	if err := (&Chart{}).WriteSVG(&b); err != nil || !strings.HasSuffix(b.String(), "</svg>\n") {
+		t.Fatalf("empty chart: %v %s", err, b.String())
+	}
+}
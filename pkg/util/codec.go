@@ -0,0 +1,192 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"fmt"
+	"math/rand"
+	"sort"
+	"unicode/utf8"
+)
+
+// codecEdgeLengths are the value lengths around the boundaries of the
+// varint encoded lengths.
+var codecEdgeLengths = []int{0, 1, 63, 64, 65, 127, 128, 129, 8191, 8192, 8193}
+
+// CodecMismatch is a row the RowCodec didn't round-trip.
+type CodecMismatch struct {
+	Row    int
+	Kind   string
+	Reason string
+	Values map[string][]byte
+}
+
+func (m CodecMismatch) String() string {
+	fields := make([]string, 0, len(m.Values))
+	for field := range m.Values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "row %d (%s): %s\n", m.Row, m.Kind, m.Reason)
+	for _, field := range fields {
+		v := m.Values[field]
+		if len(v) > 32 {
+			fmt.Fprintf(&b, "  %q: %d bytes %q...\n", field, len(v), v[:32])
+		} else {
+			fmt.Fprintf(&b, "  %q: %d bytes %q\n", field, len(v), v)
+		}
+	}
+	return b.String()
+}
+
+func randBinary(r *rand.Rand, n int) []byte {
+	v := make([]byte, n)
+	r.Read(v)
+	return v
+}
+
+func randUnicode(r *rand.Rand, n int) []byte {
+	v := make([]byte, 0, n)
+	for len(v) < n {
+		c := rune(r.Intn(0x10FFFF))
+		if !utf8.ValidRune(c) {
+			continue
+		}
+		v = utf8.AppendRune(v, c)
+	}
+	return v
+}
+
+// codecRow generates a row of the given kind, it returns whether the codec
+// must reject the row.
+func codecRow(codec *RowCodec, r *rand.Rand, kind string) (map[string][]byte, bool) {
+	fields := codec.fields
+	values := make(map[string][]byte)
+	switch kind {
+	case "empty":
+	case "empty values":
+		for _, field := range fields {
+			if r.Intn(2) == 0 {
+				values[field] = []byte{}
+			}
+		}
+	case "same length":
+		n := r.Intn(256)
+		for _, field := range fields {
+			values[field] = randBinary(r, n)
+		}
+	case "edge lengths":
+		for _, field := range fields {
+			values[field] = randBinary(r, codecEdgeLengths[r.Intn(len(codecEdgeLengths))])
+		}
+	case "unicode values":
+		for _, field := range fields {
+			values[field] = randUnicode(r, r.Intn(64))
+		}
+	case "unknown field":
+		for _, field := range fields[:r.Intn(len(fields)+1)] {
+			values[field] = randBinary(r, r.Intn(16))
+		}
+		values[string(randUnicode(r, 1+r.Intn(8)))] = randBinary(r, r.Intn(16))
+		return values, true
+	default:
+		for _, field := range fields {
+			if r.Intn(2) == 0 {
+				values[field] = randBinary(r, r.Intn(512))
+			}
+		}
+	}
+	return values, false
+}
+
+// CodecKinds are the kinds of rows VerifyRowCodec generates.
+var CodecKinds = []string{"random", "empty", "empty values", "same length", "edge lengths", "unicode values", "unknown field"}
+
+// VerifyRowCodec round-trips rows random rows of every kind through the
+// codec, all the fields and a random subset of them are decoded again. It
+// calls fn with every row which didn't round-trip and returns their number.
+func VerifyRowCodec(codec *RowCodec, r *rand.Rand, rows int, fn func(m CodecMismatch)) int {
+	mismatches := 0
+	mismatch := func(row int, kind string, values map[string][]byte, format string, args ...interface{}) {
+		mismatches++
+		fn(CodecMismatch{Row: row, Kind: kind, Reason: fmt.Sprintf(format, args...), Values: values})
+	}
+
+	var buf []byte
+	for i := 0; i < rows; i++ {
+		kind := CodecKinds[i%len(CodecKinds)]
+		values, reject := codecRow(codec, r, kind)
+
+		row, err := codec.Encode(buf, values)
+		if reject {
+			if err == nil {
+				mismatch(i, kind, values, "encoding a field outside the schema didn't fail")
+			}
+			continue
+		}
+		if err != nil {
+			mismatch(i, kind, values, "encode failed %v", err)
+			continue
+		}
+		buf = row
+
+		subset := make([]string, 0, len(codec.fields))
+		for _, field := range codec.fields {
+			if r.Intn(2) == 0 {
+				subset = append(subset, field)
+			}
+		}
+		for _, fields := range [][]string{nil, subset} {
+			decoded, err := codec.Decode(row, fields)
+			if err != nil {
+				mismatch(i, kind, values, "decode of %d fields failed %v", len(fields), err)
+				break
+			}
+			if reason := diffDecoded(values, decoded, fields); reason != "" {
+				mismatch(i, kind, values, "decode of %d fields: %s", len(fields), reason)
+				break
+			}
+		}
+	}
+	return mismatches
+}
+
+// diffDecoded compares the decoded fields with the encoded values, no
+// fields are all of them.
+func diffDecoded(values map[string][]byte, decoded map[string][]byte, fields []string) string {
+	expected := len(values)
+	if len(fields) > 0 {
+		expected = 0
+		for _, field := range fields {
+			if _, ok := values[field]; ok {
+				expected++
+			}
+		}
+	}
+	if len(decoded) != expected {
+		return fmt.Sprintf("%d fields decoded, %d expected", len(decoded), expected)
+	}
+	for field, v := range decoded {
+		want, ok := values[field]
+		if !ok {
+			return fmt.Sprintf("field %q wasn't encoded", field)
+		}
+		if !bytes.Equal(v, want) {
+			return fmt.Sprintf("field %q decoded as %d bytes, %d expected", field, len(v), len(want))
+		}
+	}
+	return ""
+}
@@ -0,0 +1,98 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/magiconair/properties"
+)
+
+func TestVerifyRowCodec(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set("fieldcount", "10")
+	codec := NewRowCodec(p)
+
+	n := VerifyRowCodec(codec, rand.New(rand.NewSource(1)), 10000, func(m CodecMismatch) {
+		t.Error(m)
+	})
+	if n != 0 {
+		t.Fatalf("expected no mismatches, got %d", n)
+	}
+}
+
+func TestRowCodecUnknownField(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set("fieldcount", "2")
+	codec := NewRowCodec(p)
+
+	if _, err := codec.Encode(nil, map[string][]byte{"field0": []byte("a"), "name": []byte("b")}); err == nil {
+		t.Fatal("expected encoding an unknown field to fail")
+	}
+
+	row, err := codec.Encode(nil, map[string][]byte{"field0": []byte("a")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	m, err := codec.Decode(row, []string{"field0", "name"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(m) != 1 || string(m["field0"]) != "a" {
+		t.Fatalf("unexpected decoded row %v", m)
+	}
+}
+
+func TestRowCodecMetadata(t *testing.T) {
+	p := properties.NewProperties()
+	p.Set("fieldcount", "2")
+	p.Set("row.metadata", "true")
+	codec := NewRowCodec(p)
+
+	values := map[string][]byte{"field0": []byte("a"), "field1": []byte("b")}
+	first, err := codec.Encode(nil, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := codec.Encode(nil, values)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := codec.Decode(first, nil)
+	if err != nil || len(m) != 2 || string(m["field0"]) != "a" {
+		t.Fatalf("unexpected decoded row %v %v", m, err)
+	}
+
+	firstMeta, ok, err := codec.DecodeMeta(first)
+	if err != nil || !ok {
+		t.Fatalf("expected the metadata of the row, got %v %v", ok, err)
+	}
+	secondMeta, _, _ := codec.DecodeMeta(second)
+	if secondMeta.Generation <= firstMeta.Generation || secondMeta.Timestamp.Before(firstMeta.Timestamp) {
+		t.Fatalf("expected increasing metadata, got %+v and %+v", firstMeta, secondMeta)
+	}
+
+	m, meta, ok, err := codec.DecodeWithMeta(first, []string{"field1"})
+	if err != nil || !ok || len(m) != 1 || meta != firstMeta {
+		t.Fatalf("unexpected row with metadata %v %+v %v %v", m, meta, ok, err)
+	}
+
+	p.Set("row.metadata", "false")
+	plain, _ := NewRowCodec(p).Encode(nil, values)
+	if _, ok, err := codec.DecodeMeta(plain); ok || err != nil {
+		t.Fatalf("expected no metadata, got %v %v", ok, err)
+	}
+}
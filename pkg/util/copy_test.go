@@ -0,0 +1,59 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCopyPath(t *testing.T) {
+	src := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(src, "a", "b"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	files := map[string]string{
+		"data":                         "x",
+		filepath.Join("a", "b", "log"): "yy",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(src, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	dst := filepath.Join(t.TempDir(), "copy")
+	if err := CopyPath(src, dst); err != nil {
+		t.Fatal(err)
+	}
+	for name, content := range files {
+		b, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != content {
+			t.Fatalf("%s: want %q, but got %q", name, content, b)
+		}
+	}
+
+	// a single file is copied to the destination path.
+	file := filepath.Join(t.TempDir(), "file")
+	if err := CopyPath(filepath.Join(src, "data"), file); err != nil {
+		t.Fatal(err)
+	}
+	if b, err := os.ReadFile(file); err != nil || string(b) != "x" {
+		t.Fatalf("want %q, but got %q %v", "x", b, err)
+	}
+}
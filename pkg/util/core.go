@@ -14,17 +14,32 @@
 package util
 
 import (
+	"encoding/binary"
 	"fmt"
 	"sort"
+	"sync/atomic"
+	"time"
 
 	"github.com/magiconair/properties"
 	"github.com/pingcap/go-ycsb/pkg/prop"
 )
 
+// maxFieldCount returns the largest field count among all the tables, so a
+// single codec can serve tables with per-table field counts.
+func maxFieldCount(p *properties.Properties) int64 {
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	for _, table := range TableNames(p) {
+		if n := p.GetInt64(TablePrefix(table)+prop.FieldCount, fieldCount); n > fieldCount {
+			fieldCount = n
+		}
+	}
+	return fieldCount
+}
+
 // createFieldIndices is a helper function to create a field -> index mapping
 // for the core workload
 func createFieldIndices(p *properties.Properties) map[string]int64 {
-	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	fieldCount := maxFieldCount(p)
 	m := make(map[string]int64, fieldCount)
 	for i := int64(0); i < fieldCount; i++ {
 		field := fmt.Sprintf("field%d", i)
@@ -35,7 +50,7 @@ func createFieldIndices(p *properties.Properties) map[string]int64 {
 
 // allFields is a helper function to create all fields
 func allFields(p *properties.Properties) []string {
-	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	fieldCount := maxFieldCount(p)
 	fields := make([]string, 0, fieldCount)
 	for i := int64(0); i < fieldCount; i++ {
 		field := fmt.Sprintf("field%d", i)
@@ -44,10 +59,30 @@ func allFields(p *properties.Properties) []string {
 	return fields
 }
 
+// The metadata of a row is kept in columns with negative indices, which
+// no field has, so decoding the fields skips them.
+const (
+	rowTimestampColumn  int64 = -1
+	rowGenerationColumn int64 = -2
+)
+
+// rowGeneration is the last generation given to a row. It starts at the
+// start time in nanoseconds, so the generations increase across runs too.
+var rowGeneration = uint64(time.Now().UnixNano())
+
+// RowMeta is the metadata embedded in a row with prop.RowMetadata.
+type RowMeta struct {
+	// Timestamp is when the row was encoded.
+	Timestamp time.Time
+	// Generation increases with every row encoded.
+	Generation uint64
+}
+
 // RowCodec is a helper struct to encode and decode TiDB format row
 type RowCodec struct {
 	fieldIndices map[string]int64
 	fields       []string
+	metadata     bool
 }
 
 // NewRowCodec creates the RowCodec
@@ -55,43 +90,90 @@ func NewRowCodec(p *properties.Properties) *RowCodec {
 	return &RowCodec{
 		fieldIndices: createFieldIndices(p),
 		fields:       allFields(p),
+		metadata:     p.GetBool(prop.RowMetadata, prop.RowMetadataDefault),
 	}
 }
 
+// DecodeMeta returns the metadata of the row, false if the row has none.
+func (r *RowCodec) DecodeMeta(row []byte) (RowMeta, bool, error) {
+	data, err := DecodeRow(row)
+	if err != nil {
+		return RowMeta{}, false, err
+	}
+
+	meta, ok := rowMeta(data)
+	return meta, ok, nil
+}
+
+func rowMeta(data map[int64][]byte) (RowMeta, bool) {
+	ts, ok := data[rowTimestampColumn]
+	gen, ok2 := data[rowGenerationColumn]
+	if !ok || !ok2 || len(ts) != 8 || len(gen) != 8 {
+		return RowMeta{}, false
+	}
+	return RowMeta{
+		Timestamp:  time.Unix(0, int64(binary.BigEndian.Uint64(ts))),
+		Generation: binary.BigEndian.Uint64(gen),
+	}, true
+}
+
 // Decode decodes the row and returns a field-value map
 func (r *RowCodec) Decode(row []byte, fields []string) (map[string][]byte, error) {
+	res, _, _, err := r.DecodeWithMeta(row, fields)
+	return res, err
+}
+
+// DecodeWithMeta decodes the row like Decode and returns its metadata too,
+// false if the row has none.
+func (r *RowCodec) DecodeWithMeta(row []byte, fields []string) (map[string][]byte, RowMeta, bool, error) {
 	if len(fields) == 0 {
 		fields = r.fields
 	}
 
 	data, err := DecodeRow(row)
 	if err != nil {
-		return nil, err
+		return nil, RowMeta{}, false, err
 	}
 
 	res := make(map[string][]byte, len(fields))
 	for _, field := range fields {
-		i := r.fieldIndices[field]
+		i, ok := r.fieldIndices[field]
+		if !ok {
+			continue
+		}
 		if v, ok := data[i]; ok {
 			res[field] = v
 		}
 	}
 
-	return res, nil
+	meta, ok := rowMeta(data)
+	return res, meta, ok, nil
 }
 
-// Encode encodes the values
+// Encode encodes the values, the fields must be in the schema since the
+// row only keeps their indices.
 func (r *RowCodec) Encode(buf []byte, values map[string][]byte) ([]byte, error) {
-	cols := make([][]byte, 0, len(values))
-	colIDs := make([]int64, 0, len(values))
+	cols := make([][]byte, 0, len(values)+2)
+	colIDs := make([]int64, 0, len(values)+2)
 
 	for k, v := range values {
-		i := r.fieldIndices[k]
+		i, ok := r.fieldIndices[k]
+		if !ok {
+			return nil, fmt.Errorf("unknown field %q", k)
+		}
 		cols = append(cols, v)
 		colIDs = append(colIDs, i)
 	}
 
-	rowData, err := EncodeRow(cols, colIDs, buf)
+	if r.metadata {
+		var meta [16]byte
+		binary.BigEndian.PutUint64(meta[:8], uint64(time.Now().UnixNano()))
+		binary.BigEndian.PutUint64(meta[8:], atomic.AddUint64(&rowGeneration, 1))
+		cols = append(cols, meta[:8], meta[8:])
+		colIDs = append(colIDs, rowTimestampColumn, rowGenerationColumn)
+	}
+
+	rowData, err := EncodeVersionedRow(cols, colIDs, buf)
 	return rowData, err
 }
 
@@ -0,0 +1,136 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Cron is a schedule in the cron syntax of five fields: minute, hour, day of
+// month, month and day of week, with Sunday as 0 or 7. Every field is *, a
+// value, a range a-b, either with a step /n, or a list of them separated by
+// commas.
+type Cron struct {
+	minute, hour, dom, month, dow uint64
+	// as in cron, a day matches either restricted day field if both are.
+	domAny, dowAny bool
+}
+
+// ParseCron parses the schedule.
+func ParseCron(spec string) (*Cron, error) {
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron %q: want 5 fields, but got %d", spec, len(fields))
+	}
+
+	c := new(Cron)
+	var err error
+	if c.minute, _, err = parseCronField(fields[0], 0, 59); err != nil {
+		return nil, fmt.Errorf("cron %q: minute %v", spec, err)
+	}
+	if c.hour, _, err = parseCronField(fields[1], 0, 23); err != nil {
+		return nil, fmt.Errorf("cron %q: hour %v", spec, err)
+	}
+	if c.dom, c.domAny, err = parseCronField(fields[2], 1, 31); err != nil {
+		return nil, fmt.Errorf("cron %q: day of month %v", spec, err)
+	}
+	if c.month, _, err = parseCronField(fields[3], 1, 12); err != nil {
+		return nil, fmt.Errorf("cron %q: month %v", spec, err)
+	}
+	if c.dow, c.dowAny, err = parseCronField(fields[4], 0, 7); err != nil {
+		return nil, fmt.Errorf("cron %q: day of week %v", spec, err)
+	}
+	if c.dow&(1<<7) != 0 {
+		c.dow |= 1
+	}
+	return c, nil
+}
+
+// parseCronField returns the bit set of the values of the field and whether
+// it's *.
+func parseCronField(field string, min int, max int) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, step := part, 1
+		if i := strings.IndexByte(part, '/'); i >= 0 {
+			n, err := strconv.Atoi(part[i+1:])
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("bad step %q", part)
+			}
+			rng, step = part[:i], n
+		}
+
+		lo, hi := min, max
+		if rng != "*" {
+			var err error
+			bounds := strings.SplitN(rng, "-", 2)
+			if lo, err = strconv.Atoi(bounds[0]); err != nil {
+				return 0, false, fmt.Errorf("bad value %q", part)
+			}
+			hi = lo
+			if len(bounds) == 2 {
+				if hi, err = strconv.Atoi(bounds[1]); err != nil {
+					return 0, false, fmt.Errorf("bad value %q", part)
+				}
+			} else if step > 1 {
+				// a/n is every n from a on.
+				hi = max
+			}
+			if lo < min || hi > max || lo > hi {
+				return 0, false, fmt.Errorf("%q is out of %d-%d", part, min, max)
+			}
+		}
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+	return bits, field == "*", nil
+}
+
+func (c *Cron) dayMatches(t time.Time) bool {
+	dom := c.dom&(1<<uint(t.Day())) != 0
+	dow := c.dow&(1<<uint(t.Weekday())) != 0
+	if !c.domAny && !c.dowAny {
+		return dom || dow
+	}
+	return dom && dow
+}
+
+// Next returns the first minute after t matching the schedule, in the
+// location of t. It returns the zero time if nothing matches within 5 years,
+// like on February 30th.
+func (c *Cron) Next(t time.Time) time.Time {
+	loc := t.Location()
+	t = time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute()+1, 0, 0, loc)
+	end := t.AddDate(5, 0, 0)
+	for t.Before(end) {
+		y, m, d := t.Date()
+		switch {
+		case c.month&(1<<uint(m)) == 0:
+			t = time.Date(y, m+1, 1, 0, 0, 0, 0, loc)
+		case !c.dayMatches(t):
+			t = time.Date(y, m, d+1, 0, 0, 0, 0, loc)
+		case c.hour&(1<<uint(t.Hour())) == 0:
+			t = time.Date(y, m, d, t.Hour()+1, 0, 0, 0, loc)
+		case c.minute&(1<<uint(t.Minute())) == 0:
+			t = time.Date(y, m, d, t.Hour(), t.Minute()+1, 0, 0, loc)
+		default:
+			return t
+		}
+	}
+	return time.Time{}
+}
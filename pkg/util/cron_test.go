@@ -0,0 +1,58 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCronNext(t *testing.T) {
+	// Wednesday.
+	now := time.Date(2024, 5, 15, 10, 30, 20, 0, time.UTC)
+	cases := []struct {
+		spec string
+		next time.Time
+	}{
+		{"0 2 * * *", time.Date(2024, 5, 16, 2, 0, 0, 0, time.UTC)},
+		{"*/15 * * * *", time.Date(2024, 5, 15, 10, 45, 0, 0, time.UTC)},
+		{"31 10 * * *", time.Date(2024, 5, 15, 10, 31, 0, 0, time.UTC)},
+		{"30 10 * * *", time.Date(2024, 5, 16, 10, 30, 0, 0, time.UTC)},
+		{"0 12 * * 7", time.Date(2024, 5, 19, 12, 0, 0, 0, time.UTC)},
+		{"0 0 * * 1-5", time.Date(2024, 5, 16, 0, 0, 0, 0, time.UTC)},
+		// either day field matches when both are restricted.
+		{"0 0 1 * 5", time.Date(2024, 5, 17, 0, 0, 0, 0, time.UTC)},
+		{"0 0 1,20 6 *", time.Date(2024, 6, 1, 0, 0, 0, 0, time.UTC)},
+		{"5/20 3 * * *", time.Date(2024, 5, 16, 3, 5, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, 2, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 0 30 2 *", time.Time{}},
+	}
+	for _, c := range cases {
+		cron, err := ParseCron(c.spec)
+		if err != nil {
+			t.Fatalf("%s: %v", c.spec, err)
+		}
+		if next := cron.Next(now); !next.Equal(c.next) {
+			t.Fatalf("%s: want %s, but got %s", c.spec, c.next, next)
+		}
+	}
+}
+
+func TestParseCronError(t *testing.T) {
+	for _, spec := range []string{"", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		if _, err := ParseCron(spec); err == nil {
+			t.Fatalf("%q: want an error", spec)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// rowOverhead is the size of the encoded column ID and length of a field.
+const rowOverhead = 4
+
+// EstimateLoadSize returns the logical size in bytes of the records the load
+// phase inserts, the keys and the encoded rows. Field lengths are taken at
+// their maximum, so it's an upper bound for the data set, not for the engine
+// which needs space for its pages and free lists as well.
+func EstimateLoadSize(p *properties.Properties) int64 {
+	recordCount := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	insertStart := p.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+	records := p.GetInt64(prop.InsertCount, recordCount-insertStart)
+
+	fieldCount := p.GetInt64(prop.FieldCount, prop.FieldCountDefault)
+	fieldLength := p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
+	// hashed keys are the prefix and up to 20 digits.
+	keyLength := int64(len(p.GetString(prop.KeyPrefix, prop.KeyPrefixDefault))) + 20
+
+	return records * (keyLength + fieldCount*(fieldLength+rowOverhead))
+}
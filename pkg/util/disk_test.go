@@ -0,0 +1,37 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/magiconair/properties"
+)
+
+func TestEstimateLoadSize(t *testing.T) {
+	p := properties.MustLoadString("recordcount=1000\nfieldcount=2\nfieldlength=10\n")
+	// keys of user + 20 digits and 2 fields of 10 bytes plus their headers.
+	if size := EstimateLoadSize(p); size != 1000*(24+2*14) {
+		t.Fatalf("want %d, but got %d", 1000*(24+2*14), size)
+	}
+
+	p.Set("insertstart", "500")
+	if size := EstimateLoadSize(p); size != 500*(24+2*14) {
+		t.Fatalf("want %d, but got %d", 500*(24+2*14), size)
+	}
+	p.Set("insertcount", "100")
+	if size := EstimateLoadSize(p); size != 100*(24+2*14) {
+		t.Fatalf("want %d, but got %d", 100*(24+2*14), size)
+	}
+}
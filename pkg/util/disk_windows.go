@@ -0,0 +1,21 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "errors"
+
+// FreeDiskSpace isn't supported on Windows.
+func FreeDiskSpace(path string) (uint64, error) {
+	return 0, errors.New("free disk space is not supported on Windows")
+}
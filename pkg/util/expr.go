@@ -0,0 +1,205 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// Expr is an arithmetic expression of numbers and variables with +, -, *, /
+// and parentheses.
+type Expr interface {
+	// Eval evaluates the expression, lookup returns the value of a variable.
+	Eval(lookup func(name string) (float64, bool)) (float64, error)
+}
+
+type numberExpr float64
+
+func (e numberExpr) Eval(_ func(string) (float64, bool)) (float64, error) {
+	return float64(e), nil
+}
+
+type varExpr string
+
+func (e varExpr) Eval(lookup func(string) (float64, bool)) (float64, error) {
+	v, ok := lookup(string(e))
+	if !ok {
+		return 0, fmt.Errorf("unknown variable %s", string(e))
+	}
+	return v, nil
+}
+
+type negExpr struct {
+	x Expr
+}
+
+func (e negExpr) Eval(lookup func(string) (float64, bool)) (float64, error) {
+	v, err := e.x.Eval(lookup)
+	return -v, err
+}
+
+type binaryExpr struct {
+	op   byte
+	l, r Expr
+}
+
+func (e binaryExpr) Eval(lookup func(string) (float64, bool)) (float64, error) {
+	l, err := e.l.Eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+	r, err := e.r.Eval(lookup)
+	if err != nil {
+		return 0, err
+	}
+
+	switch e.op {
+	case '+':
+		return l + r, nil
+	case '-':
+		return l - r, nil
+	case '*':
+		return l * r, nil
+	default:
+		if r == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return l / r, nil
+	}
+}
+
+type exprParser struct {
+	s   string
+	pos int
+}
+
+// ParseExpr parses an arithmetic expression. Variable names are made of
+// letters, digits, '_' and '.' and don't start with a digit.
+func ParseExpr(s string) (Expr, error) {
+	p := &exprParser{s: s}
+	e, err := p.sum()
+	if err != nil {
+		return nil, err
+	}
+	if p.skipSpaces(); p.pos < len(p.s) {
+		return nil, fmt.Errorf("unexpected %q at %d in %q", p.s[p.pos], p.pos, s)
+	}
+	return e, nil
+}
+
+func (p *exprParser) skipSpaces() {
+	for p.pos < len(p.s) && (p.s[p.pos] == ' ' || p.s[p.pos] == '\t') {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space character, or 0 at the end.
+func (p *exprParser) peek() byte {
+	p.skipSpaces()
+	if p.pos == len(p.s) {
+		return 0
+	}
+	return p.s[p.pos]
+}
+
+func (p *exprParser) sum() (Expr, error) {
+	l, err := p.product()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '+' || op == '-'; op = p.peek() {
+		p.pos++
+		r, err := p.product()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryExpr{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) product() (Expr, error) {
+	l, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for op := p.peek(); op == '*' || op == '/'; op = p.peek() {
+		p.pos++
+		r, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		l = binaryExpr{op: op, l: l, r: r}
+	}
+	return l, nil
+}
+
+func (p *exprParser) unary() (Expr, error) {
+	if p.peek() == '-' {
+		p.pos++
+		x, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return negExpr{x: x}, nil
+	}
+	return p.operand()
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isNameChar(c byte) bool {
+	return c == '_' || c == '.' || isDigit(c) || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func (p *exprParser) operand() (Expr, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, fmt.Errorf("unexpected end of %q", p.s)
+	case c == '(':
+		p.pos++
+		e, err := p.sum()
+		if err != nil {
+			return nil, err
+		}
+		if p.peek() != ')' {
+			return nil, fmt.Errorf("missing ) at %d in %q", p.pos, p.s)
+		}
+		p.pos++
+		return e, nil
+	case isDigit(c) || c == '.':
+		start := p.pos
+		for p.pos < len(p.s) && (isDigit(p.s[p.pos]) || p.s[p.pos] == '.' || p.s[p.pos] == 'e' ||
+			((p.s[p.pos] == '-' || p.s[p.pos] == '+') && p.s[p.pos-1] == 'e')) {
+			p.pos++
+		}
+		v, err := strconv.ParseFloat(p.s[start:p.pos], 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %q in %q", p.s[start:p.pos], p.s)
+		}
+		return numberExpr(v), nil
+	case isNameChar(c):
+		start := p.pos
+		for p.pos < len(p.s) && isNameChar(p.s[p.pos]) {
+			p.pos++
+		}
+		return varExpr(p.s[start:p.pos]), nil
+	default:
+		return nil, fmt.Errorf("unexpected %q at %d in %q", c, p.pos, p.s)
+	}
+}
@@ -0,0 +1,66 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestExpr(t *testing.T) {
+	vars := map[string]float64{"watts": 30, "elapsed": 10, "ops": 2e6, "READ.p99": 250}
+	lookup := func(name string) (float64, bool) {
+		v, ok := vars[name]
+		return v, ok
+	}
+
+	cases := []struct {
+		expr string
+		want float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"10 - 4 - 3", 3},
+		{"8 / 2 / 2", 2},
+		{"-2 * -3", 6},
+		{"1.5e3 + .5", 1500.5},
+		{"watts*elapsed/(ops/1e6)", 150},
+		{"READ.p99 / 1000", 0.25},
+	}
+	for _, c := range cases {
+		e, err := ParseExpr(c.expr)
+		if err != nil {
+			t.Fatalf("parse %q failed %v", c.expr, err)
+		}
+		v, err := e.Eval(lookup)
+		if err != nil || v != c.want {
+			t.Fatalf("expect %q to be %v, but got %v, %v", c.expr, c.want, v, err)
+		}
+	}
+
+	for _, s := range []string{"", "1 +", "(1 + 2", "1 2", "3 $ 4", "1..2"} {
+		if _, err := ParseExpr(s); err == nil {
+			t.Fatalf("expect %q to be invalid", s)
+		}
+	}
+
+	for _, s := range []string{"unknown + 1", "ops / (elapsed - 10)"} {
+		e, err := ParseExpr(s)
+		if err != nil {
+			t.Fatalf("parse %q failed %v", s, err)
+		}
+		if _, err := e.Eval(lookup); err == nil {
+			t.Fatalf("expect %q to fail", s)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"hash/fnv"
+	"sort"
+)
+
+var fingerprintTable = crc32.MakeTable(crc32.Castagnoli)
+
+// Fingerprint is an order-independent fingerprint of a data set, the sum of a
+// hash of every key and the CRC of its row. Two data sets have the same
+// fingerprint if they have the same keys with the same rows, regardless of
+// the order they are added in.
+type Fingerprint struct {
+	Records int64
+	Sum     uint64
+}
+
+// RowCRC returns the CRC of the row with its fields in name order.
+func RowCRC(values map[string][]byte) uint32 {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	var lenBuf [binary.MaxVarintLen64]byte
+	crc := uint32(0)
+	for _, field := range fields {
+		n := binary.PutUvarint(lenBuf[:], uint64(len(field)))
+		crc = crc32.Update(crc, fingerprintTable, lenBuf[:n])
+		crc = crc32.Update(crc, fingerprintTable, Slice(field))
+		n = binary.PutUvarint(lenBuf[:], uint64(len(values[field])))
+		crc = crc32.Update(crc, fingerprintTable, lenBuf[:n])
+		crc = crc32.Update(crc, fingerprintTable, values[field])
+	}
+	return crc
+}
+
+// Add adds the row of the key.
+func (f *Fingerprint) Add(key string, values map[string][]byte) {
+	var crc [4]byte
+	binary.BigEndian.PutUint32(crc[:], RowCRC(values))
+
+	h := fnv.New64a()
+	h.Write(Slice(key))
+	h.Write(crc[:])
+
+	f.Records++
+	f.Sum += h.Sum64()
+}
+
+// Merge adds all the rows of o.
+func (f *Fingerprint) Merge(o Fingerprint) {
+	f.Records += o.Records
+	f.Sum += o.Sum
+}
+
+func (f Fingerprint) String() string {
+	return fmt.Sprintf("%d:%016x", f.Records, f.Sum)
+}
@@ -0,0 +1,63 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	rows := map[string]map[string][]byte{
+		"user1": {"field0": []byte("a"), "field1": []byte("b")},
+		"user2": {"field0": []byte("c")},
+		"user3": {},
+	}
+
+	var a, b Fingerprint
+	for _, key := range []string{"user1", "user2", "user3"} {
+		a.Add(key, rows[key])
+	}
+	for _, key := range []string{"user3", "user1", "user2"} {
+		b.Add(key, rows[key])
+	}
+	if a != b {
+		t.Fatalf("expect the same fingerprint regardless of order, but got %s and %s", a, b)
+	}
+	if a.Records != 3 {
+		t.Fatalf("expect 3 records, but got %d", a.Records)
+	}
+
+	var c Fingerprint
+	c.Add("user1", rows["user1"])
+	var d Fingerprint
+	d.Add("user2", rows["user2"])
+	d.Add("user3", rows["user3"])
+	c.Merge(d)
+	if a != c {
+		t.Fatalf("expect merged fingerprint %s, but got %s", a, c)
+	}
+
+	var e Fingerprint
+	e.Add("user1", map[string][]byte{"field0": []byte("a"), "field1": []byte("x")})
+	e.Add("user2", rows["user2"])
+	e.Add("user3", rows["user3"])
+	if a == e {
+		t.Fatalf("expect a different fingerprint for a changed row")
+	}
+
+	// the field boundaries are part of the CRC.
+	if RowCRC(map[string][]byte{"ab": []byte("c")}) == RowCRC(map[string][]byte{"a": []byte("bc")}) {
+		t.Fatalf("expect different CRCs for different fields")
+	}
+}
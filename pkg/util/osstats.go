@@ -0,0 +1,147 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"strconv"
+	"time"
+)
+
+// clockTicks is the USER_HZ of the /proc counters, which is 100 on all the
+// supported architectures.
+const clockTicks = 100
+
+// OSStats are the kernel writeback and I/O wait statistics of an interval,
+// read from /proc on Linux.
+type OSStats struct {
+	// DirtyBytes and WritebackBytes are the page cache waiting to be written
+	// back and being written back at the end of the interval.
+	DirtyBytes     uint64 `json:"dirty_bytes"`
+	WritebackBytes uint64 `json:"writeback_bytes"`
+	// IOWait is the share of the interval the process was blocked on block
+	// I/O, like the fsyncs of the engine. It's only counted with the delay
+	// accounting of the kernel enabled (sysctl kernel.task_delayacct=1).
+	IOWait float64 `json:"io_wait"`
+	// CPUIOWait is the share of the CPU time the machine was idle waiting
+	// for I/O.
+	CPUIOWait float64 `json:"cpu_iowait"`
+}
+
+// OSSampler samples the OSStats, the waits are the ones since the previous
+// sample.
+type OSSampler struct {
+	last        time.Time
+	blkioTicks  uint64
+	cpuIOWait   uint64
+	cpuTotal    uint64
+	initialized bool
+}
+
+// Sample returns the statistics since the previous call, it returns false if
+// /proc isn't available or on the first call.
+func (s *OSSampler) Sample() (OSStats, bool) {
+	var stats OSStats
+	meminfo, err := os.ReadFile("/proc/meminfo")
+	if err != nil {
+		return stats, false
+	}
+	stats.DirtyBytes, stats.WritebackBytes = parseMeminfo(meminfo)
+
+	now := time.Now()
+	var blkioTicks, cpuIOWait, cpuTotal uint64
+	if data, err := os.ReadFile("/proc/self/stat"); err == nil {
+		blkioTicks = parseBlkioTicks(data)
+	}
+	if data, err := os.ReadFile("/proc/stat"); err == nil {
+		cpuIOWait, cpuTotal = parseCPUIOWait(data)
+	}
+
+	ok := s.initialized
+	if ok {
+		if elapsed := now.Sub(s.last).Seconds(); elapsed > 0 {
+			stats.IOWait = float64(blkioTicks-s.blkioTicks) / clockTicks / elapsed
+		}
+		if cpuTotal > s.cpuTotal {
+			stats.CPUIOWait = float64(cpuIOWait-s.cpuIOWait) / float64(cpuTotal-s.cpuTotal)
+		}
+	}
+	s.last, s.blkioTicks, s.cpuIOWait, s.cpuTotal = now, blkioTicks, cpuIOWait, cpuTotal
+	s.initialized = true
+	return stats, ok
+}
+
+// parseMeminfo returns the Dirty and Writeback bytes of /proc/meminfo.
+func parseMeminfo(data []byte) (dirty uint64, writeback uint64) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := bytes.Fields(scanner.Bytes())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseUint(string(fields[1]), 10, 64)
+		if err != nil {
+			continue
+		}
+		switch string(fields[0]) {
+		case "Dirty:":
+			dirty = kb << 10
+		case "Writeback:":
+			writeback = kb << 10
+		}
+	}
+	return dirty, writeback
+}
+
+// parseBlkioTicks returns the delayacct_blkio_ticks of /proc/self/stat, the
+// 42nd field. The fields are counted after the command, which may contain
+// spaces and parentheses.
+func parseBlkioTicks(data []byte) uint64 {
+	i := bytes.LastIndexByte(data, ')')
+	if i < 0 {
+		return 0
+	}
+	// the fields after the command start at the 3rd one.
+	fields := bytes.Fields(data[i+1:])
+	if len(fields) < 42-2 {
+		return 0
+	}
+	ticks, _ := strconv.ParseUint(string(fields[42-3]), 10, 64)
+	return ticks
+}
+
+// parseCPUIOWait returns the iowait and total ticks of all the CPUs from the
+// first line of /proc/stat.
+func parseCPUIOWait(data []byte) (iowait uint64, total uint64) {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	fields := bytes.Fields(line)
+	if len(fields) < 6 || string(fields[0]) != "cpu" {
+		return 0, 0
+	}
+	// user nice system idle iowait irq softirq steal, guest time is already
+	// counted in user.
+	for i, f := range fields[1:] {
+		if i >= 8 {
+			break
+		}
+		v, _ := strconv.ParseUint(string(f), 10, 64)
+		total += v
+		if i == 4 {
+			iowait = v
+		}
+	}
+	return iowait, total
+}
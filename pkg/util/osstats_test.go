@@ -0,0 +1,37 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestParseOSStats(t *testing.T) {
+	dirty, writeback := parseMeminfo([]byte("MemTotal:       16318480 kB\nDirty:              4752 kB\nWriteback:           128 kB\nWritebackTmp:          0 kB\n"))
+	if dirty != 4752<<10 || writeback != 128<<10 {
+		t.Fatalf("unexpected dirty %d and writeback %d", dirty, writeback)
+	}
+
+	// the command has a space and a parenthesis, delayacct_blkio_ticks is 37.
+	stat := "1234 (go (ycsb) S 1 1234 1234 0 -1 4194560 1025 0 0 0 12 3 0 0 20 0 9 0 100 1000000 500 18446744073709551615 1 1 0 0 0 0 0 0 0 0 0 0 17 3 0 0 37 0 0 0 0 0 0 0 0 0 0\n"
+	if ticks := parseBlkioTicks([]byte(stat)); ticks != 37 {
+		t.Fatalf("want 37 blkio ticks, but got %d", ticks)
+	}
+	if ticks := parseBlkioTicks([]byte("1234 (go-ycsb) S 1")); ticks != 0 {
+		t.Fatalf("want 0 blkio ticks for a short stat, but got %d", ticks)
+	}
+
+	iowait, total := parseCPUIOWait([]byte("cpu  100 0 50 800 40 0 5 5 20 0\ncpu0 100 0 50 800 40 0 5 5 20 0\n"))
+	if iowait != 40 || total != 1000 {
+		t.Fatalf("unexpected iowait %d and total %d", iowait, total)
+	}
+}
@@ -0,0 +1,87 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"strings"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+)
+
+// OverrideProperties returns a copy of p where every property under the
+// prefix overrides the property without it, e.g. with prefix "table.users."
+// the value of "table.users.fieldcount" replaces the one of "fieldcount".
+func OverrideProperties(p *properties.Properties, prefix string) *properties.Properties {
+	np := properties.NewProperties()
+	np.DisableExpansion = p.DisableExpansion
+	np.Merge(p)
+
+	overrides := p.FilterStripPrefix(prefix)
+	for _, k := range overrides.Keys() {
+		v, _ := overrides.Get(k)
+		np.Set(k, v)
+	}
+	return np
+}
+
+// PhaseProperties returns a copy of p where the "load." or "run." overrides
+// of the phase replace the properties without the prefix, e.g. the value of
+// "load.threadcount" replaces the one of "threadcount" in the load phase. The
+// properties of the load phase named "load.", like load.committer, aren't
+// overrides.
+func PhaseProperties(p *properties.Properties, phase string) *properties.Properties {
+	np := properties.NewProperties()
+	np.DisableExpansion = p.DisableExpansion
+	np.Merge(p)
+
+	prefix := phase + "."
+	overrides := p.FilterStripPrefix(prefix)
+	for _, k := range overrides.Keys() {
+		if strings.HasPrefix(prefix+k, prop.LoadCommitter) {
+			continue
+		}
+		v, _ := overrides.Get(k)
+		np.Set(k, v)
+	}
+	return np
+}
+
+// TablePrefix returns the prefix of the per-table property overrides.
+func TablePrefix(table string) string {
+	return prop.TableName + "." + table + "."
+}
+
+// TableNames returns the tables of the workload, the ones listed in the
+// tables property or the single table property otherwise.
+func TableNames(p *properties.Properties) []string {
+	var tables []string
+	for _, t := range strings.Split(p.GetString(prop.Tables, ""), ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tables = append(tables, t)
+		}
+	}
+	if len(tables) == 0 {
+		tables = append(tables, p.GetString(prop.TableName, prop.TableNameDefault))
+	}
+	return tables
+}
+
+// TableProperties returns the properties of the table with its overrides applied.
+func TableProperties(p *properties.Properties, table string) *properties.Properties {
+	np := OverrideProperties(p, TablePrefix(table))
+	np.Set(prop.TableName, table)
+	np.Delete(prop.Tables)
+	return np
+}
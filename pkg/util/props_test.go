@@ -0,0 +1,71 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/magiconair/properties"
+)
+
+func TestTableProperties(t *testing.T) {
+	p := properties.MustLoadString("fieldcount=10\nrequestdistribution=zipfian\ntables=users, events\ntable.users.fieldcount=20\n")
+
+	if tables := TableNames(p); !reflect.DeepEqual(tables, []string{"users", "events"}) {
+		t.Fatalf("unexpected tables %v", tables)
+	}
+
+	users := TableProperties(p, "users")
+	if v := users.GetInt("fieldcount", 0); v != 20 {
+		t.Errorf("users fieldcount want 20, but got %d", v)
+	}
+	if v := users.GetString("table", ""); v != "users" {
+		t.Errorf("users table want users, but got %s", v)
+	}
+
+	events := TableProperties(p, "events")
+	if v := events.GetInt("fieldcount", 0); v != 10 {
+		t.Errorf("events fieldcount want 10, but got %d", v)
+	}
+	if _, ok := events.Get("tables"); ok {
+		t.Errorf("table properties must not list the tables")
+	}
+	if v := p.GetInt("fieldcount", 0); v != 10 {
+		t.Errorf("global fieldcount must not change, but got %d", v)
+	}
+}
+
+func TestPhaseProperties(t *testing.T) {
+	p := properties.MustLoadString("fredb.sync=full\nload.fredb.sync=off\nrun.threadcount=8\nload.committer=true\n")
+
+	load := PhaseProperties(p, "load")
+	if v := load.GetString("fredb.sync", ""); v != "off" {
+		t.Errorf("load fredb.sync want off, but got %s", v)
+	}
+	if _, ok := load.Get("committer"); ok {
+		t.Errorf("load.committer must not be an override")
+	}
+	if _, ok := load.Get("threadcount"); ok {
+		t.Errorf("the run overrides must not apply to the load")
+	}
+
+	run := PhaseProperties(p, "run")
+	if v := run.GetString("fredb.sync", ""); v != "full" {
+		t.Errorf("run fredb.sync want full, but got %s", v)
+	}
+	if v := run.GetInt("threadcount", 0); v != 8 {
+		t.Errorf("run threadcount want 8, but got %d", v)
+	}
+}
@@ -32,11 +32,26 @@ func EncodeRow(cols [][]byte, colIDs []int64, valBuf []byte) ([]byte, error) {
 	if len(cols) == 0 {
 		return append(valBuf, 0), nil
 	}
+	return appendColumns(valBuf, cols, colIDs), nil
+}
+
+// EncodeVersionedRow encodes the row like EncodeRow after a byte holding
+// RowFormatVersion, so the format can change without invalidating the rows
+// already stored.
+func EncodeVersionedRow(cols [][]byte, colIDs []int64, valBuf []byte) ([]byte, error) {
+	if len(cols) != len(colIDs) {
+		return nil, errors.Errorf("EncodeRow error: cols and colIDs count not match %d vs %d", len(cols), len(colIDs))
+	}
+	valBuf = append(valBuf[:0], rowVersionFlag|RowFormatVersion)
+	return appendColumns(valBuf, cols, colIDs), nil
+}
+
+func appendColumns(b []byte, cols [][]byte, colIDs []int64) []byte {
 	for i := range cols {
-		valBuf = encodeInt64(valBuf, colIDs[i])
-		valBuf = encodeBytes(valBuf, cols[i])
+		b = encodeInt64(b, colIDs[i])
+		b = encodeBytes(b, cols[i])
 	}
-	return valBuf, nil
+	return b
 }
 
 const (
@@ -44,6 +59,27 @@ const (
 	varintFlag       byte = 8
 )
 
+// RowFormatVersion is the version of the rows encoded by
+// EncodeVersionedRow. A versioned row starts with rowVersionFlag or'ed
+// with its version, while a legacy row of EncodeRow starts with the
+// varintFlag of its first column or is a single 0 byte.
+const RowFormatVersion byte = 1
+
+const rowVersionFlag byte = 0x80
+
+// RowVersion returns the format version of the row and its columns, the
+// version of a legacy row is 0.
+func RowVersion(b []byte) (byte, []byte, error) {
+	if len(b) == 0 || b[0]&rowVersionFlag == 0 {
+		return 0, b, nil
+	}
+	version := b[0] &^ rowVersionFlag
+	if version > RowFormatVersion {
+		return version, nil, errors.Errorf("unknown row format version %d, the row was written by a newer version", version)
+	}
+	return version, b[1:], nil
+}
+
 func encodeInt64(b []byte, v int64) []byte {
 	b = append(b, varintFlag)
 	return appendVarint(b, v)
@@ -61,11 +97,15 @@ func appendVarint(b []byte, v int64) []byte {
 	return append(b, data[:n]...)
 }
 
-// DecodeRow decodes a byte slice into columns.
+// DecodeRow decodes a byte slice into columns, the row may be versioned.
 // Row layout: colID1, value1, colID2, value2, .....
 // It is a simplified and specialized version of `github.com/pingcap/tidb/tablecodec.DecodeRow`.
 func DecodeRow(b []byte) (map[int64][]byte, error) {
 	row := make(map[int64][]byte)
+	_, b, err := RowVersion(b)
+	if err != nil {
+		return row, err
+	}
 	if len(b) == 0 {
 		return row, nil
 	}
@@ -36,3 +36,48 @@ func TestCodec(t *testing.T) {
 		}
 	}
 }
+
+func TestVersionedRow(t *testing.T) {
+	colIDs := []int64{0, 3}
+	cols := [][]byte{[]byte("a"), []byte("")}
+
+	legacy, err := EncodeRow(cols, colIDs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	versioned, err := EncodeVersionedRow(cols, colIDs, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	empty, err := EncodeVersionedRow(nil, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, c := range []struct {
+		b       []byte
+		version byte
+		cols    int
+	}{
+		{legacy, 0, 2},
+		{[]byte{0}, 0, 0},
+		{versioned, RowFormatVersion, 2},
+		{empty, RowFormatVersion, 0},
+	} {
+		version, _, err := RowVersion(c.b)
+		if err != nil || version != c.version {
+			t.Fatalf("%q: expected version %d, got %d %v", c.b, c.version, version, err)
+		}
+		row, err := DecodeRow(c.b)
+		if err != nil || len(row) != c.cols {
+			t.Fatalf("%q: expected %d columns, got %v %v", c.b, c.cols, row, err)
+		}
+		if c.cols > 0 && string(row[0]) != "a" {
+			t.Fatalf("%q: unexpected columns %q", c.b, row)
+		}
+	}
+
+	if _, err := DecodeRow([]byte{rowVersionFlag | (RowFormatVersion + 1)}); err == nil {
+		t.Fatal("expected a row of a newer version to fail")
+	}
+}
@@ -0,0 +1,39 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"os"
+	"runtime"
+	"strconv"
+)
+
+// ProcessRSS returns the resident set size of the current process in bytes.
+// It reads /proc/self/statm where available and falls back to the memory
+// obtained from the OS by the Go runtime otherwise.
+func ProcessRSS() uint64 {
+	if data, err := os.ReadFile("/proc/self/statm"); err == nil {
+		fields := bytes.Fields(data)
+		if len(fields) > 1 {
+			if pages, err := strconv.ParseUint(string(fields[1]), 10, 64); err == nil {
+				return pages * uint64(os.Getpagesize())
+			}
+		}
+	}
+
+	var ms runtime.MemStats
+	runtime.ReadMemStats(&ms)
+	return ms.Sys
+}
@@ -0,0 +1,134 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pingcap/errors"
+)
+
+// SpillFileExt is the file extension of the spill files.
+const SpillFileExt = ".spill"
+
+// RemoveSpillFiles removes the spill files of an earlier run in the
+// directory, so they aren't ingested with the ones of the next.
+func RemoveSpillFiles(dir string) error {
+	files, err := filepath.Glob(filepath.Join(dir, "*"+SpillFileExt))
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if err := os.Remove(file); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SpillWriter writes key and encoded row pairs to a spill file.
+// Record layout: uvarint(len(key)), key, uvarint(len(row)), row.
+type SpillWriter struct {
+	f *os.File
+	w *bufio.Writer
+
+	lenBuf [binary.MaxVarintLen64]byte
+}
+
+// NewSpillWriter creates the spill file at path.
+func NewSpillWriter(path string) (*SpillWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &SpillWriter{f: f, w: bufio.NewWriterSize(f, 1<<20)}, nil
+}
+
+func (s *SpillWriter) writeBytes(b []byte) error {
+	n := binary.PutUvarint(s.lenBuf[:], uint64(len(b)))
+	if _, err := s.w.Write(s.lenBuf[:n]); err != nil {
+		return err
+	}
+	_, err := s.w.Write(b)
+	return err
+}
+
+// Write appends a record.
+func (s *SpillWriter) Write(key string, row []byte) error {
+	if err := s.writeBytes(Slice(key)); err != nil {
+		return err
+	}
+	return s.writeBytes(row)
+}
+
+// Close flushes and closes the spill file.
+func (s *SpillWriter) Close() error {
+	if err := s.w.Flush(); err != nil {
+		s.f.Close()
+		return err
+	}
+	return s.f.Close()
+}
+
+// SpillReader reads the records written by SpillWriter.
+type SpillReader struct {
+	f *os.File
+	r *bufio.Reader
+}
+
+// NewSpillReader opens the spill file at path.
+func NewSpillReader(path string) (*SpillReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	return &SpillReader{f: f, r: bufio.NewReaderSize(f, 1<<20)}, nil
+}
+
+func (s *SpillReader) readBytes() ([]byte, error) {
+	n, err := binary.ReadUvarint(s.r)
+	if err != nil {
+		return nil, err
+	}
+	b := make([]byte, n)
+	if _, err := io.ReadFull(s.r, b); err != nil {
+		return nil, errors.Errorf("truncated spill record: %v", err)
+	}
+	return b, nil
+}
+
+// Next returns the next record, or io.EOF when the file is exhausted.
+func (s *SpillReader) Next() (string, []byte, error) {
+	key, err := s.readBytes()
+	if err != nil {
+		return "", nil, err
+	}
+	row, err := s.readBytes()
+	if err == io.EOF {
+		err = errors.New("truncated spill record: missing row")
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	return string(key), row, nil
+}
+
+// Close closes the spill file.
+func (s *SpillReader) Close() error {
+	return s.f.Close()
+}
@@ -0,0 +1,64 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "math/rand"
+
+const splitMixGamma = 0x9e3779b97f4a7c15
+
+func splitMixMix(z uint64) uint64 {
+	z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+	z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+	return z ^ (z >> 31)
+}
+
+// SplitMix64 is a splittable pseudo random source, see
+// "Fast Splittable Pseudorandom Number Generators" by Steele et al.
+type SplitMix64 struct {
+	state uint64
+}
+
+// NewSplitMix64 creates a SplitMix64 source with the seed.
+func NewSplitMix64(seed int64) *SplitMix64 {
+	return &SplitMix64{state: uint64(seed)}
+}
+
+// Uint64 implements the rand.Source64 Uint64 interface.
+func (s *SplitMix64) Uint64() uint64 {
+	s.state += splitMixGamma
+	return splitMixMix(s.state)
+}
+
+// Int63 implements the rand.Source Int63 interface.
+func (s *SplitMix64) Int63() int64 {
+	return int64(s.Uint64() >> 1)
+}
+
+// Seed implements the rand.Source Seed interface.
+func (s *SplitMix64) Seed(seed int64) {
+	s.state = uint64(seed)
+}
+
+// Split returns an independent stream for the index. The stream only depends
+// on the current state and the index, not on how many streams are split.
+func (s *SplitMix64) Split(index int) *SplitMix64 {
+	return &SplitMix64{state: splitMixMix(s.state + uint64(index+1)*splitMixGamma)}
+}
+
+// NewThreadRand returns the random generator of the thread, derived from the
+// global seed and the thread ID, so adding threads doesn't change the
+// sequences generated by the others.
+func NewThreadRand(seed int64, threadID int) *rand.Rand {
+	return rand.New(NewSplitMix64(seed).Split(threadID))
+}
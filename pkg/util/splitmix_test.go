@@ -0,0 +1,36 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestThreadRand(t *testing.T) {
+	a := NewThreadRand(42, 3)
+	b := NewThreadRand(42, 3)
+	c := NewThreadRand(42, 4)
+
+	same := 0
+	for i := 0; i < 100; i++ {
+		x, y, z := a.Int63(), b.Int63(), c.Int63()
+		if x != y {
+			t.Fatalf("thread 3 streams diverge at %d: %d vs %d", i, x, y)
+		}
+		if x == z {
+			same++
+		}
+	}
+	if same == 100 {
+		t.Fatal("thread 3 and thread 4 generate the same stream")
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"math"
+	"sort"
+)
+
+// tQuantiles95 are the two-sided 95% quantiles of the Student's t
+// distribution for 1 to 30 degrees of freedom.
+var tQuantiles95 = []float64{
+	12.706, 4.303, 3.182, 2.776, 2.571, 2.447, 2.365, 2.306, 2.262, 2.228,
+	2.201, 2.179, 2.160, 2.145, 2.131, 2.120, 2.110, 2.101, 2.093, 2.086,
+	2.080, 2.074, 2.069, 2.064, 2.060, 2.056, 2.052, 2.048, 2.045, 2.042,
+}
+
+// SampleStats describes a sample of repeated measurements.
+type SampleStats struct {
+	N      int
+	Mean   float64
+	StdDev float64
+	// CILow and CIHigh bound the 95% confidence interval of the mean.
+	CILow  float64
+	CIHigh float64
+}
+
+// Summarize returns the mean, the sample standard deviation and the 95%
+// confidence interval of the mean of the values. The interval of a single
+// value is the value itself.
+func Summarize(values []float64) SampleStats {
+	s := SampleStats{N: len(values)}
+	if s.N == 0 {
+		return s
+	}
+
+	for _, v := range values {
+		s.Mean += v
+	}
+	s.Mean /= float64(s.N)
+	s.CILow, s.CIHigh = s.Mean, s.Mean
+	if s.N == 1 {
+		return s
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - s.Mean) * (v - s.Mean)
+	}
+	s.StdDev = math.Sqrt(squares / float64(s.N-1))
+
+	t := 1.96
+	if df := s.N - 1; df <= len(tQuantiles95) {
+		t = tQuantiles95[df-1]
+	}
+	half := t * s.StdDev / math.Sqrt(float64(s.N))
+	s.CILow, s.CIHigh = s.Mean-half, s.Mean+half
+	return s
+}
+
+// MedianAbsDeviation returns the median of the values and the median of their
+// absolute deviations from it, a spread the outliers barely move.
+func MedianAbsDeviation(values []float64) (float64, float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+
+	median := medianOf(append([]float64(nil), values...))
+	deviations := make([]float64, len(values))
+	for i, v := range values {
+		deviations[i] = math.Abs(v - median)
+	}
+	return median, medianOf(deviations)
+}
+
+// medianOf sorts the values and returns their median.
+func medianOf(values []float64) float64 {
+	sort.Float64s(values)
+	n := len(values)
+	if n%2 == 1 {
+		return values[n/2]
+	}
+	return (values[n/2-1] + values[n/2]) / 2
+}
@@ -0,0 +1,60 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSummarize(t *testing.T) {
+	s := Summarize([]float64{2, 4, 4, 4, 5, 5, 7, 9})
+	if s.N != 8 || s.Mean != 5 {
+		t.Fatalf("expect 8 values with mean 5, but got %d with mean %v", s.N, s.Mean)
+	}
+	if math.Abs(s.StdDev-2.138) > 0.001 {
+		t.Fatalf("expect a standard deviation of 2.138, but got %v", s.StdDev)
+	}
+	// 2.365 * 2.138 / sqrt(8)
+	if math.Abs(s.CIHigh-6.788) > 0.001 || math.Abs(s.Mean-s.CILow-(s.CIHigh-s.Mean)) > 1e-9 {
+		t.Fatalf("unexpected confidence interval [%v, %v]", s.CILow, s.CIHigh)
+	}
+
+	s = Summarize([]float64{3})
+	if s.StdDev != 0 || s.CILow != 3 || s.CIHigh != 3 {
+		t.Fatalf("expect a single value to be its own interval, but got %+v", s)
+	}
+	if s := Summarize(nil); s.N != 0 {
+		t.Fatalf("expect an empty sample, but got %+v", s)
+	}
+}
+
+func TestMedianAbsDeviation(t *testing.T) {
+	values := []float64{4, 1, 100, 3, 2}
+	median, mad := MedianAbsDeviation(values)
+	if median != 3 || mad != 1 {
+		t.Fatalf("expect median 3 and MAD 1, but got %v and %v", median, mad)
+	}
+	if values[0] != 4 || values[2] != 100 {
+		t.Fatalf("expect the values to be left unsorted, but got %v", values)
+	}
+
+	median, mad = MedianAbsDeviation([]float64{1, 2, 3, 4})
+	if median != 2.5 || mad != 1 {
+		t.Fatalf("expect median 2.5 and MAD 1, but got %v and %v", median, mad)
+	}
+	if median, mad := MedianAbsDeviation(nil); median != 0 || mad != 0 {
+		t.Fatalf("expect 0 for no values, but got %v and %v", median, mad)
+	}
+}
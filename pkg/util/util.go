@@ -18,6 +18,10 @@ import (
 	"math/rand"
 	"os"
 	"sync"
+	"sync/atomic"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
 )
 
 // Fatalf prints the message and exits the program.
@@ -46,28 +50,92 @@ func RandBytes(r *rand.Rand, b []byte) {
 // BufPool is a bytes.Buffer pool
 type BufPool struct {
 	p *sync.Pool
+
+	initialSize int
+	maxRetained int
+
+	gets    int64
+	misses  int64
+	dropped int64
+	newCap  int64
+	getCap  int64
+	putCap  int64
+}
+
+// BufPoolStats are the statistics of a BufPool.
+type BufPoolStats struct {
+	Gets   int64
+	Hits   int64
+	Misses int64
+	// Dropped is the number of buffers larger than the maximum retained size
+	// which were not put back.
+	Dropped int64
+	// Allocated is the total capacity in bytes of the buffers created by the
+	// pool and grown by their users.
+	Allocated int64
 }
 
 // NewBufPool creates a buffer pool.
 func NewBufPool() *BufPool {
-	p := &sync.Pool{
+	return NewSizedBufPool(0, 0)
+}
+
+// NewSizedBufPool creates a buffer pool whose new buffers have the initial
+// size as capacity and which doesn't keep the buffers larger than
+// maxRetained bytes, 0 keeps all of them.
+func NewSizedBufPool(initialSize int, maxRetained int) *BufPool {
+	b := &BufPool{
+		initialSize: initialSize,
+		maxRetained: maxRetained,
+	}
+	b.p = &sync.Pool{
 		New: func() interface{} {
-			return []byte(nil)
+			atomic.AddInt64(&b.misses, 1)
+			atomic.AddInt64(&b.newCap, int64(b.initialSize))
+			if b.initialSize == 0 {
+				return []byte(nil)
+			}
+			return make([]byte, 0, b.initialSize)
 		},
 	}
-	return &BufPool{
-		p: p,
-	}
+	return b
+}
+
+// NewBufPoolFromProps creates a buffer pool sized by the bufpool properties.
+func NewBufPoolFromProps(p *properties.Properties) *BufPool {
+	return NewSizedBufPool(p.GetInt(prop.BufPoolInitialSize, prop.BufPoolInitialSizeDefault),
+		p.GetInt(prop.BufPoolMaxRetained, prop.BufPoolMaxRetainedDefault))
 }
 
 // Get gets a buffer.
 func (b *BufPool) Get() []byte {
 	buf := b.p.Get().([]byte)
 	buf = buf[:0]
+	atomic.AddInt64(&b.gets, 1)
+	atomic.AddInt64(&b.getCap, int64(cap(buf)))
 	return buf
 }
 
 // Put returns a buffer.
 func (b *BufPool) Put(buf []byte) {
+	atomic.AddInt64(&b.putCap, int64(cap(buf)))
+	if b.maxRetained > 0 && cap(buf) > b.maxRetained {
+		atomic.AddInt64(&b.dropped, 1)
+		return
+	}
 	b.p.Put(buf)
 }
+
+// Stats returns the statistics of the pool. The growth of the buffers is
+// only known once they are put back.
+func (b *BufPool) Stats() BufPoolStats {
+	gets := atomic.LoadInt64(&b.gets)
+	misses := atomic.LoadInt64(&b.misses)
+	return BufPoolStats{
+		Gets:      gets,
+		Hits:      gets - misses,
+		Misses:    misses,
+		Dropped:   atomic.LoadInt64(&b.dropped),
+		Allocated: atomic.LoadInt64(&b.newCap) + atomic.LoadInt64(&b.putCap) - atomic.LoadInt64(&b.getCap),
+	}
+}
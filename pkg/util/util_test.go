@@ -0,0 +1,53 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import "testing"
+
+func TestBufPoolStats(t *testing.T) {
+	b := NewSizedBufPool(16, 64)
+
+	buf := b.Get()
+	if cap(buf) != 16 {
+		t.Fatalf("expected a new buffer of capacity 16, got %d", cap(buf))
+	}
+	buf = append(buf, make([]byte, 100)...)
+	grown := cap(buf)
+	// the grown buffer is larger than the retained size and dropped.
+	b.Put(buf)
+
+	s := b.Stats()
+	if s.Gets != 1 || s.Misses != 1 || s.Hits != 0 || s.Dropped != 1 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+	if s.Allocated != int64(grown) {
+		t.Fatalf("expected %d bytes allocated, got %d", grown, s.Allocated)
+	}
+}
+
+func TestBufPoolUnlimited(t *testing.T) {
+	b := NewBufPool()
+
+	buf := append(b.Get(), "abc"...)
+	b.Put(buf)
+	if buf = b.Get(); len(buf) != 0 {
+		t.Fatalf("expected an empty buffer, got %q", buf)
+	}
+	b.Put(buf)
+
+	s := b.Stats()
+	if s.Gets != 2 || s.Hits+s.Misses != 2 || s.Dropped != 0 {
+		t.Fatalf("unexpected stats %+v", s)
+	}
+}
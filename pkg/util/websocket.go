@@ -0,0 +1,143 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const webSocketGUID = "258EAFA5-E914-47DA-95CA-C5AB0DC85B11"
+
+const (
+	webSocketOpText  = 0x1
+	webSocketOpClose = 0x8
+)
+
+// WebSocketAccept returns the Sec-WebSocket-Accept value for the
+// Sec-WebSocket-Key of a handshake request.
+func WebSocketAccept(key string) string {
+	h := sha1.Sum([]byte(key + webSocketGUID))
+	return base64.StdEncoding.EncodeToString(h[:])
+}
+
+// WebSocketConn is the server side of a WebSocket connection which only
+// sends text messages, messages from the client are discarded.
+type WebSocketConn struct {
+	conn net.Conn
+	rw   *bufio.ReadWriter
+}
+
+// UpgradeWebSocket does the WebSocket handshake of the request.
+func UpgradeWebSocket(w http.ResponseWriter, r *http.Request) (*WebSocketConn, error) {
+	key := r.Header.Get("Sec-WebSocket-Key")
+	if !strings.EqualFold(r.Header.Get("Upgrade"), "websocket") || key == "" {
+		http.Error(w, "not a websocket handshake", http.StatusBadRequest)
+		return nil, errors.New("not a websocket handshake")
+	}
+
+	hj, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "websocket is not supported", http.StatusInternalServerError)
+		return nil, errors.New("the response writer can't be hijacked")
+	}
+	conn, rw, err := hj.Hijack()
+	if err != nil {
+		return nil, err
+	}
+
+	rw.WriteString("HTTP/1.1 101 Switching Protocols\r\n")
+	rw.WriteString("Upgrade: websocket\r\nConnection: Upgrade\r\n")
+	rw.WriteString("Sec-WebSocket-Accept: " + WebSocketAccept(key) + "\r\n\r\n")
+	if err := rw.Flush(); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return &WebSocketConn{conn: conn, rw: rw}, nil
+}
+
+// AppendWebSocketFrame appends an unmasked final frame with the payload.
+func AppendWebSocketFrame(buf []byte, opcode byte, payload []byte) []byte {
+	buf = append(buf, 0x80|opcode)
+	switch n := len(payload); {
+	case n < 126:
+		buf = append(buf, byte(n))
+	case n <= 0xFFFF:
+		buf = append(buf, 126)
+		buf = binary.BigEndian.AppendUint16(buf, uint16(n))
+	default:
+		buf = append(buf, 127)
+		buf = binary.BigEndian.AppendUint64(buf, uint64(n))
+	}
+	return append(buf, payload...)
+}
+
+// WriteText sends a text message.
+func (c *WebSocketConn) WriteText(msg []byte) error {
+	if _, err := c.rw.Write(AppendWebSocketFrame(nil, webSocketOpText, msg)); err != nil {
+		return err
+	}
+	return c.rw.Flush()
+}
+
+// Discard reads and drops the frames from the client until the connection
+// is closed or the client sends a close frame.
+func (c *WebSocketConn) Discard() error {
+	var hdr [2]byte
+	for {
+		if _, err := io.ReadFull(c.rw, hdr[:]); err != nil {
+			return err
+		}
+		if hdr[0]&0x0F == webSocketOpClose {
+			return io.EOF
+		}
+
+		n := uint64(hdr[1] & 0x7F)
+		switch n {
+		case 126:
+			var ext [2]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			n = uint64(binary.BigEndian.Uint16(ext[:]))
+		case 127:
+			var ext [8]byte
+			if _, err := io.ReadFull(c.rw, ext[:]); err != nil {
+				return err
+			}
+			n = binary.BigEndian.Uint64(ext[:])
+		}
+		if hdr[1]&0x80 != 0 {
+			// the masking key
+			n += 4
+		}
+		if _, err := io.CopyN(io.Discard, c.rw, int64(n)); err != nil {
+			return err
+		}
+	}
+}
+
+// Close closes the connection.
+func (c *WebSocketConn) Close() error {
+	c.rw.Write(AppendWebSocketFrame(nil, webSocketOpClose, nil))
+	c.rw.Flush()
+	return c.conn.Close()
+}
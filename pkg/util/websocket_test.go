@@ -0,0 +1,47 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package util
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestWebSocketAccept(t *testing.T) {
+	// the example of RFC 6455 section 1.3
+	if got := WebSocketAccept("dGhlIHNhbXBsZSBub25jZQ=="); got != "s3pPLMBiTxaQ9kYGzzhZRbK+xOo=" {
+		t.Fatalf("expect s3pPLMBiTxaQ9kYGzzhZRbK+xOo=, but got %s", got)
+	}
+}
+
+func TestAppendWebSocketFrame(t *testing.T) {
+	cases := []struct {
+		size   int
+		header []byte
+	}{
+		{5, []byte{0x81, 5}},
+		{200, []byte{0x81, 126, 0, 200}},
+		{70000, []byte{0x81, 127, 0, 0, 0, 0, 0, 1, 0x11, 0x70}},
+	}
+	for _, c := range cases {
+		payload := bytes.Repeat([]byte{'a'}, c.size)
+		frame := AppendWebSocketFrame(nil, webSocketOpText, payload)
+		if !bytes.Equal(frame[:len(c.header)], c.header) {
+			t.Fatalf("size %d: expect header %v, but got %v", c.size, c.header, frame[:len(c.header)])
+		}
+		if !bytes.Equal(frame[len(c.header):], payload) {
+			t.Fatalf("size %d: bad payload", c.size)
+		}
+	}
+}
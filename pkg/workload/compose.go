@@ -0,0 +1,213 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const composeStateKey = contextKey("compose")
+
+type composeState struct {
+	r    *rand.Rand
+	ctxs []context.Context
+}
+
+// overlayContext looks values up in the context of a composed workload first,
+// and in the context of the current operation otherwise. Cancellation and
+// deadlines always come from the current operation.
+type overlayContext struct {
+	context.Context
+
+	overlay context.Context
+}
+
+func (c overlayContext) Value(key interface{}) interface{} {
+	if v := c.overlay.Value(key); v != nil {
+		return v
+	}
+	return c.Context.Value(key)
+}
+
+// compose runs several named workloads against the same database, every
+// operation picks one of them by weight. The operations of each workload are
+// also measured under their own "<name>-" prefix.
+type compose struct {
+	names     []string
+	workloads []ycsb.Workload
+	chooser   *generator.Discrete
+	seed      int64
+}
+
+// composeProperties returns the properties of the named workload: the base
+// properties, then its property file, then its compose.<name>. overrides.
+func composeProperties(p *properties.Properties, name string) (*properties.Properties, error) {
+	prefix := prop.Compose + "." + name + "."
+	np := properties.NewProperties()
+	np.Merge(p)
+	if file := p.GetString(prefix+prop.ComposeFile, ""); file != "" {
+		fp, err := properties.LoadFile(file, properties.UTF8)
+		if err != nil {
+			return nil, err
+		}
+		np.Merge(fp)
+	}
+
+	np = util.OverrideProperties(np, prefix)
+	np.Delete(prop.Compose)
+	if np.GetString(prop.Workload, "core") == "compose" {
+		np.Set(prop.Workload, "core")
+	}
+	return np, nil
+}
+
+type composeCreator struct{}
+
+// Create implements the WorkloadCreator Create interface.
+func (composeCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	c := &compose{
+		chooser: generator.NewDiscrete(),
+		seed:    p.GetInt64(prop.RandomSeed, time.Now().UnixNano()),
+	}
+	weights := make(map[string]float64)
+	for _, name := range strings.Split(p.GetString(prop.Compose, ""), ",") {
+		if name = strings.TrimSpace(name); name == "" {
+			continue
+		}
+
+		np, err := composeProperties(p, name)
+		if err != nil {
+			return nil, err
+		}
+		workloadName := np.GetString(prop.Workload, "core")
+		creator := ycsb.GetWorkloadCreator(workloadName)
+		if creator == nil {
+			return nil, fmt.Errorf("workload %s of %s is not registered", workloadName, name)
+		}
+		w, err := creator.Create(np)
+		if err != nil {
+			return nil, err
+		}
+
+		weight := np.GetFloat64(prop.ComposeWeight, prop.ComposeWeightDefault)
+		c.chooser.Add(weight, int64(len(c.workloads)))
+		weights[name] = weight
+		c.names = append(c.names, name)
+		c.workloads = append(c.workloads, w)
+	}
+	if len(c.workloads) == 0 {
+		return nil, fmt.Errorf("%s must list at least one workload", prop.Compose)
+	}
+	// the mix is the one of the workloads, the mixes of their operations
+	// created with them are replaced.
+	measurement.SetIntendedMix(weights)
+	return c, nil
+}
+
+// Load implements the Workload Load interface.
+func (c *compose) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return c.workloads[0].Load(ctx, db, totalCount)
+}
+
+// InitThread implements the Workload InitThread interface.
+func (c *compose) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	state := &composeState{
+		r:    util.NewThreadRand(c.seed, threadID),
+		ctxs: make([]context.Context, len(c.workloads)),
+	}
+	for i, w := range c.workloads {
+		state.ctxs[i] = measurement.WithLabel(w.InitThread(ctx, threadID, threadCount), c.names[i])
+	}
+	return context.WithValue(ctx, composeStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (c *compose) CleanupThread(ctx context.Context) {
+	state := ctx.Value(composeStateKey).(*composeState)
+	for i, w := range c.workloads {
+		w.CleanupThread(overlayContext{Context: ctx, overlay: state.ctxs[i]})
+	}
+}
+
+// Close implements the Workload Close interface.
+func (c *compose) Close() error {
+	for _, w := range c.workloads {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// next returns the index of the workload of the next operation and its
+// context.
+func (c *compose) next(ctx context.Context) (int, context.Context) {
+	state := ctx.Value(composeStateKey).(*composeState)
+	i := c.chooser.Next(state.r)
+	return int(i), overlayContext{Context: ctx, overlay: state.ctxs[i]}
+}
+
+// first returns the first workload, which does the inserts of the load phase
+// as all the workloads share the same data.
+func (c *compose) first(ctx context.Context) (ycsb.Workload, context.Context) {
+	state := ctx.Value(composeStateKey).(*composeState)
+	return c.workloads[0], overlayContext{Context: ctx, overlay: state.ctxs[0]}
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (c *compose) DoInsert(ctx context.Context, db ycsb.DB) error {
+	w, ctx := c.first(ctx)
+	return w.DoInsert(ctx, db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (c *compose) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	w, ctx := c.first(ctx)
+	return w.DoBatchInsert(ctx, batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (c *compose) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	i, ctx := c.next(ctx)
+	err := c.workloads[i].DoTransaction(ctx, db)
+	if err == nil {
+		measurement.CountOp(c.names[i], 1)
+	}
+	return err
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (c *compose) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	i, ctx := c.next(ctx)
+	err := c.workloads[i].DoBatchTransaction(ctx, batchSize, db)
+	if err == nil {
+		measurement.CountOp(c.names[i], int64(batchSize))
+	}
+	return err
+}
+
+func init() {
+	ycsb.RegisterWorkloadCreator("compose", composeCreator{})
+}
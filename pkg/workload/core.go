@@ -19,9 +19,11 @@ import (
 	"fmt"
 	"math"
 	"math/rand"
+	"path/filepath"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/magiconair/properties"
@@ -40,6 +42,11 @@ type coreState struct {
 	r *rand.Rand
 	// fieldNames is a copy of core.fieldNames to be goroutine-local
 	fieldNames []string
+	// keySequence generates the keys of the load phase inserts of the thread.
+	keySequence ycsb.Generator
+
+	script    ycsb.OpScript
+	scriptSeq int64
 }
 
 type operationType int64
@@ -50,8 +57,15 @@ const (
 	insert
 	scan
 	readModifyWrite
+	readAfterDelete
+	pagedScan
+	fullScan
 )
 
+// keyLockStripes is the number of locks used to serialize the
+// read-after-delete operations with the other operations on the same key.
+const keyLockStripes = 64
+
 // Core is the core benchmark scenario. Represents a set of clients doing simple CRUD operations.
 type core struct {
 	p *properties.Properties
@@ -64,20 +78,39 @@ type core struct {
 	readAllFields        bool
 	writeAllFields       bool
 	dataIntegrity        bool
+	// strictOrdering issues the writes to a key in order, see prop.WriteOrdering.
+	strictOrdering bool
 
 	keySequence                  ycsb.Generator
+	insertKeyStrategy            string
+	insertStart                  int64
+	insertCount                  int64
 	operationChooser             *generator.Discrete
 	keyChooser                   ycsb.Generator
 	fieldChooser                 ycsb.Generator
 	transactionInsertKeySequence *generator.AcknowledgedCounter
 	scanLength                   ycsb.Generator
+	scanStartChooser             ycsb.Generator
+	scanPageSize                 int
 	orderedInserts               bool
 	recordCount                  int64
 	zeroPadding                  int64
 	insertionRetryLimit          int64
 	insertionRetryInterval       int64
+	seed                         int64
+	scriptPath                   string
+	scriptCreator                ycsb.OpScriptCreator
 
 	valuePool sync.Pool
+	keyLocks  [keyLockStripes]sync.Mutex
+	// lockKeys is whether the single key operations take the lock of their
+	// key, with read-after-delete operations in the mix.
+	lockKeys bool
+
+	// fullScans is the number of full scans running.
+	fullScans int64
+
+	growth *valueGrowth
 }
 
 func getFieldLengthGenerator(p *properties.Properties) ycsb.Generator {
@@ -102,33 +135,42 @@ func getFieldLengthGenerator(p *properties.Properties) ycsb.Generator {
 	return fieldLengthGenerator
 }
 
-func createOperationGenerator(p *properties.Properties) *generator.Discrete {
-	readProportion := p.GetFloat64(prop.ReadProportion, prop.ReadProportionDefault)
-	updateProportion := p.GetFloat64(prop.UpdateProportion, prop.UpdateProportionDefault)
-	insertProportion := p.GetFloat64(prop.InsertProportion, prop.InsertProportionDefault)
-	scanProportion := p.GetFloat64(prop.ScanProportion, prop.ScanProportionDefault)
-	readModifyWriteProportion := p.GetFloat64(prop.ReadModifyWriteProportion, prop.ReadModifyWriteProportionDefault)
-
-	operationChooser := generator.NewDiscrete()
-	if readProportion > 0 {
-		operationChooser.Add(readProportion, int64(read))
-	}
-
-	if updateProportion > 0 {
-		operationChooser.Add(updateProportion, int64(update))
-	}
-
-	if insertProportion > 0 {
-		operationChooser.Add(insertProportion, int64(insert))
-	}
+// operationNames are the names the operations are counted in the mix under.
+var operationNames = map[operationType]string{
+	read:            "READ",
+	update:          "UPDATE",
+	insert:          "INSERT",
+	scan:            "SCAN",
+	readModifyWrite: "READ_MODIFY_WRITE",
+	readAfterDelete: "READ_AFTER_DELETE",
+	pagedScan:       "PAGED_SCAN",
+	fullScan:        "FULLSCAN",
+}
 
-	if scanProportion > 0 {
-		operationChooser.Add(scanProportion, int64(scan))
+func createOperationGenerator(p *properties.Properties) *generator.Discrete {
+	proportions := []struct {
+		operation  operationType
+		proportion float64
+	}{
+		{read, p.GetFloat64(prop.ReadProportion, prop.ReadProportionDefault)},
+		{update, p.GetFloat64(prop.UpdateProportion, prop.UpdateProportionDefault)},
+		{insert, p.GetFloat64(prop.InsertProportion, prop.InsertProportionDefault)},
+		{scan, p.GetFloat64(prop.ScanProportion, prop.ScanProportionDefault)},
+		{readModifyWrite, p.GetFloat64(prop.ReadModifyWriteProportion, prop.ReadModifyWriteProportionDefault)},
+		{readAfterDelete, p.GetFloat64(prop.ReadAfterDeleteProportion, prop.ReadAfterDeleteProportionDefault)},
+		{pagedScan, p.GetFloat64(prop.PagedScanProportion, prop.PagedScanProportionDefault)},
+		{fullScan, p.GetFloat64(prop.FullScanProportion, prop.FullScanProportionDefault)},
 	}
 
-	if readModifyWriteProportion > 0 {
-		operationChooser.Add(readModifyWriteProportion, int64(readModifyWrite))
+	operationChooser := generator.NewDiscrete()
+	mix := make(map[string]float64, len(proportions))
+	for _, o := range proportions {
+		if o.proportion > 0 {
+			operationChooser.Add(o.proportion, int64(o.operation))
+			mix[operationNames[o.operation]] = o.proportion
+		}
 	}
+	measurement.SetIntendedMix(mix)
 
 	return operationChooser
 }
@@ -138,25 +180,67 @@ func (c *core) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
 	return nil
 }
 
+// threadKeySequence returns the key sequence of the load phase inserts of the
+// thread. The blocks and the stripes end with the keys of the load, -1 after,
+// so a thread doing more inserts than the client gave it, like the ones of
+// the warm-up, doesn't write the keys of another.
+func (c *core) threadKeySequence(threadID int, threadCount int) ycsb.Generator {
+	n := int64(threadCount)
+	id := int64(threadID)
+	switch c.insertKeyStrategy {
+	case "blocks":
+		// the first insertCount % n threads do one more insert.
+		start := c.insertStart + id*(c.insertCount/n)
+		if id < c.insertCount%n {
+			start += id
+		} else {
+			start += c.insertCount % n
+		}
+		end := start + c.insertCount/n
+		if id < c.insertCount%n {
+			end++
+		}
+		return generator.NewBounded(generator.NewCounter(start), end)
+	case "stripes":
+		return generator.NewBounded(generator.NewStripe(c.insertStart+id, n), c.insertStart+c.insertCount)
+	default:
+		return c.keySequence
+	}
+}
+
 // InitThread implements the Workload InitThread interface.
-func (c *core) InitThread(ctx context.Context, _ int, _ int) context.Context {
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+func (c *core) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	r := util.NewThreadRand(c.seed, threadID)
 	fieldNames := make([]string, len(c.fieldNames))
 	copy(fieldNames, c.fieldNames)
 	state := &coreState{
-		r:          r,
-		fieldNames: fieldNames,
+		r:           r,
+		fieldNames:  fieldNames,
+		keySequence: c.threadKeySequence(threadID, threadCount),
+	}
+	if c.scriptCreator != nil {
+		script, err := c.scriptCreator.Create(c.p, c.scriptPath, threadID)
+		if err != nil {
+			util.Fatalf("load script %s failed %v", c.scriptPath, err)
+		}
+		state.script = script
 	}
 	return context.WithValue(ctx, stateKey, state)
 }
 
 // CleanupThread implements the Workload CleanupThread interface.
-func (c *core) CleanupThread(_ context.Context) {
-
+func (c *core) CleanupThread(ctx context.Context) {
+	state := ctx.Value(stateKey).(*coreState)
+	if state.script != nil {
+		state.script.Close()
+	}
 }
 
 // Close implements the Workload Close interface.
 func (c *core) Close() error {
+	if c.growth != nil {
+		c.growth.close()
+	}
 	return nil
 }
 
@@ -250,19 +334,51 @@ func (c *core) verifyRow(state *coreState, key string, values map[string][]byte)
 		return
 	}
 
+	if err := c.checkRow(state, key, values); err != nil {
+		util.Fatal(err)
+	}
+}
+
+// checkRow compares the values with the deterministic values of the key.
+func (c *core) checkRow(state *coreState, key string, values map[string][]byte) error {
 	for fieldKey, value := range values {
 		expected := c.buildDeterministicValue(state, key, fieldKey)
 		if !bytes.Equal(expected, value) {
-			util.Fatalf("unexpected deterministic value, expect %q, but got %q", expected, value)
+			return fmt.Errorf("unexpected deterministic value, expect %q, but got %q", expected, value)
 		}
 	}
+	return nil
+}
+
+// VerifyRow implements the VerifyWorkload VerifyRow interface, the row is
+// chosen uniformly among the acknowledged keys.
+func (c *core) VerifyRow(ctx context.Context, db ycsb.DB) (string, error) {
+	state := ctx.Value(stateKey).(*coreState)
+	// the keys of the client, like the ones of the operations.
+	last := c.transactionInsertKeySequence.Last()
+	if last < c.insertStart {
+		return "", nil
+	}
+	keyName := c.buildKeyName(c.insertStart + state.r.Int63n(last-c.insertStart+1))
+
+	values, err := db.Read(ctx, c.table, keyName, nil)
+	if err != nil {
+		return keyName, err
+	}
+	if len(values) == 0 {
+		return keyName, fmt.Errorf("no fields returned")
+	}
+	return keyName, c.checkRow(state, keyName, values)
 }
 
 // DoInsert implements the Workload DoInsert interface.
 func (c *core) DoInsert(ctx context.Context, db ycsb.DB) error {
 	state := ctx.Value(stateKey).(*coreState)
 	r := state.r
-	keyNum := c.keySequence.Next(r)
+	keyNum := state.keySequence.Next(r)
+	if keyNum < 0 {
+		return ycsb.ErrNoMoreKeys
+	}
 	dbKey := c.buildKeyName(keyNum)
 	values := c.buildValues(state, dbKey)
 	defer c.putValues(values)
@@ -271,7 +387,11 @@ func (c *core) DoInsert(ctx context.Context, db ycsb.DB) error {
 
 	var err error
 	for {
-		err = db.Insert(ctx, c.table, dbKey, values)
+		opCtx := ctx
+		if numOfRetries > 0 {
+			opCtx = measurement.WithRetries(ctx, numOfRetries)
+		}
+		err = db.Insert(opCtx, c.table, dbKey, values)
 		if err != nil {
 			break
 		}
@@ -312,11 +432,18 @@ func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) err
 	var keys []string
 	var values []map[string][]byte
 	for i := 0; i < batchSize; i++ {
-		keyNum := c.keySequence.Next(r)
+		keyNum := state.keySequence.Next(r)
+		if keyNum < 0 {
+			// the last batch of the thread ends with its keys.
+			break
+		}
 		dbKey := c.buildKeyName(keyNum)
 		keys = append(keys, dbKey)
 		values = append(values, c.buildValues(state, dbKey))
 	}
+	if len(keys) == 0 {
+		return ycsb.ErrNoMoreKeys
+	}
 	defer func() {
 		for _, value := range values {
 			c.putValues(value)
@@ -326,7 +453,11 @@ func (c *core) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) err
 	numOfRetries := int64(0)
 	var err error
 	for {
-		err = batchDB.BatchInsert(ctx, c.table, keys, values)
+		opCtx := ctx
+		if numOfRetries > 0 {
+			opCtx = measurement.WithRetries(ctx, numOfRetries)
+		}
+		err = batchDB.BatchInsert(opCtx, c.table, keys, values)
 		if err != nil {
 			break
 		}
@@ -360,7 +491,23 @@ func (c *core) DoTransaction(ctx context.Context, db ycsb.DB) error {
 	state := ctx.Value(stateKey).(*coreState)
 	r := state.r
 
+	if c.growth != nil {
+		c.growth.maybeReport(ctx, db)
+	}
+
+	if state.script != nil {
+		return c.doTransactionScript(ctx, db, state)
+	}
+
 	operation := operationType(c.operationChooser.Next(r))
+	err := c.doTransactionOperation(ctx, db, state, operation)
+	if err == nil {
+		measurement.CountOp(operationNames[operation], 1)
+	}
+	return err
+}
+
+func (c *core) doTransactionOperation(ctx context.Context, db ycsb.DB, state *coreState, operation operationType) error {
 	switch operation {
 	case read:
 		return c.doTransactionRead(ctx, db, state)
@@ -370,6 +517,12 @@ func (c *core) DoTransaction(ctx context.Context, db ycsb.DB) error {
 		return c.doTransactionInsert(ctx, db, state)
 	case scan:
 		return c.doTransactionScan(ctx, db, state)
+	case readAfterDelete:
+		return c.doTransactionReadAfterDelete(ctx, db, state)
+	case pagedScan:
+		return c.doTransactionPagedScan(ctx, db, state)
+	case fullScan:
+		return c.doTransactionFullScan(ctx, db)
 	default:
 		return c.doTransactionReadModifyWrite(ctx, db, state)
 	}
@@ -384,19 +537,28 @@ func (c *core) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB
 	state := ctx.Value(stateKey).(*coreState)
 	r := state.r
 
+	if c.growth != nil {
+		c.growth.maybeReport(ctx, db)
+	}
+
 	operation := operationType(c.operationChooser.Next(r))
+	var err error
 	switch operation {
 	case read:
-		return c.doBatchTransactionRead(ctx, batchSize, batchDB, state)
+		err = c.doBatchTransactionRead(ctx, batchSize, batchDB, state)
 	case insert:
-		return c.doBatchTransactionInsert(ctx, batchSize, batchDB, state)
+		err = c.doBatchTransactionInsert(ctx, batchSize, batchDB, state)
 	case update:
-		return c.doBatchTransactionUpdate(ctx, batchSize, batchDB, state)
+		err = c.doBatchTransactionUpdate(ctx, batchSize, batchDB, state)
 	case scan:
 		panic("The batch mode don't support the scan operation")
 	default:
 		return nil
 	}
+	if err == nil {
+		measurement.CountOp(operationNames[operation], int64(batchSize))
+	}
+	return err
 }
 
 func (c *core) nextKeyNum(state *coreState) int64 {
@@ -413,10 +575,32 @@ func (c *core) nextKeyNum(state *coreState) int64 {
 	return keyNum
 }
 
+// nextScanKeyNum returns the start key of a scan, from the scan start
+// distribution if there is one.
+func (c *core) nextScanKeyNum(state *coreState) int64 {
+	if c.scanStartChooser == nil {
+		return c.nextKeyNum(state)
+	}
+	return c.scanStartChooser.Next(state.r)
+}
+
+// lockKey locks the key while read-after-delete operations are in the mix,
+// so the other operations don't find it deleted, and returns the unlock
+// function. The batches and the scans aren't serialized with them.
+func (c *core) lockKey(keyNum int64) func() {
+	if !c.lockKeys {
+		return func() {}
+	}
+	lock := &c.keyLocks[uint64(keyNum)%keyLockStripes]
+	lock.Lock()
+	return lock.Unlock
+}
+
 func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreState) error {
 	r := state.r
 	keyNum := c.nextKeyNum(state)
 	keyName := c.buildKeyName(keyNum)
+	defer c.lockKey(keyNum)()
 
 	var fields []string
 	if !c.readAllFields {
@@ -426,7 +610,12 @@ func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreSta
 		fields = state.fieldNames
 	}
 
+	start := time.Now()
+	duringFullScan := atomic.LoadInt64(&c.fullScans) > 0
 	values, err := db.Read(ctx, c.table, keyName, fields)
+	if duringFullScan {
+		measurement.Measure("READ_DURING_FULLSCAN", start, time.Now().Sub(start))
+	}
 	if err != nil {
 		return err
 	}
@@ -438,6 +627,32 @@ func (c *core) doTransactionRead(ctx context.Context, db ycsb.DB, state *coreSta
 	return nil
 }
 
+// doTransactionFullScan iterates over the whole table like an analytics
+// job. The reads started while a full scan runs are measured apart as
+// READ_DURING_FULLSCAN too, to compare them with all the reads.
+func (c *core) doTransactionFullScan(ctx context.Context, db ycsb.DB) error {
+	iterateDB, ok := db.(ycsb.IterateDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the IterateDB interface", db)
+	}
+
+	atomic.AddInt64(&c.fullScans, 1)
+	defer atomic.AddInt64(&c.fullScans, -1)
+
+	start := time.Now()
+	var rows int64
+	err := iterateDB.Iterate(ctx, c.table, func(_ string, _ map[string][]byte) error {
+		rows++
+		return ctx.Err()
+	})
+	takes := time.Now().Sub(start)
+	measurement.Measure("FULLSCAN", start, takes)
+	if err == nil {
+		fmt.Printf("[FULLSCAN] %d rows of %s in %s, %.0f rows/s\n", rows, c.table, takes.Round(time.Millisecond), float64(rows)/takes.Seconds())
+	}
+	return err
+}
+
 func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, state *coreState) error {
 	start := time.Now()
 	defer func() {
@@ -447,6 +662,7 @@ func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, sta
 	r := state.r
 	keyNum := c.nextKeyNum(state)
 	keyName := c.buildKeyName(keyNum)
+	defer c.lockKey(keyNum)()
 
 	var fields []string
 	if !c.readAllFields {
@@ -462,6 +678,9 @@ func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, sta
 	} else {
 		values = c.buildSingleValue(state, keyName)
 	}
+	if c.growth != nil {
+		c.growth.resize(c, state, keyNum, values)
+	}
 	defer c.putValues(values)
 
 	readValues, err := db.Read(ctx, c.table, keyName, fields)
@@ -480,6 +699,34 @@ func (c *core) doTransactionReadModifyWrite(ctx context.Context, db ycsb.DB, sta
 	return nil
 }
 
+// doTransactionReadAfterDelete deletes a key and reads it back right away, a
+// successful read is a delete visibility violation. The read back isn't
+// measured, it's expected to miss, and the row is inserted again afterwards
+// so the data set stays the same for the other operations.
+func (c *core) doTransactionReadAfterDelete(ctx context.Context, db ycsb.DB, state *coreState) error {
+	keyNum := c.nextKeyNum(state)
+	keyName := c.buildKeyName(keyNum)
+	defer c.lockKey(keyNum)()
+
+	start := time.Now()
+	defer func() {
+		measurement.Measure("READ_AFTER_DELETE", start, time.Now().Sub(start))
+	}()
+
+	if err := db.Delete(ctx, c.table, keyName); err != nil {
+		return err
+	}
+
+	if values, err := db.Read(measurement.WithoutMeasurement(ctx), c.table, keyName, nil); err == nil && len(values) > 0 {
+		measurement.Measure("READ_AFTER_DELETE_VIOLATION", start, time.Now().Sub(start))
+	}
+
+	values := c.buildValues(state, keyName)
+	defer c.putValues(values)
+
+	return db.Insert(ctx, c.table, keyName, values)
+}
+
 func (c *core) doTransactionInsert(ctx context.Context, db ycsb.DB, state *coreState) error {
 	r := state.r
 	keyNum := c.transactionInsertKeySequence.Next(r)
@@ -493,7 +740,7 @@ func (c *core) doTransactionInsert(ctx context.Context, db ycsb.DB, state *coreS
 
 func (c *core) doTransactionScan(ctx context.Context, db ycsb.DB, state *coreState) error {
 	r := state.r
-	keyNum := c.nextKeyNum(state)
+	keyNum := c.nextScanKeyNum(state)
 	startKeyName := c.buildKeyName(keyNum)
 
 	scanLen := c.scanLength.Next(r)
@@ -511,9 +758,70 @@ func (c *core) doTransactionScan(ctx context.Context, db ycsb.DB, state *coreSta
 	return err
 }
 
+// doTransactionPagedScan scans as many rows as a scan in pages of
+// scanpagesize rows. Like a paginated API, every page seeks again to the
+// last key of the previous page, which is skipped, so the cost of the
+// seeks can be compared with a single scan.
+func (c *core) doTransactionPagedScan(ctx context.Context, db ycsb.DB, state *coreState) error {
+	scanDB, ok := db.(ycsb.ScanKeysDB)
+	if !ok {
+		return fmt.Errorf("the %T does't implement the ScanKeysDB interface", db)
+	}
+
+	r := state.r
+	keyNum := c.nextScanKeyNum(state)
+	startKeyName := c.buildKeyName(keyNum)
+
+	remaining := int(c.scanLength.Next(r))
+
+	var fields []string
+	if !c.readAllFields {
+		fieldName := state.fieldNames[c.fieldChooser.Next(r)]
+		fields = append(fields, fieldName)
+	} else {
+		fields = state.fieldNames
+	}
+
+	start := time.Now()
+	defer func() {
+		measurement.Measure("PAGED_SCAN", start, time.Now().Sub(start))
+	}()
+
+	resume := false
+	for remaining > 0 {
+		page := min(remaining, c.scanPageSize)
+		count := page
+		if resume {
+			count++
+		}
+
+		pageStart := time.Now()
+		keys, _, err := scanDB.ScanKeys(ctx, c.table, startKeyName, count, fields)
+		measurement.Measure("SCAN_PAGE", pageStart, time.Now().Sub(pageStart))
+		if err != nil {
+			return err
+		}
+
+		if resume && len(keys) > 0 && keys[0] == startKeyName {
+			keys = keys[1:]
+		}
+		if len(keys) > page {
+			keys = keys[:page]
+		}
+		if len(keys) < page {
+			// the end of the table.
+			return nil
+		}
+		remaining -= len(keys)
+		startKeyName, resume = keys[len(keys)-1], true
+	}
+	return nil
+}
+
 func (c *core) doTransactionUpdate(ctx context.Context, db ycsb.DB, state *coreState) error {
 	keyNum := c.nextKeyNum(state)
 	keyName := c.buildKeyName(keyNum)
+	defer c.lockKey(keyNum)()
 
 	var values map[string][]byte
 	if c.writeAllFields {
@@ -521,12 +829,64 @@ func (c *core) doTransactionUpdate(ctx context.Context, db ycsb.DB, state *coreS
 	} else {
 		values = c.buildSingleValue(state, keyName)
 	}
+	if c.growth != nil {
+		c.growth.resize(c, state, keyNum, values)
+	}
 
 	defer c.putValues(values)
 
 	return db.Update(ctx, c.table, keyName, values)
 }
 
+// doTransactionScript runs the next operation computed by the script of the
+// thread, the values of inserts and updates are still generated by the workload.
+func (c *core) doTransactionScript(ctx context.Context, db ycsb.DB, state *coreState) error {
+	start := time.Now()
+	op, err := state.script.Next(state.scriptSeq)
+	measurement.Measure("SCRIPT", start, time.Now().Sub(start))
+	state.scriptSeq++
+	if err != nil {
+		return err
+	}
+
+	keyName := op.Key
+	if keyName == "" {
+		keyName = c.buildKeyName(op.KeyNum)
+	}
+
+	switch op.Op {
+	case "read":
+		_, err = db.Read(ctx, c.table, keyName, op.Fields)
+	case "scan":
+		_, err = db.Scan(ctx, c.table, keyName, op.Count, op.Fields)
+	case "delete":
+		err = db.Delete(ctx, c.table, keyName)
+	case "insert":
+		values := c.buildValues(state, keyName)
+		defer c.putValues(values)
+		err = db.Insert(ctx, c.table, keyName, values)
+	case "update":
+		var values map[string][]byte
+		if len(op.Fields) == 0 {
+			values = c.buildValues(state, keyName)
+		} else {
+			values = make(map[string][]byte, len(op.Fields))
+			for _, field := range op.Fields {
+				if c.dataIntegrity {
+					values[field] = c.buildDeterministicValue(state, keyName, field)
+				} else {
+					values[field] = c.buildRandomValue(state)
+				}
+			}
+		}
+		defer c.putValues(values)
+		err = db.Update(ctx, c.table, keyName, values)
+	default:
+		err = fmt.Errorf("unknown script operation %q", op.Op)
+	}
+	return err
+}
+
 func (c *core) doBatchTransactionRead(ctx context.Context, batchSize int, db ycsb.BatchDB, state *coreState) error {
 	r := state.r
 	var fields []string
@@ -589,6 +949,9 @@ func (c *core) doBatchTransactionUpdate(ctx context.Context, batchSize int, db y
 		} else {
 			values[i] = c.buildSingleValue(state, keyName)
 		}
+		if c.growth != nil {
+			c.growth.resize(c, state, keyNum, values[i])
+		}
 	}
 
 	defer func() {
@@ -597,7 +960,31 @@ func (c *core) doBatchTransactionUpdate(ctx context.Context, batchSize int, db y
 		}
 	}()
 
-	return db.BatchUpdate(ctx, c.table, keys, values)
+	if !c.strictOrdering {
+		return db.BatchUpdate(ctx, c.table, keys, values)
+	}
+	return orderedBatches(keys, func(i, j int) error {
+		return db.BatchUpdate(ctx, c.table, keys[i:j], values[i:j])
+	})
+}
+
+// orderedBatches cuts the batch before every key already in it and calls fn
+// with the parts in order, so a key is written at most once per batch and the
+// writes to a key can't be reordered by the database.
+func orderedBatches(keys []string, fn func(i, j int) error) error {
+	seen := make(map[string]struct{}, len(keys))
+	i := 0
+	for j, key := range keys {
+		if _, ok := seen[key]; ok {
+			if err := fn(i, j); err != nil {
+				return err
+			}
+			i = j
+			clear(seen)
+		}
+		seen[key] = struct{}{}
+	}
+	return fn(i, len(keys))
 }
 
 // CoreCreator creates the Core workload.
@@ -606,6 +993,12 @@ type coreCreator struct {
 
 // Create implements the WorkloadCreator Create interface.
 func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	tables := util.TableNames(p)
+	if len(tables) > 1 {
+		return newMultiTable(p, tables)
+	}
+	p = util.TableProperties(p, tables[0])
+
 	c := new(core)
 	c.p = p
 	c.table = p.GetString(prop.TableName, prop.TableNameDefault)
@@ -639,6 +1032,9 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	if c.dataIntegrity && fieldLengthDistribution != "constant" {
 		util.Fatal("must have constant field size to check data integrity")
 	}
+	if p.GetFloat64(prop.VerifyRate, prop.VerifyRateDefault) > 0 && !c.dataIntegrity {
+		util.Fatalf("%s checks the deterministic values of %s, set it too", prop.VerifyRate, prop.DataIntegrity)
+	}
 
 	if p.GetString(prop.InsertOrder, prop.InsertOrderDefault) == "hashed" {
 		c.orderedInserts = false
@@ -647,7 +1043,17 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	}
 
 	c.keySequence = generator.NewCounter(insertStart)
+	c.insertStart = insertStart
+	c.insertCount = insertCount
+	c.insertKeyStrategy = p.GetString(prop.InsertKeyStrategy, prop.InsertKeyStrategyDefault)
+	switch c.insertKeyStrategy {
+	case "shared", "blocks", "stripes":
+	default:
+		util.Fatalf("unknown insert key strategy %s", c.insertKeyStrategy)
+	}
+	fmt.Printf("Using insert key strategy '%s'\n", c.insertKeyStrategy)
 	c.operationChooser = createOperationGenerator(p)
+	c.lockKeys = p.GetFloat64(prop.ReadAfterDeleteProportion, prop.ReadAfterDeleteProportionDefault) > 0
 	var keyrangeLowerBound int64 = insertStart
 	var keyrangeUpperBound int64 = insertStart + insertCount - 1
 
@@ -678,6 +1084,20 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	}
 	fmt.Println(fmt.Sprintf("Using request distribution '%s' a keyrange of [%d %d]", requestDistrib, keyrangeLowerBound, keyrangeUpperBound))
 
+	switch scanStartDistrib := p.GetString(prop.ScanStartDistribution, ""); scanStartDistrib {
+	case "":
+	case "uniform":
+		c.scanStartChooser = generator.NewUniform(keyrangeLowerBound, keyrangeUpperBound)
+	case "zipfian":
+		// unlike the request distribution the keys aren't scrambled, so the
+		// scans start in a hot region of the key space.
+		c.scanStartChooser = generator.NewZipfianWithRange(keyrangeLowerBound, keyrangeUpperBound, generator.ZipfianConstant)
+	case "recent":
+		c.scanStartChooser = generator.NewSkewedLatest(c.transactionInsertKeySequence)
+	default:
+		util.Fatalf("unknown scan start distribution %s", scanStartDistrib)
+	}
+
 	c.fieldChooser = generator.NewUniform(0, c.fieldCount-1)
 	switch scanLengthDistrib {
 	case "uniform":
@@ -687,9 +1107,37 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 	default:
 		util.Fatalf("distribution %s not allowed for scan length", scanLengthDistrib)
 	}
+	if c.scanPageSize = int(p.GetInt64(prop.ScanPageSize, prop.ScanPageSizeDefault)); c.scanPageSize <= 0 {
+		util.Fatalf("%s must be positive", prop.ScanPageSize)
+	}
 
 	c.insertionRetryLimit = p.GetInt64(prop.InsertionRetryLimit, prop.InsertionRetryLimitDefault)
 	c.insertionRetryInterval = p.GetInt64(prop.InsertionRetryInterval, prop.InsertionRetryIntervalDefault)
+	c.seed = p.GetInt64(prop.RandomSeed, time.Now().UnixNano())
+
+	if c.scriptPath = p.GetString(prop.Script, ""); c.scriptPath != "" {
+		c.scriptCreator = ycsb.GetOpScriptCreator(filepath.Ext(c.scriptPath))
+		if c.scriptCreator == nil {
+			util.Fatalf("no script engine for %s", c.scriptPath)
+		}
+	}
+
+	switch ordering := p.GetString(prop.WriteOrdering, prop.WriteOrderingDefault); ordering {
+	case "any":
+	case "strict":
+		if p.GetBool(prop.LoadCommitter, prop.LoadCommitterDefault) {
+			// the inserts return once they are queued.
+			return nil, fmt.Errorf("%s strict can't be combined with %s", prop.WriteOrdering, prop.LoadCommitter)
+		}
+		c.strictOrdering = true
+	default:
+		return nil, fmt.Errorf("unknown %s %s, expected any or strict", prop.WriteOrdering, ordering)
+	}
+
+	var err error
+	if c.growth, err = newValueGrowth(p, c.fieldNames); err != nil {
+		return nil, err
+	}
 
 	fieldLength := p.GetInt64(prop.FieldLength, prop.FieldLengthDefault)
 	c.valuePool = sync.Pool{
@@ -704,4 +1152,6 @@ func (coreCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
 func init() {
 	ycsb.RegisterWorkloadCreator("core", coreCreator{})
 	ycsb.RegisterWorkloadCreator("site.ycsb.workloads.CoreWorkload", coreCreator{})
+	ycsb.RegisterWorkloadProperties("core", coreProperties...)
+	ycsb.RegisterWorkloadProperties("site.ycsb.workloads.CoreWorkload", coreProperties...)
 }
@@ -11,8 +11,11 @@
 package workload
 
 import (
-	"github.com/magiconair/properties"
+	"errors"
+	"slices"
 	"testing"
+
+	"github.com/magiconair/properties"
 )
 
 func Test_core_buildKeyName(t *testing.T) {
@@ -45,3 +48,125 @@ func Test_core_buildKeyName(t *testing.T) {
 		})
 	}
 }
+
+func Test_core_threadKeySequence(t *testing.T) {
+	tests := []struct {
+		name        string
+		strategy    string
+		insertStart int64
+		insertCount int64
+		threadCount int
+		want        [][]int64
+	}{
+		{
+			name:        "blocks",
+			strategy:    "blocks",
+			insertStart: 10,
+			insertCount: 9,
+			threadCount: 3,
+			want:        [][]int64{{10, 11, 12}, {13, 14, 15}, {16, 17, 18}},
+		},
+		{
+			name:        "uneven blocks",
+			strategy:    "blocks",
+			insertStart: 10,
+			insertCount: 11,
+			threadCount: 3,
+			want:        [][]int64{{10, 11, 12, 13}, {14, 15, 16, 17}, {18, 19, 20}},
+		},
+		{
+			name:        "fewer keys than threads",
+			strategy:    "blocks",
+			insertCount: 2,
+			threadCount: 3,
+			want:        [][]int64{{0}, {1}, nil},
+		},
+		{
+			name:        "uneven stripes",
+			strategy:    "stripes",
+			insertStart: 10,
+			insertCount: 10,
+			threadCount: 3,
+			want:        [][]int64{{10, 13, 16, 19}, {11, 14, 17}, {12, 15, 18}},
+		},
+		{
+			name:        "fewer keys than stripes",
+			strategy:    "stripes",
+			insertCount: 2,
+			threadCount: 3,
+			want:        [][]int64{{0}, {1}, nil},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := core{insertKeyStrategy: tt.strategy, insertStart: tt.insertStart, insertCount: tt.insertCount}
+			for id := 0; id < tt.threadCount; id++ {
+				g := c.threadKeySequence(id, tt.threadCount)
+				var got []int64
+				for n := g.Next(nil); n >= 0; n = g.Next(nil) {
+					got = append(got, n)
+				}
+				if !slices.Equal(got, tt.want[id]) {
+					t.Errorf("threadKeySequence(%d) = %v, want %v", id, got, tt.want[id])
+				}
+				// the sequence stays ended, the warm-up may insert more.
+				if n := g.Next(nil); n != -1 {
+					t.Errorf("threadKeySequence(%d) went on with %d after the end", id, n)
+				}
+			}
+		})
+	}
+}
+
+func Test_orderedBatches(t *testing.T) {
+	tests := []struct {
+		name string
+		keys []string
+		want [][]string
+	}{
+		{
+			name: "distinct keys",
+			keys: []string{"a", "b", "c"},
+			want: [][]string{{"a", "b", "c"}},
+		},
+		{
+			name: "repeated key",
+			keys: []string{"a", "b", "a", "c"},
+			want: [][]string{{"a", "b"}, {"a", "c"}},
+		},
+		{
+			name: "key written three times",
+			keys: []string{"a", "a", "a"},
+			want: [][]string{{"a"}, {"a"}, {"a"}},
+		},
+		{
+			// a cut forgets the keys before it, so the second b stays in
+			// the part of the second a.
+			name: "overlapping repeats",
+			keys: []string{"a", "b", "a", "b", "c", "b"},
+			want: [][]string{{"a", "b"}, {"a", "b", "c"}, {"b"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got [][]string
+			err := orderedBatches(tt.keys, func(i, j int) error {
+				got = append(got, tt.keys[i:j])
+				return nil
+			})
+			if err != nil || !slices.EqualFunc(got, tt.want, slices.Equal) {
+				t.Errorf("orderedBatches() = %v %v, want %v", got, err, tt.want)
+			}
+		})
+	}
+
+	// an error stops at the failed part.
+	calls := 0
+	err := orderedBatches([]string{"a", "a", "a"}, func(i, j int) error {
+		calls++
+		return errors.New("batch failed")
+	})
+	if err == nil || calls != 1 {
+		t.Errorf("orderedBatches() = %v after %d parts, want the error of the first one", err, calls)
+	}
+}
@@ -0,0 +1,223 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// valueGrowth grows the values written by the updates by a factor every
+// period, exercising the page splits and relocations of the engine, or shrinks
+// them with a factor below 1. It tracks the logical size of the data set
+// against the size of the database on disk, and when the disk space is
+// reclaimed. The logical size is the one of the field values of the loaded
+// keys, which are assumed to be loaded with values of fieldlength bytes.
+type valueGrowth struct {
+	factor     float64
+	period     time.Duration
+	minLength  int64
+	maxLength  int64
+	interval   time.Duration
+	fieldCount int64
+	fieldIndex map[string]int64
+
+	// sizes are the lengths of the fields of the loaded keys, 0 is the
+	// loaded length.
+	sizes       []int32
+	loadLength  int64
+	logical     int64
+	loadLogical int64
+
+	once      sync.Once
+	start     time.Time
+	db        ycsb.DB
+	startDisk int64
+	// nextReport is the time of the next report in nanoseconds.
+	nextReport int64
+
+	// reportMu protects the peak disk size and when the disk space was
+	// first reclaimed after it.
+	reportMu  sync.Mutex
+	peakDisk  int64
+	peakAt    time.Duration
+	reclaimed bool
+	reclaimAt time.Duration
+}
+
+func newValueGrowth(p *properties.Properties, fieldNames []string) (*valueGrowth, error) {
+	factor := p.GetFloat64(prop.ValueGrowthFactor, prop.ValueGrowthFactorDefault)
+	if factor == 1 || !p.GetBool(prop.DoTransactions, true) {
+		return nil, nil
+	}
+	if factor <= 0 {
+		return nil, fmt.Errorf("%s must be positive, but got %v", prop.ValueGrowthFactor, factor)
+	}
+	if p.GetBool(prop.DataIntegrity, prop.DataIntegrityDefault) {
+		return nil, fmt.Errorf("%s changes the values, it can't be used with %s", prop.ValueGrowthFactor, prop.DataIntegrity)
+	}
+	records := p.GetInt64(prop.RecordCount, prop.RecordCountDefault)
+	if records <= 0 {
+		return nil, fmt.Errorf("%s needs %s to track the logical size", prop.ValueGrowthFactor, prop.RecordCount)
+	}
+
+	g := &valueGrowth{
+		factor:     factor,
+		period:     p.GetParsedDuration(prop.ValueGrowthPeriod, prop.ValueGrowthPeriodDefault),
+		minLength:  p.GetInt64(prop.ValueGrowthMinLength, prop.ValueGrowthMinLengthDefault),
+		maxLength:  p.GetInt64(prop.ValueGrowthMaxLength, prop.ValueGrowthMaxLengthDefault),
+		interval:   p.GetParsedDuration(prop.ValueGrowthReport, prop.ValueGrowthReportDefault),
+		fieldCount: int64(len(fieldNames)),
+		fieldIndex: make(map[string]int64, len(fieldNames)),
+		sizes:      make([]int32, records*int64(len(fieldNames))),
+		loadLength: p.GetInt64(prop.FieldLength, prop.FieldLengthDefault),
+	}
+	for i, name := range fieldNames {
+		g.fieldIndex[name] = int64(i)
+	}
+	g.loadLogical = records * g.fieldCount * g.loadLength
+	g.logical = g.loadLogical
+	return g, nil
+}
+
+// multiplier returns the factor applied to the generated value lengths.
+func (g *valueGrowth) multiplier() float64 {
+	return math.Pow(g.factor, float64(time.Since(g.start))/float64(g.period))
+}
+
+// resize replaces the values of an update of the key by values of the grown
+// length.
+func (g *valueGrowth) resize(c *core, state *coreState, keyNum int64, values map[string][]byte) {
+	m := g.multiplier()
+	for field, value := range values {
+		n := int64(float64(len(value)) * m)
+		if n < g.minLength {
+			n = g.minLength
+		} else if n > g.maxLength {
+			n = g.maxLength
+		}
+
+		buf := c.getValueBuffer(int(n))
+		util.RandBytes(state.r, buf)
+		c.valuePool.Put(value)
+		values[field] = buf
+		g.track(keyNum, g.fieldIndex[field], n)
+	}
+}
+
+// track records the new length of the field, keys inserted by the run phase
+// are not tracked.
+func (g *valueGrowth) track(keyNum int64, field int64, n int64) {
+	i := keyNum*g.fieldCount + field
+	if keyNum < 0 || i >= int64(len(g.sizes)) {
+		return
+	}
+	old := int64(atomic.SwapInt32(&g.sizes[i], int32(n)))
+	if old == 0 {
+		old = g.loadLength
+	}
+	atomic.AddInt64(&g.logical, n-old)
+}
+
+func (g *valueGrowth) diskSize(ctx context.Context) int64 {
+	sizeDB, ok := g.db.(ycsb.SizeDB)
+	if !ok {
+		return -1
+	}
+	size, err := sizeDB.DiskSize(ctx)
+	if err != nil {
+		return -1
+	}
+	return size
+}
+
+// maybeReport starts the growth with the first operation and prints the
+// sizes once per interval.
+func (g *valueGrowth) maybeReport(ctx context.Context, db ycsb.DB) {
+	g.once.Do(func() {
+		g.start = time.Now()
+		g.db = db
+		g.startDisk = g.diskSize(ctx)
+		g.peakDisk = g.startDisk
+		atomic.StoreInt64(&g.nextReport, g.start.Add(g.interval).UnixNano())
+	})
+
+	next := atomic.LoadInt64(&g.nextReport)
+	now := time.Now()
+	if now.UnixNano() < next || !atomic.CompareAndSwapInt64(&g.nextReport, next, now.Add(g.interval).UnixNano()) {
+		return
+	}
+	g.report(ctx)
+}
+
+func (g *valueGrowth) report(ctx context.Context) {
+	g.reportMu.Lock()
+	defer g.reportMu.Unlock()
+
+	logical := atomic.LoadInt64(&g.logical)
+	line := fmt.Sprintf("[GROWTH] values x%.2f, logical %d MB (%+.1f%%)",
+		g.multiplier(), logical>>20, growthPercent(g.loadLogical, logical))
+	disk := g.diskSize(ctx)
+	if disk < 0 || g.startDisk <= 0 {
+		fmt.Println(line)
+		return
+	}
+	fmt.Printf("%s, disk %d MB (%+.1f%%), %.2f disk bytes per logical byte\n",
+		line, disk>>20, growthPercent(g.startDisk, disk), float64(disk)/float64(logical))
+	g.trackReclaim(disk)
+}
+
+// trackReclaim records the peak disk size, and when the size first drops
+// below it afterwards.
+func (g *valueGrowth) trackReclaim(disk int64) {
+	elapsed := time.Since(g.start).Round(time.Second)
+	switch {
+	case disk > g.peakDisk:
+		g.peakDisk, g.peakAt, g.reclaimed = disk, elapsed, false
+	case disk < g.peakDisk && !g.reclaimed:
+		g.reclaimed, g.reclaimAt = true, elapsed
+		fmt.Printf("[GROWTH] disk space reclaimed at %s, %d MB below the peak at %s\n",
+			elapsed, (g.peakDisk-disk)>>20, g.peakAt)
+	}
+}
+
+func growthPercent(from int64, to int64) float64 {
+	return float64(to-from) / float64(from) * 100
+}
+
+// close prints the final sizes and whether the disk space was reclaimed.
+func (g *valueGrowth) close() {
+	if g.db == nil {
+		return
+	}
+	g.report(context.Background())
+
+	if g.startDisk <= 0 {
+		return
+	}
+	if !g.reclaimed {
+		fmt.Printf("[GROWTH] disk space not reclaimed, peak %d MB at %s\n", g.peakDisk>>20, g.peakAt)
+		return
+	}
+	fmt.Printf("[GROWTH] disk space reclaimed at %s after the peak of %d MB at %s\n", g.reclaimAt, g.peakDisk>>20, g.peakAt)
+}
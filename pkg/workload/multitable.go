@@ -0,0 +1,144 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"math/rand"
+	"sync/atomic"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/generator"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/util"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const multiStateKey = contextKey("multiTable")
+
+type multiTableState struct {
+	r      *rand.Rand
+	states []*coreState
+}
+
+// multiTable runs a core workload per table, each created with the
+// properties of its table. Transactions are spread among the tables by their
+// weight, the load phase fills the tables one after another.
+type multiTable struct {
+	tables []string
+	cores  []*core
+
+	tableChooser *generator.Discrete
+	// loadEnds is the cumulative insert count of the tables.
+	loadEnds []int64
+	loaded   int64
+}
+
+func newMultiTable(p *properties.Properties, tables []string) (*multiTable, error) {
+	m := &multiTable{
+		tables:       tables,
+		tableChooser: generator.NewDiscrete(),
+	}
+
+	var loadEnd int64
+	for i, table := range tables {
+		tp := util.TableProperties(p, table)
+		w, err := coreCreator{}.Create(tp)
+		if err != nil {
+			return nil, err
+		}
+		c := w.(*core)
+		if c.insertKeyStrategy != "shared" {
+			// the load phase inserts of a thread aren't spread evenly among the tables.
+			util.Fatalf("insert key strategy %s is not supported with multiple tables", c.insertKeyStrategy)
+		}
+		m.cores = append(m.cores, c)
+
+		m.tableChooser.Add(tp.GetFloat64(prop.TableWeight, prop.TableWeightDefault), int64(i))
+
+		insertStart := tp.GetInt64(prop.InsertStart, prop.InsertStartDefault)
+		loadEnd += tp.GetInt64(prop.InsertCount, tp.GetInt64(prop.RecordCount, prop.RecordCountDefault)-insertStart)
+		m.loadEnds = append(m.loadEnds, loadEnd)
+	}
+	return m, nil
+}
+
+// Load implements the Workload Load interface.
+func (m *multiTable) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return nil
+}
+
+// InitThread implements the Workload InitThread interface.
+func (m *multiTable) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	state := &multiTableState{
+		states: make([]*coreState, len(m.cores)),
+	}
+	for i, c := range m.cores {
+		state.states[i] = c.InitThread(ctx, threadID, threadCount).Value(stateKey).(*coreState)
+	}
+	state.r = state.states[0].r
+	return context.WithValue(ctx, multiStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (m *multiTable) CleanupThread(_ context.Context) {
+}
+
+// Close implements the Workload Close interface.
+func (m *multiTable) Close() error {
+	return nil
+}
+
+// tableContext returns the context of the core workload of the table.
+func (m *multiTable) tableContext(ctx context.Context, i int) context.Context {
+	state := ctx.Value(multiStateKey).(*multiTableState)
+	return context.WithValue(ctx, stateKey, state.states[i])
+}
+
+// nextLoadTable returns the table the next n inserts of the load phase go to.
+func (m *multiTable) nextLoadTable(n int64) int {
+	pos := atomic.AddInt64(&m.loaded, n) - n
+	for i, end := range m.loadEnds {
+		if pos < end {
+			return i
+		}
+	}
+	return len(m.loadEnds) - 1
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (m *multiTable) DoInsert(ctx context.Context, db ycsb.DB) error {
+	i := m.nextLoadTable(1)
+	return m.cores[i].DoInsert(m.tableContext(ctx, i), db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (m *multiTable) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	i := m.nextLoadTable(int64(batchSize))
+	return m.cores[i].DoBatchInsert(m.tableContext(ctx, i), batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (m *multiTable) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(multiStateKey).(*multiTableState)
+	i := int(m.tableChooser.Next(state.r))
+	return m.cores[i].DoTransaction(m.tableContext(ctx, i), db)
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (m *multiTable) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	state := ctx.Value(multiStateKey).(*multiTableState)
+	i := int(m.tableChooser.Next(state.r))
+	return m.cores[i].DoBatchTransaction(m.tableContext(ctx, i), batchSize, db)
+}
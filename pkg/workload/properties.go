@@ -0,0 +1,55 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"fmt"
+
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+// coreProperties are the main properties of the core workload.
+var coreProperties = []ycsb.Property{
+	{Name: prop.TableName, Default: prop.TableNameDefault, Description: "The name of the table"},
+	{Name: prop.RecordCount, Default: fmt.Sprint(prop.RecordCountDefault), Description: "The number of records loaded, or already in the table for the run phase"},
+	{Name: prop.OperationCount, Default: "", Description: "The number of operations of the run phase"},
+	{Name: prop.InsertStart, Default: fmt.Sprint(prop.InsertStartDefault), Description: "The key number of the first insert of the load phase"},
+	{Name: prop.InsertCount, Default: "", Description: "The number of inserts of the load phase, recordcount minus insertstart by default"},
+	{Name: prop.InsertOrder, Default: prop.InsertOrderDefault, Description: "Insert the keys `hashed` or `ordered`"},
+	{Name: prop.InsertKeyStrategy, Default: prop.InsertKeyStrategyDefault, Description: "How the load threads share the keys: `shared`, `blocks` or `stripes`"},
+	{Name: prop.FieldCount, Default: fmt.Sprint(prop.FieldCountDefault), Description: "The number of fields of a record"},
+	{Name: prop.FieldLength, Default: fmt.Sprint(prop.FieldLengthDefault), Description: "The length in bytes of a field"},
+	{Name: prop.FieldLengthDistribution, Default: prop.FieldLengthDistributionDefault, Description: "The distribution of the field lengths: `constant`, `uniform`, `zipfian` or `histogram`"},
+	{Name: prop.ReadAllFields, Default: fmt.Sprint(prop.ReadALlFieldsDefault), Description: "Read all the fields of a record, or a single one"},
+	{Name: prop.WriteAllFields, Default: fmt.Sprint(prop.WriteAllFieldsDefault), Description: "Update all the fields of a record, or a single one"},
+	{Name: prop.ReadProportion, Default: fmt.Sprint(prop.ReadProportionDefault), Description: "The proportion of reads"},
+	{Name: prop.UpdateProportion, Default: fmt.Sprint(prop.UpdateProportionDefault), Description: "The proportion of updates"},
+	{Name: prop.InsertProportion, Default: fmt.Sprint(prop.InsertProportionDefault), Description: "The proportion of inserts"},
+	{Name: prop.ScanProportion, Default: fmt.Sprint(prop.ScanProportionDefault), Description: "The proportion of scans"},
+	{Name: prop.ReadModifyWriteProportion, Default: fmt.Sprint(prop.ReadModifyWriteProportionDefault), Description: "The proportion of reads followed by an update of the record"},
+	{Name: prop.ReadAfterDeleteProportion, Default: fmt.Sprint(prop.ReadAfterDeleteProportionDefault), Description: "The proportion of deletes read back right away"},
+	{Name: prop.PagedScanProportion, Default: fmt.Sprint(prop.PagedScanProportionDefault), Description: "The proportion of paged scans"},
+	{Name: prop.FullScanProportion, Default: fmt.Sprint(prop.FullScanProportionDefault), Description: "The proportion of full table scans"},
+	{Name: prop.RequestDistribution, Default: prop.RequestDistributionDefault, Description: "The distribution of the keys: `uniform`, `sequential`, `zipfian`, `latest`, `hotspot` or `exponential`"},
+	{Name: prop.HotspotDataFraction, Default: fmt.Sprint(prop.HotspotDataFractionDefault), Description: "The fraction of the keys in the hot set of the hotspot distribution"},
+	{Name: prop.HotspotOpnFraction, Default: fmt.Sprint(prop.HotspotOpnFractionDefault), Description: "The fraction of the operations on the hot set of the hotspot distribution"},
+	{Name: prop.MinScanLength, Default: fmt.Sprint(prop.MinScanLengthDefault), Description: "The minimum number of records of a scan"},
+	{Name: prop.MaxScanLength, Default: fmt.Sprint(prop.MaxScanLengthDefault), Description: "The maximum number of records of a scan"},
+	{Name: prop.ScanLengthDistribution, Default: prop.ScanLengthDistributionDefault, Description: "The distribution of the scan lengths: `uniform` or `zipfian`"},
+	{Name: prop.ScanStartDistribution, Default: "", Description: "The distribution of the scan start keys: `uniform`, `zipfian` or `recent`, the request distribution by default"},
+	{Name: prop.ScanPageSize, Default: fmt.Sprint(prop.ScanPageSizeDefault), Description: "The rows of a page of a paged scan"},
+	{Name: prop.DataIntegrity, Default: fmt.Sprint(prop.DataIntegrityDefault), Description: "Check the values read were written for the key"},
+	{Name: prop.WriteOrdering, Default: prop.WriteOrderingDefault, Description: "The order of the writes of a thread to a key: `any` or `strict`"},
+}
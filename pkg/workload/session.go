@@ -0,0 +1,201 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const sessionStateKey = contextKey("session")
+
+type sessionState struct {
+	writer bool
+}
+
+// sessionWrite is a write published by the writer, written is when the
+// update returned.
+type sessionWrite struct {
+	key     string
+	version int64
+	written time.Time
+}
+
+// session checks that writes are visible to the other threads once they
+// return. Thread 0 updates keys with increasing versions and publishes every
+// write, the other threads read the published keys right away. A read seeing
+// an older version is stale, the time until the version is seen is measured
+// as VISIBILITY. The load phase is the one of the core workload.
+type session struct {
+	core    *core
+	field   string
+	timeout time.Duration
+	writes  chan sessionWrite
+
+	version   int64
+	dropped   int64
+	reads     int64
+	stale     int64
+	invisible int64
+}
+
+type sessionCreator struct{}
+
+// Create implements the WorkloadCreator Create interface.
+func (sessionCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	w, err := coreCreator{}.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	c := w.(*core)
+	if p.GetBool(prop.DoTransactions, true) && p.GetInt(prop.ThreadCount, 1) < 2 {
+		return nil, fmt.Errorf("the session workload needs a writer and at least one reader thread")
+	}
+	return &session{
+		core:    c,
+		field:   c.fieldNames[0],
+		timeout: p.GetParsedDuration(prop.SessionTimeout, prop.SessionTimeoutDefault),
+		writes:  make(chan sessionWrite, p.GetInt(prop.SessionQueue, prop.SessionQueueDefault)),
+	}, nil
+}
+
+// Load implements the Workload Load interface.
+func (s *session) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return s.core.Load(ctx, db, totalCount)
+}
+
+// InitThread implements the Workload InitThread interface.
+func (s *session) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	ctx = s.core.InitThread(ctx, threadID, threadCount)
+	return context.WithValue(ctx, sessionStateKey, &sessionState{writer: threadID == 0})
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (s *session) CleanupThread(ctx context.Context) {
+	if ctx.Value(sessionStateKey).(*sessionState).writer {
+		// the readers return right away once the writer is done.
+		close(s.writes)
+	}
+	s.core.CleanupThread(ctx)
+}
+
+// Close implements the Workload Close interface.
+func (s *session) Close() error {
+	if s.version > 0 {
+		fmt.Printf("[SESSION] writes: %d, dropped: %d, checked: %d, stale reads: %d, not visible after %s: %d\n",
+			s.version, s.dropped, s.reads, s.stale, s.timeout, s.invisible)
+	}
+	return s.core.Close()
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (s *session) DoInsert(ctx context.Context, db ycsb.DB) error {
+	return s.core.DoInsert(ctx, db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (s *session) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	return s.core.DoBatchInsert(ctx, batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (s *session) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	if ctx.Value(sessionStateKey).(*sessionState).writer {
+		return s.write(ctx, db)
+	}
+	return s.read(ctx, db)
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (s *session) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := s.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *session) write(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(stateKey).(*coreState)
+	key := s.core.buildKeyName(s.core.nextKeyNum(state))
+	version := atomic.AddInt64(&s.version, 1)
+	values := map[string][]byte{s.field: []byte(strconv.FormatInt(version, 10))}
+	if err := db.Update(ctx, s.core.table, key, values); err != nil {
+		return err
+	}
+
+	// the writer doesn't wait for slow readers, that would delay the next write.
+	select {
+	case s.writes <- sessionWrite{key: key, version: version, written: time.Now()}:
+	default:
+		atomic.AddInt64(&s.dropped, 1)
+	}
+	return nil
+}
+
+func (s *session) read(ctx context.Context, db ycsb.DB) error {
+	var w sessionWrite
+	select {
+	case <-ctx.Done():
+		return nil
+	case v, ok := <-s.writes:
+		if !ok {
+			return nil
+		}
+		w = v
+	}
+	atomic.AddInt64(&s.reads, 1)
+
+	stale := false
+	for {
+		values, err := db.Read(ctx, s.core.table, w.key, []string{s.field})
+		if err != nil {
+			return err
+		}
+		// the loaded value of a key not written by the writer yet isn't a version.
+		version, _ := strconv.ParseInt(string(values[s.field]), 10, 64)
+		if version >= w.version {
+			measurement.Measure("VISIBILITY", w.written, time.Now().Sub(w.written))
+			return nil
+		}
+
+		if !stale {
+			stale = true
+			atomic.AddInt64(&s.stale, 1)
+		}
+		if time.Now().Sub(w.written) > s.timeout {
+			atomic.AddInt64(&s.invisible, 1)
+			return fmt.Errorf("version %d of %s is not visible after %s, read version %d", w.version, w.key, s.timeout, version)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil
+		default:
+		}
+	}
+}
+
+func init() {
+	ycsb.RegisterWorkloadCreator("session", sessionCreator{})
+}
@@ -0,0 +1,232 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package workload
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/magiconair/properties"
+	"github.com/pingcap/go-ycsb/pkg/measurement"
+	"github.com/pingcap/go-ycsb/pkg/prop"
+	"github.com/pingcap/go-ycsb/pkg/ycsb"
+)
+
+const tombstoneStateKey = contextKey("tombstone")
+
+type tombstoneState struct {
+	// pairs are the indexes of the range pairs owned by the thread.
+	pairs []int64
+	// churned are the pairs whose first range is already churned.
+	churned map[int64]bool
+}
+
+// tombstoneScans are the totals of the scans of one kind, the time is in
+// nanoseconds.
+type tombstoneScans struct {
+	scans     int64
+	rows      int64
+	positions int64
+	nanos     int64
+}
+
+func (s *tombstoneScans) add(rows int64, positions int64, d time.Duration) {
+	atomic.AddInt64(&s.scans, 1)
+	atomic.AddInt64(&s.rows, rows)
+	atomic.AddInt64(&s.positions, positions)
+	atomic.AddInt64(&s.nanos, int64(d))
+}
+
+func (s *tombstoneScans) perSecond(n int64) float64 {
+	return float64(n) / time.Duration(s.nanos).Seconds()
+}
+
+// tombstone measures how deleted keys slow scans down. The loaded keys are
+// split into pairs of ranges, the first range of a pair is churned: all but
+// every keep-th key are deleted the first time the pair is picked, and stays
+// so for the rest of the run. Every operation scans the live rows of the
+// churned range as TOMBSTONE_SCAN, walking over the deleted key positions,
+// and the same number of rows of the dense range as DENSE_SCAN. The effective
+// rows/sec of both and the key positions/sec of the churned scans are
+// reported at the end. Keys must be ordered, the load phase is the one of the
+// core workload.
+type tombstone struct {
+	core *core
+	// length is the number of keys in a range, keep is the stride of the
+	// live keys of a churned range.
+	length int64
+	keep   int64
+	pairs  int64
+
+	dense   tombstoneScans
+	churned tombstoneScans
+	deleted int64
+}
+
+type tombstoneCreator struct{}
+
+// Create implements the WorkloadCreator Create interface.
+func (tombstoneCreator) Create(p *properties.Properties) (ycsb.Workload, error) {
+	w, err := coreCreator{}.Create(p)
+	if err != nil {
+		return nil, err
+	}
+	c := w.(*core)
+
+	if !c.orderedInserts {
+		return nil, fmt.Errorf("the tombstone workload scans key ranges, it needs %s=ordered", prop.InsertOrder)
+	}
+	recordCount := c.recordCount
+	if digits := int64(len(strconv.FormatInt(recordCount-1, 10))); c.zeroPadding < digits {
+		return nil, fmt.Errorf("the keys of %d records are only ordered with %s=%d or more", recordCount, prop.ZeroPadding, digits)
+	}
+
+	fraction := p.GetFloat64(prop.TombstoneFraction, prop.TombstoneFractionDefault)
+	if fraction < 0 || fraction >= 1 {
+		return nil, fmt.Errorf("%s must be in [0, 1), but got %v", prop.TombstoneFraction, fraction)
+	}
+	t := &tombstone{
+		core:   c,
+		length: p.GetInt64(prop.TombstoneRange, prop.TombstoneRangeDefault),
+		keep:   int64(math.Round(1 / (1 - fraction))),
+	}
+	if t.length <= 0 {
+		return nil, fmt.Errorf("%s must be positive", prop.TombstoneRange)
+	}
+	t.pairs = recordCount / (2 * t.length)
+	if threadCount := p.GetInt64(prop.ThreadCount, 1); p.GetBool(prop.DoTransactions, true) && t.pairs < threadCount {
+		return nil, fmt.Errorf("%d records only have %d pairs of ranges of %d keys, the %d threads need one each",
+			recordCount, t.pairs, t.length, threadCount)
+	}
+	return t, nil
+}
+
+// Load implements the Workload Load interface.
+func (t *tombstone) Load(ctx context.Context, db ycsb.DB, totalCount int64) error {
+	return t.core.Load(ctx, db, totalCount)
+}
+
+// InitThread implements the Workload InitThread interface, the pairs are
+// split between the threads so a range is only churned by one of them.
+func (t *tombstone) InitThread(ctx context.Context, threadID int, threadCount int) context.Context {
+	ctx = t.core.InitThread(ctx, threadID, threadCount)
+	state := &tombstoneState{churned: make(map[int64]bool)}
+	for pair := int64(threadID); pair < t.pairs; pair += int64(threadCount) {
+		state.pairs = append(state.pairs, pair)
+	}
+	return context.WithValue(ctx, tombstoneStateKey, state)
+}
+
+// CleanupThread implements the Workload CleanupThread interface.
+func (t *tombstone) CleanupThread(ctx context.Context) {
+	t.core.CleanupThread(ctx)
+}
+
+// Close implements the Workload Close interface.
+func (t *tombstone) Close() error {
+	if t.churned.scans > 0 {
+		fmt.Printf("[TOMBSTONE] deleted keys: %d, one in %d keys of the churned ranges is live\n", t.deleted, t.keep)
+		fmt.Printf("[TOMBSTONE] dense scans: %d, %.0f rows/s\n", t.dense.scans, t.dense.perSecond(t.dense.rows))
+		fmt.Printf("[TOMBSTONE] churned scans: %d, %.0f rows/s, %.0f key positions/s\n",
+			t.churned.scans, t.churned.perSecond(t.churned.rows), t.churned.perSecond(t.churned.positions))
+		if rows := t.churned.perSecond(t.churned.rows); rows > 0 {
+			fmt.Printf("[TOMBSTONE] the churned scans are %.2fx slower per row\n", t.dense.perSecond(t.dense.rows)/rows)
+		}
+	}
+	return t.core.Close()
+}
+
+// DoInsert implements the Workload DoInsert interface.
+func (t *tombstone) DoInsert(ctx context.Context, db ycsb.DB) error {
+	return t.core.DoInsert(ctx, db)
+}
+
+// DoBatchInsert implements the Workload DoBatchInsert interface.
+func (t *tombstone) DoBatchInsert(ctx context.Context, batchSize int, db ycsb.DB) error {
+	return t.core.DoBatchInsert(ctx, batchSize, db)
+}
+
+// DoTransaction implements the Workload DoTransaction interface.
+func (t *tombstone) DoTransaction(ctx context.Context, db ycsb.DB) error {
+	state := ctx.Value(tombstoneStateKey).(*tombstoneState)
+	r := ctx.Value(stateKey).(*coreState).r
+	pair := state.pairs[r.Intn(len(state.pairs))]
+	churnedStart := 2 * pair * t.length
+	denseStart := churnedStart + t.length
+
+	if !state.churned[pair] {
+		if err := t.churn(ctx, db, churnedStart); err != nil {
+			return err
+		}
+		state.churned[pair] = true
+	}
+
+	live := (t.length + t.keep - 1) / t.keep
+	start := time.Now()
+	rows, err := db.Scan(ctx, t.core.table, t.core.buildKeyName(denseStart), int(live), nil)
+	if err != nil {
+		return err
+	}
+	lan := time.Now().Sub(start)
+	measurement.Measure("DENSE_SCAN", start, lan)
+	t.dense.add(int64(len(rows)), int64(len(rows)), lan)
+
+	start = time.Now()
+	rows, err = db.Scan(ctx, t.core.table, t.core.buildKeyName(churnedStart), int(live), nil)
+	if err != nil {
+		return err
+	}
+	lan = time.Now().Sub(start)
+	measurement.Measure("TOMBSTONE_SCAN", start, lan)
+	// the live keys are every keep-th key, the scan walks over the deleted
+	// keys in between.
+	var positions int64
+	if len(rows) > 0 {
+		positions = int64(len(rows)-1)*t.keep + 1
+	}
+	t.churned.add(int64(len(rows)), positions, lan)
+	return nil
+}
+
+// churn deletes all but every keep-th key of the range.
+func (t *tombstone) churn(ctx context.Context, db ycsb.DB, start int64) error {
+	for i := int64(0); i < t.length; i++ {
+		if i%t.keep == 0 {
+			continue
+		}
+		if err := db.Delete(ctx, t.core.table, t.core.buildKeyName(start+i)); err != nil {
+			return err
+		}
+		atomic.AddInt64(&t.deleted, 1)
+	}
+	return nil
+}
+
+// DoBatchTransaction implements the Workload DoBatchTransaction interface.
+func (t *tombstone) DoBatchTransaction(ctx context.Context, batchSize int, db ycsb.DB) error {
+	for i := 0; i < batchSize; i++ {
+		if err := t.DoTransaction(ctx, db); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	ycsb.RegisterWorkloadCreator("tombstone", tombstoneCreator{})
+}
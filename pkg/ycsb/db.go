@@ -16,6 +16,7 @@ package ycsb
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"github.com/magiconair/properties"
 )
@@ -102,6 +103,48 @@ type AnalyzeDB interface {
 	Analyze(ctx context.Context, table string) error
 }
 
+// BulkIngestDB is the interface for the DB that can write rows already
+// encoded with util.RowCodec without decoding them first.
+type BulkIngestDB interface {
+	// IngestRows writes the encoded rows in the database.
+	// table: The name of the table.
+	// keys: The keys of the rows.
+	// rows: The rows encoded with util.RowCodec.
+	IngestRows(ctx context.Context, table string, keys []string, rows [][]byte) error
+}
+
+// StatsDB is the interface for the DB that can report engine statistics.
+type StatsDB interface {
+	// Stats returns a snapshot of engine statistics keyed by name.
+	Stats(ctx context.Context) (map[string]interface{}, error)
+}
+
+// SizeDB is the interface for the DB that can report the size of its files.
+type SizeDB interface {
+	// DiskSize returns the size in bytes the database takes on disk.
+	DiskSize(ctx context.Context) (int64, error)
+}
+
+// ScanKeysDB is the interface for the DB that can return the keys of the
+// scanned records.
+type ScanKeysDB interface {
+	// ScanKeys scans records like Scan and also returns their keys.
+	// table: The name of the table.
+	// startKey: The first record to read.
+	// count: The number of records to read.
+	// fields: The list of fields to read, nil|empty for reading all.
+	ScanKeys(ctx context.Context, table string, startKey string, count int, fields []string) ([]string, []map[string][]byte, error)
+}
+
+// IterateDB is the interface for the DB that can iterate over all the rows
+// of a table.
+type IterateDB interface {
+	// Iterate calls fn with every row of the table, it stops at the first
+	// error returned by fn.
+	// table: The name of the table.
+	Iterate(ctx context.Context, table string, fn func(key string, values map[string][]byte) error) error
+}
+
 var dbCreators = map[string]DBCreator{}
 
 // RegisterDBCreator registers a creator for the database
@@ -118,3 +161,40 @@ func RegisterDBCreator(name string, creator DBCreator) {
 func GetDBCreator(name string) DBCreator {
 	return dbCreators[name]
 }
+
+// DBNames returns the names of the registered databases, sorted.
+func DBNames() []string {
+	names := make([]string, 0, len(dbCreators))
+	for name := range dbCreators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// DataPath is the property holding the path of the local files of a
+// database, and its default value.
+type DataPath struct {
+	Property string
+	Default  string
+}
+
+var dbDataPaths = map[string]DataPath{}
+
+// RegisterDBDataPath registers where the database keeps its local files, so
+// they can be copied as a whole.
+func RegisterDBDataPath(name string, path DataPath) {
+	_, ok := dbDataPaths[name]
+	if ok {
+		panic(fmt.Sprintf("duplicate register data path %s", name))
+	}
+
+	dbDataPaths[name] = path
+}
+
+// GetDBDataPath gets the data path of the database, it returns false if the
+// database doesn't keep its data in local files.
+func GetDBDataPath(name string) (DataPath, bool) {
+	path, ok := dbDataPaths[name]
+	return path, ok
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ycsb
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/magiconair/properties"
+)
+
+// Call is an operation passing through the middleware chain of a DB.
+type Call struct {
+	// Op is the measured name of the operation, e.g. READ or BATCH_UPDATE.
+	Op     string
+	Table  string
+	Keys   []string
+	Fields []string
+	// Values are the written values.
+	Values []map[string][]byte
+	// Count is the number of records to scan.
+	Count int
+	// Result are the read values, they are set once next returns. A
+	// middleware answering a read itself sets them instead of calling next.
+	Result []map[string][]byte
+}
+
+// Middleware handles the operations on their way to the DB, next runs the
+// rest of the chain and the operation itself.
+type Middleware interface {
+	Handle(ctx context.Context, call *Call, next func(ctx context.Context) error) error
+}
+
+// MiddlewareCreator creates a middleware.
+type MiddlewareCreator interface {
+	Create(p *properties.Properties) (Middleware, error)
+}
+
+var middlewareCreators = map[string]MiddlewareCreator{}
+
+// RegisterMiddlewareCreator registers a creator for the middleware.
+func RegisterMiddlewareCreator(name string, creator MiddlewareCreator) {
+	_, ok := middlewareCreators[name]
+	if ok {
+		panic(fmt.Sprintf("duplicate register middleware %s", name))
+	}
+
+	middlewareCreators[name] = creator
+}
+
+// GetMiddlewareCreator gets the MiddlewareCreator for the middleware.
+func GetMiddlewareCreator(name string) MiddlewareCreator {
+	return middlewareCreators[name]
+}
@@ -0,0 +1,75 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ycsb
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Property describes a property of a workload or a database, for the
+// describe command.
+type Property struct {
+	Name string
+	// Default is the default value, empty if there is none.
+	Default     string
+	Description string
+}
+
+var (
+	workloadProperties = map[string][]Property{}
+	dbProperties       = map[string][]Property{}
+)
+
+// RegisterWorkloadProperties registers the properties of the workload.
+func RegisterWorkloadProperties(name string, props ...Property) {
+	_, ok := workloadProperties[name]
+	if ok {
+		panic(fmt.Sprintf("duplicate register workload properties %s", name))
+	}
+
+	workloadProperties[name] = props
+}
+
+// GetWorkloadProperties gets the properties of the workload, nil if they
+// aren't registered.
+func GetWorkloadProperties(name string) []Property {
+	return workloadProperties[name]
+}
+
+// RegisterDBProperties registers the properties of the database.
+func RegisterDBProperties(name string, props ...Property) {
+	_, ok := dbProperties[name]
+	if ok {
+		panic(fmt.Sprintf("duplicate register database properties %s", name))
+	}
+
+	dbProperties[name] = props
+}
+
+// GetDBProperties gets the properties of the database, nil if they aren't
+// registered.
+func GetDBProperties(name string) []Property {
+	return dbProperties[name]
+}
+
+// WorkloadNames returns the names of the registered workloads, sorted.
+func WorkloadNames() []string {
+	names := make([]string, 0, len(workloadCreators))
+	for name := range workloadCreators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,65 @@
+// Copyright 2018 PingCAP, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ycsb
+
+import (
+	"fmt"
+
+	"github.com/magiconair/properties"
+)
+
+// ScriptOp is the operation computed by an OpScript.
+type ScriptOp struct {
+	// Op is one of read, update, insert, scan and delete.
+	Op string
+	// Key is the key of the operation, if it's empty the key is built from KeyNum.
+	Key    string
+	KeyNum int64
+	// Fields are the fields to read or update, all fields if empty.
+	Fields []string
+	// Count is the number of records to scan.
+	Count int
+}
+
+// OpScript computes the operations of a workload thread. It is only used by
+// the thread it was created for.
+type OpScript interface {
+	// Next returns the seq-th operation of the thread.
+	Next(seq int64) (ScriptOp, error)
+
+	// Close releases the script.
+	Close()
+}
+
+// OpScriptCreator creates the OpScript of a thread from a script file.
+type OpScriptCreator interface {
+	Create(p *properties.Properties, path string, threadID int) (OpScript, error)
+}
+
+var opScriptCreators = map[string]OpScriptCreator{}
+
+// RegisterOpScriptCreator registers a creator for the script files with the extension.
+func RegisterOpScriptCreator(ext string, creator OpScriptCreator) {
+	_, ok := opScriptCreators[ext]
+	if ok {
+		panic(fmt.Sprintf("duplicate register script %s", ext))
+	}
+
+	opScriptCreators[ext] = creator
+}
+
+// GetOpScriptCreator gets the OpScriptCreator for the script file extension.
+func GetOpScriptCreator(ext string) OpScriptCreator {
+	return opScriptCreators[ext]
+}
@@ -15,6 +15,7 @@ package ycsb
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/magiconair/properties"
@@ -53,6 +54,18 @@ type Workload interface {
 	DoBatchTransaction(ctx context.Context, batchSize int, db DB) error
 }
 
+// ErrNoMoreKeys is returned by the inserts of a thread whose keys are used up,
+// the client stops the thread.
+var ErrNoMoreKeys = errors.New("the keys of the thread are used up")
+
+// VerifyWorkload is the interface for the Workload that can check the rows it
+// wrote while it runs.
+type VerifyWorkload interface {
+	// VerifyRow reads a random row of the data set and checks its values. It
+	// returns the key of the row and why it's wrong, nil if it's right.
+	VerifyRow(ctx context.Context, db DB) (string, error)
+}
+
 var workloadCreators = map[string]WorkloadCreator{}
 
 // RegisterWorkloadCreator registers a creator for the workload